@@ -207,6 +207,79 @@ CREATE TABLE IF NOT EXISTS bar_system.payments (
     completed_at TIMESTAMP WITH TIME ZONE
 );
 
+-- ================================================
+-- MÓDULO 5: MICROSERVICIO DE OPTIMIZACIÓN
+-- ================================================
+-- Tablas de MS-OPTIMIZATION-GO cuando usa Postgres; en bases de datos
+-- existentes se crean con optimization_tables.sql
+
+-- Cajones de efectivo
+CREATE TABLE IF NOT EXISTS bar_system.cash_drawers (
+    id VARCHAR(100) PRIMARY KEY,
+    currency VARCHAR(3) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cantidad de cada denominación en cada cajón
+CREATE TABLE IF NOT EXISTS bar_system.cash_drawer_counts (
+    drawer_id VARCHAR(100) NOT NULL REFERENCES bar_system.cash_drawers(id) ON DELETE CASCADE,
+    denomination_cents BIGINT NOT NULL CHECK (denomination_cents > 0),
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    PRIMARY KEY (drawer_id, denomination_cents)
+);
+
+-- Registro de cambio entregado, para auditoría y analítica
+CREATE TABLE IF NOT EXISTS bar_system.change_log (
+    id BIGSERIAL PRIMARY KEY,
+    terminal_id VARCHAR(100) NOT NULL DEFAULT '',
+    drawer_id VARCHAR(100) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    amount_paid_cents BIGINT NOT NULL,
+    total_cost_cents BIGINT NOT NULL,
+    change_cents BIGINT NOT NULL,
+    breakdown JSONB NOT NULL,
+    drawer_before JSONB,
+    algorithm VARCHAR(20) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Planos de sala versionados; cada cambio agrega una versión
+CREATE TABLE IF NOT EXISTS bar_system.floor_plans (
+    id VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    name VARCHAR(200) NOT NULL DEFAULT '',
+    days JSONB NOT NULL,
+    tables JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, version)
+);
+
+-- Ocupación actual de cada mesa de un plano de sala
+CREATE TABLE IF NOT EXISTS bar_system.table_occupancy (
+    floor_plan_id VARCHAR(100) NOT NULL,
+    table_id VARCHAR(100) NOT NULL,
+    occupied BOOLEAN NOT NULL,
+    group_id VARCHAR(100) NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    seated_at TIMESTAMP WITH TIME ZONE,
+    expected_departure TIMESTAMP WITH TIME ZONE,
+    version BIGINT NOT NULL,
+    PRIMARY KEY (floor_plan_id, table_id)
+);
+
+-- Historial de sesiones de mesa, de la llegada a la salida de cada grupo
+CREATE TABLE IF NOT EXISTS bar_system.table_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    floor_plan_id VARCHAR(100) NOT NULL,
+    table_id VARCHAR(100) NOT NULL,
+    group_id VARCHAR(100) NOT NULL DEFAULT '',
+    size INTEGER NOT NULL,
+    seated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
 -- ================================================
 -- ÍNDICES PARA OPTIMIZACIÓN DE CONSULTAS
 -- ================================================
@@ -279,6 +352,14 @@ CREATE INDEX IF NOT EXISTS idx_payments_payment_status ON bar_system.payments(pa
 CREATE INDEX IF NOT EXISTS idx_payments_created_at ON bar_system.payments(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_payments_completed_at ON bar_system.payments(completed_at DESC);
 
+-- Índices del registro de cambio
+CREATE INDEX IF NOT EXISTS change_log_created_at_idx ON bar_system.change_log (created_at DESC);
+CREATE INDEX IF NOT EXISTS change_log_terminal_idx ON bar_system.change_log (terminal_id, created_at DESC);
+
+-- Índices de sesiones de mesa
+CREATE INDEX IF NOT EXISTS idx_table_sessions_floor_plan_released
+    ON bar_system.table_sessions (floor_plan_id, released_at);
+
 -- ================================================
 -- FUNCIONES Y TRIGGERS
 -- ================================================
@@ -479,6 +560,12 @@ COMMENT ON TABLE bar_system.products IS 'Catálogo de productos disponibles';
 COMMENT ON TABLE bar_system.orders IS 'Órdenes/Pedidos de clientes';
 COMMENT ON TABLE bar_system.order_items IS 'Items/productos en cada orden';
 COMMENT ON TABLE bar_system.payments IS 'Pagos realizados por los clientes';
+COMMENT ON TABLE bar_system.cash_drawers IS 'Cajones de efectivo del microservicio de optimización';
+COMMENT ON TABLE bar_system.cash_drawer_counts IS 'Cantidad de cada denominación en cada cajón';
+COMMENT ON TABLE bar_system.change_log IS 'Registro de cambio entregado por el microservicio de optimización';
+COMMENT ON TABLE bar_system.floor_plans IS 'Versiones de los planos de sala del microservicio de optimización';
+COMMENT ON TABLE bar_system.table_occupancy IS 'Ocupación actual de las mesas de cada plano de sala';
+COMMENT ON TABLE bar_system.table_sessions IS 'Historial de sesiones de mesa, usado para estimar tiempos de rotación';
 
 -- Comentarios de vistas
 COMMENT ON VIEW bar_system.v_users_with_roles IS 'Vista desnormalizada de usuarios con sus roles';
@@ -0,0 +1,84 @@
+package algorithms
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ABC classes, from the few items carrying most of the value to the many carrying little
+const (
+	ClassA = "A"
+	ClassB = "B"
+	ClassC = "C"
+)
+
+// ABCItem is the classification of one item
+type ABCItem struct {
+	Index           int     // position of the item in the input
+	Rank            int     // 1 for the most valuable item
+	Share           float64 // the item's fraction of the total value
+	CumulativeShare float64 // fraction of the total value of this item and all ranked above it
+	Class           string
+}
+
+// ABCResult is the classification of a set of items, ordered by rank
+type ABCResult struct {
+	Items      []ABCItem
+	TotalValue float64
+	Success    bool
+	Message    string
+}
+
+// ClassifyABC ranks items by value and assigns class A while the cumulative share of the
+// items ranked above is below aThreshold, B while it is below bThreshold and C after. The
+// item that crosses a threshold stays in the higher class, so A is never empty
+func ClassifyABC(values []float64, aThreshold, bThreshold float64) ABCResult {
+	if !(aThreshold > 0 && aThreshold <= bThreshold && bThreshold <= 1) {
+		return ABCResult{Success: false, Message: "Thresholds must satisfy 0 < A <= B <= 1"}
+	}
+
+	total := 0.0
+	for _, value := range values {
+		if value < 0 {
+			return ABCResult{Success: false, Message: "Values must not be negative"}
+		}
+		total += value
+	}
+	if total <= 0 {
+		return ABCResult{Success: false, Message: "Total value must be positive"}
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+
+	items := make([]ABCItem, len(order))
+	cumulative := 0.0
+	for rank, index := range order {
+		class := ClassC
+		switch {
+		case cumulative < aThreshold:
+			class = ClassA
+		case cumulative < bThreshold:
+			class = ClassB
+		}
+		share := values[index] / total
+		cumulative += share
+		items[rank] = ABCItem{
+			Index:           index,
+			Rank:            rank + 1,
+			Share:           share,
+			CumulativeShare: cumulative,
+			Class:           class,
+		}
+	}
+
+	return ABCResult{
+		Items:      items,
+		TotalValue: total,
+		Success:    true,
+		Message:    fmt.Sprintf("%d items classified", len(items)),
+	}
+}
@@ -0,0 +1,266 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Bounds for the even-split DP: its work (guests × reachable deviation sums × deviations)
+// and the per-guest share it builds a min-pieces table for, in denomination steps
+const (
+	maxSplitWork  = 40000000
+	maxSplitShare = 1000000
+)
+
+// BillItem is an order line shared by some of the guests at a table
+type BillItem struct {
+	Amount int   // line total in minor units
+	Guests []int // indexes of the guests sharing the line; empty means every guest
+}
+
+// BillSplitResult represents how an order total is divided among guests
+type BillSplitResult struct {
+	Amounts     []int // what each guest pays, in minor units
+	FairShares  []int // equal shares rounded to minor units (even split only)
+	Adjustments []int // Amounts - FairShares, summing to zero (even split only)
+	Pieces      []int // pieces each guest needs to pay their amount exactly (even split only)
+	Remainder   int   // part of the total the denominations cannot form, charged to the first guest
+	Success     bool
+	Message     string
+}
+
+// Apportion divides total into whole minor units proportional to weights using the
+// largest-remainder method, with ties going to the earlier weight. It returns nil when
+// no weight is positive
+func Apportion(total int, weights []float64) []int {
+	sum := 0.0
+	for _, weight := range weights {
+		if weight > 0 {
+			sum += weight
+		}
+	}
+	if sum <= 0 {
+		return nil
+	}
+
+	amounts := make([]int, len(weights))
+	remainders := make([]float64, len(weights))
+	assigned := 0
+	for i, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		quota := float64(total) * weight / sum
+		amounts[i] = int(math.Floor(quota))
+		remainders[i] = quota - float64(amounts[i])
+		assigned += amounts[i]
+	}
+
+	// Hand the minor units lost to flooring to the largest remainders
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; assigned < total; i++ {
+		amounts[order[i%len(order)]]++
+		assigned++
+	}
+
+	return amounts
+}
+
+// SplitByItems charges each guest for the lines assigned to them. Lines shared by several
+// guests are divided evenly, rotating who absorbs leftover minor units from line to line
+func SplitByItems(items []BillItem, guests int) BillSplitResult {
+	if guests <= 0 {
+		return BillSplitResult{Success: false, Message: "At least one guest is required"}
+	}
+
+	amounts := make([]int, guests)
+	for i, item := range items {
+		sharers := item.Guests
+		if len(sharers) == 0 {
+			sharers = make([]int, guests)
+			for g := range sharers {
+				sharers[g] = g
+			}
+		}
+		for _, g := range sharers {
+			if g < 0 || g >= guests {
+				return BillSplitResult{Success: false, Message: fmt.Sprintf("Item %d is assigned to unknown guest %d", i, g)}
+			}
+		}
+
+		weights := make([]float64, len(sharers))
+		for j := range weights {
+			weights[j] = 1
+		}
+		shares := Apportion(item.Amount, weights)
+		for j := range sharers {
+			amounts[sharers[(i+j)%len(sharers)]] += shares[j]
+		}
+	}
+
+	return BillSplitResult{
+		Amounts: amounts,
+		Success: true,
+		Message: fmt.Sprintf("Order split by items among %d guests", guests),
+	}
+}
+
+// SplitProportional divides total among guests proportionally to their weights
+func SplitProportional(total int, weights []float64) BillSplitResult {
+	amounts := Apportion(total, weights)
+	if amounts == nil {
+		return BillSplitResult{Success: false, Message: "At least one guest needs a positive weight"}
+	}
+
+	return BillSplitResult{
+		Amounts: amounts,
+		Success: true,
+		Message: fmt.Sprintf("Order split proportionally among %d guests", len(weights)),
+	}
+}
+
+// SplitEvenly divides total among guests so everyone pays within maxAdjustment of the equal
+// share, choosing amounts that take the fewest pieces to hand over while still summing to
+// total. Ties go to the split closest to equal shares. A cancelled ctx stops the search
+func (mca *MoneyChangeAlgorithm) SplitEvenly(ctx context.Context, total, guests, maxAdjustment int) BillSplitResult {
+	if guests <= 0 || total < 0 {
+		return BillSplitResult{Success: false, Message: "At least one guest and a non-negative total are required"}
+	}
+	if len(mca.coins) == 0 {
+		return BillSplitResult{Success: false, Message: "No denominations available"}
+	}
+
+	// Work in steps of the smallest amount the denominations can form
+	step := 0
+	for _, coin := range mca.coins {
+		step = gcd(step, coin)
+	}
+	remainder := total % step
+	units := total / step
+	base := units / guests
+	extra := units - base*guests // deviations must add up to this
+
+	deviation := maxAdjustment / step
+	if deviation < 1 && extra > 0 {
+		deviation = 1
+	}
+	for deviation > 1 && guests*(2*guests*deviation+1)*(2*deviation+1) > maxSplitWork {
+		deviation--
+	}
+
+	if base+deviation > maxSplitShare {
+		return BillSplitResult{Success: false, Message: "Each share is too large to optimize for coin friction"}
+	}
+
+	scaled := make([]int, len(mca.coins))
+	for i, coin := range mca.coins {
+		scaled[i] = coin / step
+	}
+	minPieces, _ := NewMoneyChangeAlgorithm(scaled).minCoinTable(base + deviation)
+
+	// pieces[s] and spread[s] (total |deviation|) describe the best deviations summing to s - offset
+	const unreachable = math.MaxInt32
+	offset := guests * deviation
+	width := 2*offset + 1
+	pieces := make([]int, width)
+	spread := make([]int, width)
+	for s := range pieces {
+		pieces[s] = unreachable
+	}
+	pieces[offset] = 0
+
+	choice := make([][]int, guests)
+	lowest := -deviation
+	if lowest < -base {
+		lowest = -base
+	}
+	for g := 0; g < guests; g++ {
+		if ctx.Err() != nil {
+			return BillSplitResult{Success: false, Message: cancelledMessage}
+		}
+		nextPieces := make([]int, width)
+		nextSpread := make([]int, width)
+		for s := range nextPieces {
+			nextPieces[s] = unreachable
+		}
+		choice[g] = make([]int, width)
+
+		for s := range pieces {
+			if pieces[s] == unreachable {
+				continue
+			}
+			for d := lowest; d <= deviation; d++ {
+				cost := minPieces[base+d]
+				if cost == unreachable {
+					continue
+				}
+				next := s + d
+				p, dev := pieces[s]+cost, spread[s]+abs(d)
+				if p < nextPieces[next] || (p == nextPieces[next] && dev < nextSpread[next]) {
+					nextPieces[next] = p
+					nextSpread[next] = dev
+					choice[g][next] = d
+				}
+			}
+		}
+		pieces, spread = nextPieces, nextSpread
+	}
+
+	if pieces[offset+extra] == unreachable {
+		return BillSplitResult{Success: false, Message: "Cannot split the total into payable amounts within the allowed adjustment"}
+	}
+
+	amounts := make([]int, guests)
+	guestPieces := make([]int, guests)
+	for g, s := guests-1, offset+extra; g >= 0; g-- {
+		d := choice[g][s]
+		amounts[g] = (base + d) * step
+		guestPieces[g] = minPieces[base+d]
+		s -= d
+	}
+	amounts[0] += remainder
+
+	equal := make([]float64, guests)
+	for g := range equal {
+		equal[g] = 1
+	}
+	fairShares := Apportion(total, equal)
+	adjustments := make([]int, guests)
+	for g := range adjustments {
+		adjustments[g] = amounts[g] - fairShares[g]
+	}
+
+	return BillSplitResult{
+		Amounts:     amounts,
+		FairShares:  fairShares,
+		Adjustments: adjustments,
+		Pieces:      guestPieces,
+		Remainder:   remainder,
+		Success:     true,
+		Message:     fmt.Sprintf("Order split evenly among %d guests with %d pieces in total", guests, pieces[offset+extra]),
+	}
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// abs returns the absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,5 @@
+package algorithms
+
+// cancelledMessage is the message of a result cut short because its context was cancelled,
+// e.g. when the background job running it is cancelled
+const cancelledMessage = "Calculation cancelled"
@@ -0,0 +1,37 @@
+package algorithms
+
+// Cash rounding modes
+const (
+	RoundingUp      = "up"
+	RoundingDown    = "down"
+	RoundingNearest = "nearest"
+)
+
+// RoundToIncrement rounds an amount to a multiple of increment (both in minor units), e.g.
+// Swedish rounding of cash totals to the nearest 5 or 50 cents. Nearest rounds halves up
+func RoundToIncrement(amount, increment int, mode string) int {
+	if increment <= 1 {
+		return amount
+	}
+
+	remainder := amount % increment
+	if remainder < 0 {
+		remainder += increment
+	}
+	if remainder == 0 {
+		return amount
+	}
+
+	down := amount - remainder
+	switch mode {
+	case RoundingUp:
+		return down + increment
+	case RoundingDown:
+		return down
+	default:
+		if remainder*2 >= increment {
+			return down + increment
+		}
+		return down
+	}
+}
@@ -0,0 +1,195 @@
+package algorithms
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"math/big"
+)
+
+// maxEnumerationNodes bounds the search effort spent listing alternative breakdowns
+const maxEnumerationNodes = 200000
+
+// Breakdown is one way of making an amount
+type Breakdown struct {
+	Pieces int
+	Coins  map[int]int // coin value -> quantity
+}
+
+// CountWays returns the number of distinct coin combinations (order ignored) that sum to
+// amount with an unlimited supply of each coin. The count grows quickly, hence big.Int.
+// It stops with ctx's error once ctx is cancelled
+func (mca *MoneyChangeAlgorithm) CountWays(ctx context.Context, amount int) (*big.Int, error) {
+	if amount < 0 {
+		return big.NewInt(0), nil
+	}
+
+	ways := make([]*big.Int, amount+1)
+	for v := range ways {
+		ways[v] = new(big.Int)
+	}
+	ways[0].SetInt64(1)
+
+	// Processing one coin at a time counts combinations rather than permutations
+	for _, coin := range mca.coins {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for v := coin; v <= amount; v++ {
+			ways[v].Add(ways[v], ways[v-coin])
+		}
+	}
+
+	return ways[amount], nil
+}
+
+// TopBreakdowns lists up to k distinct breakdowns of amount ranked by number of pieces.
+// available limits the quantity of each coin (nil means unlimited).
+//
+// It runs a best-first search over "how many of each coin, largest first" using the exact
+// minimum pieces for the remaining coins as heuristic, so complete breakdowns come out of the
+// queue in order of piece count. It stops with ctx's error once ctx is cancelled
+func (mca *MoneyChangeAlgorithm) TopBreakdowns(ctx context.Context, amount int, available map[int]int, k int) ([]Breakdown, error) {
+	if amount < 0 || k <= 0 {
+		return nil, nil
+	}
+
+	n := len(mca.coins)
+	limit := func(i int) int {
+		count := amount / mca.coins[i]
+		if available != nil && available[mca.coins[i]] < count {
+			count = available[mca.coins[i]]
+		}
+		return count
+	}
+	suffix := mca.suffixMinPieces(amount, limit)
+
+	const unreachable = math.MaxInt32
+	if suffix[0][amount] == unreachable {
+		return nil, nil
+	}
+
+	queue := &breakdownQueue{}
+	heap.Push(queue, &breakdownNode{remaining: amount, bound: suffix[0][amount]})
+
+	var result []Breakdown
+	for pushed := 1; queue.Len() > 0 && len(result) < k; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		node := heap.Pop(queue).(*breakdownNode)
+		if node.index == n {
+			coins := make(map[int]int)
+			for i, quantity := range node.quantities {
+				if quantity > 0 {
+					coins[mca.coins[i]] = quantity
+				}
+			}
+			result = append(result, Breakdown{Pieces: node.pieces, Coins: coins})
+			continue
+		}
+
+		coin := mca.coins[node.index]
+		for q := limit(node.index); q >= 0; q-- {
+			if q*coin > node.remaining {
+				continue
+			}
+			rest := node.remaining - q*coin
+			if suffix[node.index+1][rest] == unreachable {
+				continue
+			}
+			if pushed >= maxEnumerationNodes {
+				return result, nil
+			}
+
+			quantities := make([]int, node.index+1)
+			copy(quantities, node.quantities)
+			quantities[node.index] = q
+			heap.Push(queue, &breakdownNode{
+				index:      node.index + 1,
+				remaining:  rest,
+				pieces:     node.pieces + q,
+				bound:      node.pieces + q + suffix[node.index+1][rest],
+				quantities: quantities,
+			})
+			pushed++
+		}
+	}
+
+	return result, nil
+}
+
+// suffixMinPieces returns table[i][v] = fewest pieces summing to v using coins i..n-1 with at
+// most limit(j) pieces of coin j (math.MaxInt32 if impossible). Each coin is added with a
+// sliding-window minimum per residue class, so every row costs O(amount)
+func (mca *MoneyChangeAlgorithm) suffixMinPieces(amount int, limit func(i int) int) [][]int {
+	const unreachable = math.MaxInt32
+	n := len(mca.coins)
+
+	table := make([][]int, n+1)
+	table[n] = make([]int, amount+1)
+	for v := 1; v <= amount; v++ {
+		table[n][v] = unreachable
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		coin, maxCount := mca.coins[i], limit(i)
+		next := table[i+1]
+		row := make([]int, amount+1)
+
+		for residue := 0; residue < coin && residue <= amount; residue++ {
+			// row[residue + j*coin] = j + min over j-maxCount <= j' <= j of (next[residue + j'*coin] - j')
+			var window []int // indices j' with increasing next[...] - j'
+			key := func(j int) int { return next[residue+j*coin] - j }
+			for j := 0; residue+j*coin <= amount; j++ {
+				if next[residue+j*coin] != unreachable {
+					for len(window) > 0 && key(window[len(window)-1]) >= key(j) {
+						window = window[:len(window)-1]
+					}
+					window = append(window, j)
+				}
+				for len(window) > 0 && window[0] < j-maxCount {
+					window = window[1:]
+				}
+
+				if len(window) == 0 {
+					row[residue+j*coin] = unreachable
+				} else {
+					row[residue+j*coin] = key(window[0]) + j
+				}
+			}
+		}
+
+		table[i] = row
+	}
+
+	return table
+}
+
+// breakdownNode is a partial breakdown deciding coins in index order
+type breakdownNode struct {
+	index      int // next coin to decide
+	remaining  int
+	pieces     int
+	bound      int // pieces + fewest pieces for the remaining amount
+	quantities []int
+}
+
+// breakdownQueue is a min-heap of partial breakdowns ordered by bound
+type breakdownQueue []*breakdownNode
+
+func (q breakdownQueue) Len() int { return len(q) }
+func (q breakdownQueue) Less(i, j int) bool {
+	if q[i].bound != q[j].bound {
+		return q[i].bound < q[j].bound
+	}
+	return q[i].index > q[j].index // prefer deeper nodes to finish breakdowns sooner
+}
+func (q breakdownQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *breakdownQueue) Push(x interface{}) { *q = append(*q, x.(*breakdownNode)) }
+func (q *breakdownQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
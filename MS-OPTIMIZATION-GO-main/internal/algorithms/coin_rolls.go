@@ -0,0 +1,28 @@
+package algorithms
+
+// RolledQuantity is the quantity of one denomination split into full rolls and loose pieces
+type RolledQuantity struct {
+	Rolls    int // full rolls (coins) or bundles (bills)
+	RollSize int // pieces per roll, 0 when the denomination is not rolled
+	Loose    int // pieces left outside rolls
+}
+
+// BundleBreakdown groups the pieces of a breakdown into standard rolls or bundles, e.g.
+// 93 quarters with rolls of 40 become 2 rolls and 13 loose quarters. Denominations without
+// a positive roll size stay loose
+func BundleBreakdown(breakdown, rollSizes map[int]int) map[int]RolledQuantity {
+	rolled := make(map[int]RolledQuantity, len(breakdown))
+	for coin, quantity := range breakdown {
+		size := rollSizes[coin]
+		if size <= 0 {
+			rolled[coin] = RolledQuantity{Loose: quantity}
+			continue
+		}
+		rolled[coin] = RolledQuantity{
+			Rolls:    quantity / size,
+			RollSize: size,
+			Loose:    quantity % size,
+		}
+	}
+	return rolled
+}
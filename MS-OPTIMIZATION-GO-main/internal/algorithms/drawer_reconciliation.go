@@ -0,0 +1,158 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FloatResetPlan describes how to bring a counted drawer back to its opening float
+type FloatResetPlan struct {
+	Keep    map[int]int // coin value -> pieces left in the drawer
+	Remove  map[int]int // coin value -> pieces taken out (deposited)
+	Add     map[int]int // coin value -> pieces brought in from the safe or bank
+	Handled int         // pieces removed + pieces added
+	Success bool
+	Message string
+}
+
+// PlanFloatComposition resets a drawer to an exact float composition: surplus pieces are
+// removed and missing pieces added. The composition is fixed, so the handling is too
+func PlanFloatComposition(counted, target map[int]int) FloatResetPlan {
+	plan := FloatResetPlan{
+		Keep:    make(map[int]int),
+		Remove:  make(map[int]int),
+		Add:     make(map[int]int),
+		Success: true,
+	}
+
+	for coin, quantity := range target {
+		if quantity > 0 {
+			plan.Keep[coin] = quantity
+		}
+	}
+	for coin, quantity := range counted {
+		diff := quantity - target[coin]
+		if diff > 0 {
+			plan.Remove[coin] = diff
+			plan.Handled += diff
+		}
+	}
+	for coin, quantity := range target {
+		diff := quantity - counted[coin]
+		if diff > 0 {
+			plan.Add[coin] = diff
+			plan.Handled += diff
+		}
+	}
+
+	plan.Message = fmt.Sprintf("Drawer reset to its float composition handling %d pieces", plan.Handled)
+	return plan
+}
+
+// PlanFloatReset resets a drawer to a float amount with as little handling as possible. Any
+// subset of the counted pieces summing to t <= floatAmount can stay; the gap is topped up with
+// the fewest pieces of this algorithm's denominations. The subset keeping the most pieces for
+// each t comes from a bounded knapsack, then the t minimizing removed + added pieces wins
+func (mca *MoneyChangeAlgorithm) PlanFloatReset(counted map[int]int, floatAmount int) FloatResetPlan {
+	if floatAmount < 0 {
+		return FloatResetPlan{
+			Success: false,
+			Message: "Float amount cannot be negative",
+		}
+	}
+	if floatAmount > maxDPAmount {
+		return FloatResetPlan{
+			Success: false,
+			Message: fmt.Sprintf("Float amount %d exceeds the planning limit of %d", floatAmount, maxDPAmount),
+		}
+	}
+
+	coins := make([]int, 0, len(counted))
+	totalPieces := 0
+	for coin, quantity := range counted {
+		if coin > 0 && quantity > 0 {
+			coins = append(coins, coin)
+			totalPieces += quantity
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(coins)))
+
+	var lots []coinLot
+	for _, coin := range coins {
+		count := counted[coin]
+		if count > floatAmount/coin {
+			count = floatAmount / coin
+		}
+		for size := 1; count > 0; size *= 2 {
+			if size > count {
+				size = count
+			}
+			lots = append(lots, coinLot{coin: coin, quantity: size})
+			count -= size
+		}
+	}
+
+	// maxKept[t] is the most counted pieces summing exactly to t (-1 if impossible)
+	maxKept := make([]int, floatAmount+1)
+	for t := 1; t <= floatAmount; t++ {
+		maxKept[t] = -1
+	}
+	taken := make([][]bool, len(lots))
+	for i, lot := range lots {
+		taken[i] = make([]bool, floatAmount+1)
+		value := lot.coin * lot.quantity
+		for t := floatAmount; t >= value; t-- {
+			if maxKept[t-value] >= 0 && maxKept[t-value]+lot.quantity > maxKept[t] {
+				maxKept[t] = maxKept[t-value] + lot.quantity
+				taken[i][t] = true
+			}
+		}
+	}
+
+	topUp, topUpCoin := mca.minCoinTable(floatAmount)
+
+	const unreachable = math.MaxInt32
+	bestKept, bestHandled := -1, unreachable
+	for t := floatAmount; t >= 0; t-- {
+		if maxKept[t] < 0 || topUp[floatAmount-t] == unreachable {
+			continue
+		}
+		if handled := totalPieces - maxKept[t] + topUp[floatAmount-t]; handled < bestHandled {
+			bestKept, bestHandled = t, handled
+		}
+	}
+
+	if bestKept < 0 {
+		return FloatResetPlan{
+			Success: false,
+			Message: fmt.Sprintf("Float of %d cannot be formed with the counted pieces and available denominations", floatAmount),
+		}
+	}
+
+	plan := FloatResetPlan{
+		Keep:    make(map[int]int),
+		Remove:  make(map[int]int),
+		Add:     make(map[int]int),
+		Handled: bestHandled,
+		Success: true,
+	}
+	for i, t := len(lots)-1, bestKept; i >= 0 && t > 0; i-- {
+		if taken[i][t] {
+			plan.Keep[lots[i].coin] += lots[i].quantity
+			t -= lots[i].coin * lots[i].quantity
+		}
+	}
+	for _, coin := range coins {
+		if removed := counted[coin] - plan.Keep[coin]; removed > 0 {
+			plan.Remove[coin] = removed
+		}
+	}
+	for v := floatAmount - bestKept; v > 0; v -= topUpCoin[v] {
+		plan.Add[topUpCoin[v]]++
+		plan.Keep[topUpCoin[v]]++
+	}
+
+	plan.Message = fmt.Sprintf("Drawer reset to a float of %d handling %d pieces", floatAmount, bestHandled)
+	return plan
+}
@@ -0,0 +1,205 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// EveningGroup is a reservation or a forecast walk-in group to place on the evening's grid
+type EveningGroup struct {
+	Size     int
+	Start    int  // earliest slot the group can be seated in
+	Latest   int  // latest slot it can be seated in, Start for a reservation
+	Length   int  // slots it keeps its table
+	Reserved bool // placed before every walk-in
+
+	// Penalty[t] is added, in slots, to the delay of seating the group at table t when
+	// choosing where it goes; +Inf rules the table out. Nil for no penalties
+	Penalty []float64
+}
+
+// EveningPlacement is the table and first slot of one placed group
+type EveningPlacement struct {
+	Group int // index into the groups
+	Table int // index into the tables
+	Start int // slot
+}
+
+// EveningPlan is a table-by-slot schedule of the evening
+type EveningPlan struct {
+	Placements []EveningPlacement // in the order of the groups
+	Grid       [][]int            // group seated at each table in each slot, -1 when free
+	Unplaced   []int              // groups no table could take
+	Covers     int
+	Success    bool
+	Message    string
+}
+
+// PlanEvening schedules a whole evening of slots at once. Reservations go first, in order of
+// their slot and largest first, each at the smallest free table that fits it; a reservation
+// that finds none may move one reservation out of the way to another table. Walk-ins then fill
+// the gaps in order of arrival, largest first, each at the earliest slot within its window and
+// the smallest table free for its whole stay, which keeps large tables for large groups and
+// seats as many covers as the gaps allow. Penalties delay the seatings they apply to in these
+// choices, and a moved reservation goes where its penalty is lowest. Stays running past the
+// last slot end with the grid. A cancelled ctx stops the plan between groups
+func PlanEvening(ctx context.Context, capacities []int, slots int, groups []EveningGroup) EveningPlan {
+	if slots <= 0 {
+		return EveningPlan{Success: false, Message: "The evening needs at least one slot"}
+	}
+	for i, group := range groups {
+		if group.Size < 1 || group.Length < 1 || group.Start < 0 || group.Latest < group.Start || group.Start >= slots {
+			return EveningPlan{Success: false, Message: fmt.Sprintf("Group %d has an invalid size or time", i)}
+		}
+		if group.Penalty != nil && len(group.Penalty) != len(capacities) {
+			return EveningPlan{Success: false, Message: fmt.Sprintf("Group %d needs a penalty for every table", i)}
+		}
+	}
+
+	grid := make([][]int, len(capacities))
+	for t := range grid {
+		grid[t] = make([]int, slots)
+		for s := range grid[t] {
+			grid[t][s] = -1
+		}
+	}
+	end := func(g, start int) int {
+		if start+groups[g].Length > slots {
+			return slots
+		}
+		return start + groups[g].Length
+	}
+	// cost returns the penalty of seating group g at table t, +Inf if it is ruled out
+	cost := func(g, t int) float64 {
+		if groups[g].Penalty == nil {
+			return 0
+		}
+		return groups[g].Penalty[t]
+	}
+	// free reports whether group g may take table t and the table is free for its stay from start
+	free := func(t, g, start int) bool {
+		if capacities[t] < groups[g].Size || math.IsInf(cost(g, t), 1) {
+			return false
+		}
+		for s := start; s < end(g, start); s++ {
+			if grid[t][s] != -1 {
+				return false
+			}
+		}
+		return true
+	}
+	fill := func(t, g, start, value int) {
+		for s := start; s < end(g, start); s++ {
+			grid[t][s] = value
+		}
+	}
+
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ga, gb := groups[order[a]], groups[order[b]]
+		if ga.Reserved != gb.Reserved {
+			return ga.Reserved
+		}
+		if ga.Start != gb.Start {
+			return ga.Start < gb.Start
+		}
+		return ga.Size > gb.Size
+	})
+
+	placed := make([]bool, len(groups))
+	tableOf, startOf := make([]int, len(groups)), make([]int, len(groups))
+	var unplaced []int
+	for _, g := range order {
+		if ctx.Err() != nil {
+			return EveningPlan{Success: false, Message: cancelledMessage}
+		}
+		// The earliest slot and cheapest table once penalties are added, then the smallest
+		table, start := -1, groups[g].Start
+		bestScore := 0.0
+		for s := groups[g].Start; s <= groups[g].Latest && s < slots; s++ {
+			delay := float64(s - groups[g].Start)
+			if table >= 0 && delay >= bestScore {
+				break
+			}
+			for t := range capacities {
+				if !free(t, g, s) {
+					continue
+				}
+				score := delay + cost(g, t)
+				if table == -1 || score < bestScore || (score == bestScore && s == start && capacities[t] < capacities[table]) {
+					table, start, bestScore = t, s, score
+				}
+			}
+		}
+
+		if table == -1 && groups[g].Reserved {
+			// Move the one reservation in the way at some table to another table, where the
+			// penalties of both add up the lowest
+			moved, to := -1, -1
+			for t := range capacities {
+				if capacities[t] < groups[g].Size || math.IsInf(cost(g, t), 1) {
+					continue
+				}
+				blocking := -1
+				for s := start; s < end(g, start); s++ {
+					if h := grid[t][s]; h != -1 && h != blocking {
+						if blocking != -1 || !groups[h].Reserved {
+							blocking = -2
+							break
+						}
+						blocking = h
+					}
+				}
+				if blocking < 0 {
+					continue
+				}
+				other := -1
+				for u := range capacities {
+					if u == t || !free(u, blocking, startOf[blocking]) {
+						continue
+					}
+					if other == -1 || cost(blocking, u) < cost(blocking, other) ||
+						(cost(blocking, u) == cost(blocking, other) && capacities[u] < capacities[other]) {
+						other = u
+					}
+				}
+				if other == -1 {
+					continue
+				}
+				if score := cost(g, t) + cost(blocking, other) - cost(blocking, t); table == -1 || score < bestScore {
+					table, moved, to, bestScore = t, blocking, other, score
+				}
+			}
+			if table >= 0 {
+				fill(table, moved, startOf[moved], -1)
+				fill(to, moved, startOf[moved], moved)
+				tableOf[moved] = to
+			}
+		}
+
+		if table == -1 {
+			unplaced = append(unplaced, g)
+			continue
+		}
+		fill(table, g, start, g)
+		placed[g], tableOf[g], startOf[g] = true, table, start
+	}
+
+	placements := make([]EveningPlacement, 0, len(groups)-len(unplaced))
+	covers := 0
+	for g := range groups {
+		if placed[g] {
+			placements = append(placements, EveningPlacement{Group: g, Table: tableOf[g], Start: startOf[g]})
+			covers += groups[g].Size
+		}
+	}
+	sort.Ints(unplaced)
+
+	message := fmt.Sprintf("%d of %d groups placed, %d covers", len(placements), len(groups), covers)
+	return EveningPlan{Placements: placements, Grid: grid, Unplaced: unplaced, Covers: covers, Success: true, Message: message}
+}
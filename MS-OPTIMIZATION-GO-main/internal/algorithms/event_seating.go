@@ -0,0 +1,269 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxEventSeatingPasses bounds the local search over moves and swaps
+const maxEventSeatingPasses = 100
+
+// GuestPair links two guests, by index
+type GuestPair struct {
+	A      int
+	B      int
+	Weight float64 // how much the two want to share a table, negative to keep them apart
+}
+
+// EventSeatingPlan is a seat-level chart for an event
+type EventSeatingPlan struct {
+	Tables     [][]int     // guest indexes per table in seat order, the last seat next to the first
+	Score      float64     // total weight of the affinity pairs sharing a table
+	Violations []GuestPair // avoid pairs that could not be kept apart
+	Success    bool
+	Message    string
+}
+
+// PlanEventSeating partitions guests among tables maximizing the weight of the affinity
+// pairs that share a table, never seating avoid pairs together unless the seats leave no
+// choice. tablePenalty[g][t], nil for none, is the cost of seating guest g at table t and
+// +Inf where the guest may not sit there; lower total costs come before affinities, and
+// keeping avoid pairs apart before both. Guests with the strongest ties are placed first,
+// each at the table it is most drawn to, then single moves and pairwise swaps are applied
+// while they improve the chart. At each table, guests are seated next to the guest they are
+// most drawn to
+func PlanEventSeating(guests int, seats []int, affinities, avoid []GuestPair, tablePenalty [][]float64) EventSeatingPlan {
+	capacity := 0
+	for _, n := range seats {
+		if n < 0 {
+			return EventSeatingPlan{Success: false, Message: "Seat counts cannot be negative"}
+		}
+		capacity += n
+	}
+	if guests > capacity {
+		return EventSeatingPlan{Success: false, Message: fmt.Sprintf("%d guests but only %d seats", guests, capacity)}
+	}
+
+	if tablePenalty != nil && len(tablePenalty) != guests {
+		return EventSeatingPlan{Success: false, Message: "Table penalties must be given for every guest"}
+	}
+	for _, row := range tablePenalty {
+		if len(row) != len(seats) {
+			return EventSeatingPlan{Success: false, Message: "Table penalties must be given for every table"}
+		}
+	}
+
+	// Table costs are scaled so the smallest one weighs more than all affinities together,
+	// avoid pairs weigh more than all costs, and a table ruled out more than all avoid pairs
+	affinityTotal := 1.0
+	for _, pair := range affinities {
+		affinityTotal += math.Abs(pair.Weight)
+	}
+	smallestCost := math.Inf(1)
+	for _, row := range tablePenalty {
+		for _, cost := range row {
+			if cost > 0 && cost < smallestCost {
+				smallestCost = cost
+			}
+		}
+	}
+	costUnit := affinityTotal / smallestCost
+	penalty := affinityTotal
+	for _, row := range tablePenalty {
+		highest := 0.0
+		for _, cost := range row {
+			if !math.IsInf(cost, 1) && cost > highest {
+				highest = cost
+			}
+		}
+		penalty += highest * costUnit
+	}
+	ruledOut := penalty * float64(len(avoid)+1)
+
+	weight := make([][]float64, guests)
+	for g := range weight {
+		weight[g] = make([]float64, guests)
+	}
+	for _, pairs := range [][]GuestPair{affinities, avoid} {
+		for _, pair := range pairs {
+			if pair.A < 0 || pair.A >= guests || pair.B < 0 || pair.B >= guests || pair.A == pair.B {
+				return EventSeatingPlan{Success: false, Message: "Guest pairs must name two different guests"}
+			}
+		}
+	}
+	for _, pair := range affinities {
+		weight[pair.A][pair.B] += pair.Weight
+		weight[pair.B][pair.A] += pair.Weight
+	}
+	for _, pair := range avoid {
+		weight[pair.A][pair.B] -= penalty
+		weight[pair.B][pair.A] -= penalty
+	}
+
+	// pull[g][t] is the total weight between guest g and the guests at table t, less the
+	// scaled cost of the table itself
+	pull := make([][]float64, guests)
+	for g := range pull {
+		pull[g] = make([]float64, len(seats))
+		for t := range pull[g] {
+			switch {
+			case tablePenalty == nil:
+			case math.IsInf(tablePenalty[g][t], 1):
+				pull[g][t] = -ruledOut
+			default:
+				pull[g][t] = -tablePenalty[g][t] * costUnit
+			}
+		}
+	}
+	table := make([]int, guests)
+	free := append([]int(nil), seats...)
+	place := func(g, to int) {
+		for h, w := range weight[g] {
+			if w != 0 {
+				pull[h][to] += w
+			}
+		}
+		table[g] = to
+		free[to]--
+	}
+	move := func(g, to int) {
+		from := table[g]
+		for h, w := range weight[g] {
+			if w != 0 {
+				pull[h][from] -= w
+			}
+		}
+		free[from]++
+		place(g, to)
+	}
+
+	ties := make([]float64, guests)
+	order := make([]int, guests)
+	for g := range order {
+		order[g] = g
+		for _, w := range weight[g] {
+			ties[g] += math.Abs(w)
+		}
+		for _, w := range pull[g] {
+			ties[g] += math.Abs(w)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return ties[order[i]] > ties[order[j]] })
+
+	for _, g := range order {
+		best := -1
+		for t := range seats {
+			if free[t] == 0 {
+				continue
+			}
+			if best == -1 || pull[g][t] > pull[g][best] || (pull[g][t] == pull[g][best] && free[t] > free[best]) {
+				best = t
+			}
+		}
+		place(g, best)
+	}
+
+	const epsilon = 1e-9
+	for pass := 0; pass < maxEventSeatingPasses; pass++ {
+		improved := false
+		for g := 0; g < guests; g++ {
+			from := table[g]
+			bestGain, bestTable, bestSwap := epsilon, -1, -1
+			for t := range seats {
+				if t == from || free[t] == 0 {
+					continue
+				}
+				if gain := pull[g][t] - pull[g][from]; gain > bestGain {
+					bestGain, bestTable, bestSwap = gain, t, -1
+				}
+			}
+			for h := 0; h < guests; h++ {
+				to := table[h]
+				if to == from {
+					continue
+				}
+				gain := pull[g][to] - pull[g][from] + pull[h][from] - pull[h][to] - 2*weight[g][h]
+				if gain > bestGain {
+					bestGain, bestTable, bestSwap = gain, to, h
+				}
+			}
+
+			if bestTable == -1 {
+				continue
+			}
+			move(g, bestTable)
+			if bestSwap != -1 {
+				move(bestSwap, from)
+			}
+			improved = true
+		}
+		if !improved {
+			break
+		}
+	}
+
+	members := make([][]int, len(seats))
+	for g, t := range table {
+		members[t] = append(members[t], g)
+	}
+	chart := make([][]int, len(seats))
+	for t, guestsAt := range members {
+		chart[t] = seatOrder(guestsAt, weight, pull, t)
+	}
+
+	for g, t := range table {
+		if tablePenalty != nil && math.IsInf(tablePenalty[g][t], 1) {
+			return EventSeatingPlan{Success: false, Message: "Not enough seats at the tables every guest is allowed to take"}
+		}
+	}
+
+	score := 0.0
+	for _, pair := range affinities {
+		if table[pair.A] == table[pair.B] {
+			score += pair.Weight
+		}
+	}
+	var violations []GuestPair
+	for _, pair := range avoid {
+		if table[pair.A] == table[pair.B] {
+			violations = append(violations, pair)
+		}
+	}
+
+	message := fmt.Sprintf("%d guests seated at %d tables", guests, len(seats))
+	if len(violations) > 0 {
+		message += fmt.Sprintf(", %d avoid pairs share a table", len(violations))
+	}
+	return EventSeatingPlan{Tables: chart, Score: score, Violations: violations, Success: true, Message: message}
+}
+
+// seatOrder orders the guests of table t around it: the guest most drawn to the table
+// first, then each next seat to the remaining guest most drawn to the previous one
+func seatOrder(guests []int, weight, pull [][]float64, t int) []int {
+	if len(guests) == 0 {
+		return []int{}
+	}
+
+	first := 0
+	for i, g := range guests {
+		if pull[g][t] > pull[guests[first]][t] {
+			first = i
+		}
+	}
+	remaining := append([]int(nil), guests...)
+	order := []int{remaining[first]}
+	remaining = append(remaining[:first], remaining[first+1:]...)
+	for len(remaining) > 0 {
+		last := order[len(order)-1]
+		next := 0
+		for i, g := range remaining {
+			if weight[last][g] > weight[last][remaining[next]] {
+				next = i
+			}
+		}
+		order = append(order, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	return order
+}
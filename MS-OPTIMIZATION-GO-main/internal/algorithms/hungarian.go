@@ -0,0 +1,110 @@
+package algorithms
+
+import "math"
+
+// Hungarian solves the assignment problem: each row takes at most one column and each column
+// at most one row, as many pairs as possible are made, and among those the total cost is the
+// lowest. cost[r][c] is +Inf where row r may not take column c; every row has the same
+// number of columns. It returns the column of each row, -1 for a row left without one.
+// Runs in O(n² m) for n = min(rows, columns) and m = max(rows, columns)
+func Hungarian(cost [][]float64) []int {
+	rows := len(cost)
+	if rows == 0 {
+		return nil
+	}
+	columns := len(cost[0])
+	assignment := make([]int, rows)
+	for r := range assignment {
+		assignment[r] = -1
+	}
+	if columns == 0 {
+		return assignment
+	}
+
+	// Forbidden pairs cost more than any set of allowed ones, so they are only used to
+	// complete the square and dropped afterwards
+	largest := 0.0
+	for _, row := range cost {
+		for _, c := range row {
+			if !math.IsInf(c, 0) {
+				largest = math.Max(largest, math.Abs(c))
+			}
+		}
+	}
+	forbidden := (largest + 1) * float64(rows+columns+1)
+
+	// The method below needs no more rows than columns, so solve the transpose otherwise
+	transposed := rows > columns
+	n, m := rows, columns
+	if transposed {
+		n, m = columns, rows
+	}
+	at := func(i, j int) float64 {
+		if transposed {
+			i, j = j, i
+		}
+		c := cost[i][j]
+		if math.IsInf(c, 1) {
+			return forbidden
+		}
+		return c
+	}
+
+	// Potentials u and v keep every reduced cost non-negative; match[j] is the row matched to
+	// column j, 1-based with 0 for none
+	u, v := make([]float64, n+1), make([]float64, m+1)
+	match, way := make([]int, m+1), make([]int, m+1)
+	for i := 1; i <= n; i++ {
+		match[0] = i
+		j0 := 0
+		minv := make([]float64, m+1)
+		used := make([]bool, m+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for match[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := match[j0], math.Inf(1), 0
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				if reduced := at(i0-1, j-1) - u[i0] - v[j]; reduced < minv[j] {
+					minv[j], way[j] = reduced, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[match[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+		// Flip the augmenting path back to the free column it reached
+		for j0 != 0 {
+			j1 := way[j0]
+			match[j0] = match[j1]
+			j0 = j1
+		}
+	}
+
+	for j := 1; j <= m; j++ {
+		if match[j] == 0 {
+			continue
+		}
+		r, c := match[j]-1, j-1
+		if transposed {
+			r, c = c, r
+		}
+		if !math.IsInf(cost[r][c], 1) {
+			assignment[r] = c
+		}
+	}
+	return assignment
+}
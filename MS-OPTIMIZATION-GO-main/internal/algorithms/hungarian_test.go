@@ -0,0 +1,94 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestHungarian(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		name string
+		cost [][]float64
+		want []int
+	}{
+		{"square", [][]float64{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}}, []int{1, 0, 2}},
+		{"more rows than columns", [][]float64{{1, 2}, {5, 1}, {0, 3}}, []int{-1, 1, 0}},
+		{"more columns than rows", [][]float64{{3, 1, 2}}, []int{1}},
+		{"forbidden pairs", [][]float64{{1, inf}, {2, inf}}, []int{0, -1}},
+		// Pairing everyone beats the cheap pair that leaves a row out
+		{"as many pairs as possible", [][]float64{{0, 100}, {1, inf}}, []int{1, 0}},
+		{"no columns", [][]float64{{}, {}}, []int{-1, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hungarian(tt.cost)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for r := range got {
+				if got[r] != tt.want[r] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHungarianMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		rows, columns := 1+rng.Intn(5), 1+rng.Intn(5)
+		cost := make([][]float64, rows)
+		for r := range cost {
+			cost[r] = make([]float64, columns)
+			for c := range cost[r] {
+				cost[r][c] = float64(rng.Intn(20))
+				if rng.Intn(5) == 0 {
+					cost[r][c] = math.Inf(1)
+				}
+			}
+		}
+		assignment := Hungarian(cost)
+		used := make(map[int]bool)
+		pairs, total := 0, 0.0
+		for r, c := range assignment {
+			if c < 0 {
+				continue
+			}
+			if used[c] || math.IsInf(cost[r][c], 1) {
+				t.Fatalf("trial %d: invalid assignment %v for %v", trial, assignment, cost)
+			}
+			used[c] = true
+			pairs++
+			total += cost[r][c]
+		}
+		wantPairs, wantTotal := bestAssignment(cost, 0, make([]bool, columns))
+		if pairs != wantPairs || total != wantTotal {
+			t.Fatalf("trial %d: %d pairs costing %g, want %d costing %g", trial, pairs, total, wantPairs, wantTotal)
+		}
+	}
+}
+
+// bestAssignment returns the most pairs rows r onwards can make with the free columns, and
+// their lowest cost
+func bestAssignment(cost [][]float64, r int, used []bool) (int, float64) {
+	if r == len(cost) {
+		return 0, 0
+	}
+	bestPairs, bestTotal := bestAssignment(cost, r+1, used)
+	for c := range cost[r] {
+		if used[c] || math.IsInf(cost[r][c], 1) {
+			continue
+		}
+		used[c] = true
+		pairs, total := bestAssignment(cost, r+1, used)
+		used[c] = false
+		pairs, total = pairs+1, total+cost[r][c]
+		if pairs > bestPairs || (pairs == bestPairs && total < bestTotal) {
+			bestPairs, bestTotal = pairs, total
+		}
+	}
+	return bestPairs, bestTotal
+}
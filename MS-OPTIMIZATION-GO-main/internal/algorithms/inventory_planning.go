@@ -0,0 +1,125 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+)
+
+// OrderPolicyInput describes the demand and costs of one product for order planning
+type OrderPolicyInput struct {
+	AnnualDemand float64 // units per year
+	OrderingCost float64 // fixed cost per order
+	HoldingCost  float64 // cost of keeping one unit in stock for a year
+	LeadTimeDays float64 // days between placing and receiving an order
+	DaysPerYear  float64 // days the bar sells per year, used to turn annual demand into daily demand
+	PackSize     int     // units per case; orders are whole cases, 1 for single units
+	SafetyStock  float64 // units kept on top of lead-time demand
+}
+
+// OrderPolicy is the economic order quantity and reorder point for a product
+type OrderPolicy struct {
+	EOQ               float64 // unrounded economic order quantity, in units
+	OrderQuantity     int     // EOQ rounded to whole packs
+	OrdersPerYear     float64
+	OrderIntervalDays float64
+	DailyDemand       float64
+	ReorderPoint      float64 // stock level at which to place the next order
+	AnnualOrderCost   float64
+	AnnualHoldingCost float64
+	TotalAnnualCost   float64 // ordering + holding cost at OrderQuantity
+	Success           bool
+	Message           string
+}
+
+// EconomicOrderQuantity computes the EOQ sqrt(2DS/H) and rounds it to the whole number of
+// packs with the lower annual cost. The reorder point covers demand during the lead time
+// plus the safety stock
+func EconomicOrderQuantity(input OrderPolicyInput) OrderPolicy {
+	if input.AnnualDemand <= 0 || input.OrderingCost <= 0 || input.HoldingCost <= 0 || input.DaysPerYear <= 0 {
+		return OrderPolicy{Success: false, Message: "Demand, ordering cost, holding cost and days per year must be positive"}
+	}
+	pack := input.PackSize
+	if pack <= 0 {
+		pack = 1
+	}
+
+	eoq := math.Sqrt(2 * input.AnnualDemand * input.OrderingCost / input.HoldingCost)
+	annualCost := func(quantity float64) float64 {
+		return input.AnnualDemand/quantity*input.OrderingCost + quantity/2*input.HoldingCost
+	}
+
+	// The cost curve is convex, so the best whole number of packs is next to the EOQ
+	packs := math.Max(1, math.Floor(eoq/float64(pack)))
+	if annualCost((packs+1)*float64(pack)) < annualCost(packs*float64(pack)) {
+		packs++
+	}
+	quantity := packs * float64(pack)
+	if quantity > math.MaxInt32 {
+		return OrderPolicy{Success: false, Message: "Order quantity is too large"}
+	}
+
+	ordersPerYear := input.AnnualDemand / quantity
+	dailyDemand := input.AnnualDemand / input.DaysPerYear
+	policy := OrderPolicy{
+		EOQ:               eoq,
+		OrderQuantity:     int(quantity),
+		OrdersPerYear:     ordersPerYear,
+		OrderIntervalDays: input.DaysPerYear / ordersPerYear,
+		DailyDemand:       dailyDemand,
+		ReorderPoint:      dailyDemand*input.LeadTimeDays + input.SafetyStock,
+		AnnualOrderCost:   ordersPerYear * input.OrderingCost,
+		AnnualHoldingCost: quantity / 2 * input.HoldingCost,
+		Success:           true,
+	}
+	policy.TotalAnnualCost = policy.AnnualOrderCost + policy.AnnualHoldingCost
+	policy.Message = fmt.Sprintf("Order %d units every %.1f days", policy.OrderQuantity, policy.OrderIntervalDays)
+	return policy
+}
+
+// SafetyStockInput describes the daily demand and replenishment of one product
+type SafetyStockInput struct {
+	DemandMean       float64 // units per day
+	DemandStdDev     float64 // standard deviation of daily demand
+	LeadTimeDays     float64
+	LeadTimeStdDev   float64 // standard deviation of the lead time, in days
+	ReviewPeriodDays float64 // days between stock counts / orders
+	ServiceLevel     float64 // target probability of not running out during a cycle, in (0, 1)
+}
+
+// SafetyStockResult is the buffer stock and par level for a product
+type SafetyStockResult struct {
+	Z            float64 // standard normal quantile of the service level
+	SafetyStock  float64
+	ReorderPoint float64 // lead-time demand + safety stock
+	ParLevel     float64 // stock to fill up to at each review: demand over review + lead time + safety stock
+	Success      bool
+	Message      string
+}
+
+// SafetyStock computes z·sqrt(L·σd² + d²·σL²) for the service level's normal quantile z,
+// which covers both demand and lead-time variability
+func SafetyStock(input SafetyStockInput) SafetyStockResult {
+	if input.ServiceLevel <= 0 || input.ServiceLevel >= 1 {
+		return SafetyStockResult{Success: false, Message: "Service level must be between 0 and 1"}
+	}
+
+	z := NormalQuantile(input.ServiceLevel)
+	deviation := math.Sqrt(input.LeadTimeDays*input.DemandStdDev*input.DemandStdDev +
+		input.DemandMean*input.DemandMean*input.LeadTimeStdDev*input.LeadTimeStdDev)
+	safetyStock := math.Max(0, z*deviation)
+
+	return SafetyStockResult{
+		Z:            z,
+		SafetyStock:  safetyStock,
+		ReorderPoint: input.DemandMean*input.LeadTimeDays + safetyStock,
+		ParLevel:     input.DemandMean*(input.LeadTimeDays+input.ReviewPeriodDays) + safetyStock,
+		Success:      true,
+		Message:      fmt.Sprintf("Safety stock of %.1f units for a %.1f%% service level", safetyStock, input.ServiceLevel*100),
+	}
+}
+
+// NormalQuantile returns the z-score below which a standard normal variable falls with
+// probability p, e.g. 1.645 for 0.95
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
@@ -0,0 +1,82 @@
+package algorithms
+
+import "sort"
+
+// Point is a location on the floor
+type Point struct {
+	X, Y float64
+}
+
+// KDTree is a 2-d tree over a fixed set of points, for finding the points near a location
+// without scanning them all
+type KDTree struct {
+	points []Point
+	nodes  []int // point indices, each subtree's median at the middle of its range
+}
+
+// NewKDTree builds a tree over points in O(n log² n)
+func NewKDTree(points []Point) *KDTree {
+	tree := &KDTree{points: points, nodes: make([]int, len(points))}
+	for i := range tree.nodes {
+		tree.nodes[i] = i
+	}
+	tree.build(0, len(points), 0)
+	return tree
+}
+
+// build orders nodes[lo:hi] so its median by the depth's axis is in the middle, with the
+// points before it no greater and the points after it no smaller on that axis
+func (kt *KDTree) build(lo, hi, depth int) {
+	if hi-lo <= 1 {
+		return
+	}
+	nodes := kt.nodes[lo:hi]
+	sort.Slice(nodes, func(a, b int) bool {
+		return kt.coordinate(nodes[a], depth) < kt.coordinate(nodes[b], depth)
+	})
+	mid := lo + (hi-lo)/2
+	kt.build(lo, mid, depth+1)
+	kt.build(mid+1, hi, depth+1)
+}
+
+// coordinate returns point i's coordinate on the axis split at depth
+func (kt *KDTree) coordinate(i, depth int) float64 {
+	if depth%2 == 0 {
+		return kt.points[i].X
+	}
+	return kt.points[i].Y
+}
+
+// Within returns the indices of the points at most radius from center, in increasing order.
+// It visits O(√n + k) nodes for k results
+func (kt *KDTree) Within(center Point, radius float64) []int {
+	var found []int
+	kt.within(0, len(kt.nodes), 0, center, radius, &found)
+	sort.Ints(found)
+	return found
+}
+
+// within collects the points near center in the subtree over nodes[lo:hi]
+func (kt *KDTree) within(lo, hi, depth int, center Point, radius float64, found *[]int) {
+	if lo >= hi {
+		return
+	}
+	mid := lo + (hi-lo)/2
+	i := kt.nodes[mid]
+	dx, dy := kt.points[i].X-center.X, kt.points[i].Y-center.Y
+	if dx*dx+dy*dy <= radius*radius {
+		*found = append(*found, i)
+	}
+
+	offset := center.X - kt.points[i].X
+	if depth%2 == 1 {
+		offset = center.Y - kt.points[i].Y
+	}
+	// Each side of the split is searched only if the circle reaches it
+	if offset <= radius {
+		kt.within(lo, mid, depth+1, center, radius, found)
+	}
+	if offset >= -radius {
+		kt.within(mid+1, hi, depth+1, center, radius, found)
+	}
+}
@@ -2,12 +2,39 @@ package algorithms
 
 import (
 	"fmt"
+	"math"
 	"sort"
 )
 
-// MoneyChangeAlgorithm implements a greedy algorithm for optimal coin change
+// Change calculation modes
+const (
+	ChangeModeGreedy = "greedy"
+	ChangeModeDP     = "dp"
+	ChangeModeAuto   = "auto" // greedy for canonical coin systems, dp otherwise
+)
+
+// maxDPAmount bounds the amounts (in minor units) dynamic programming tables are built for;
+// larger amounts are reduced first where that is safe or rejected
+const maxDPAmount = 1000000
+
+// Change strategies: minimize pieces or keep the drawer balanced
+const (
+	StrategyMinCoins      = "min_coins"
+	StrategyBalanceDrawer = "balance_drawer"
+)
+
+// Breakdown preferences between bills and coins
+const (
+	PreferenceBalanced = "balanced"
+	PreferenceBills    = "prefer_bills"
+	PreferenceCoins    = "prefer_coins"
+)
+
+// MoneyChangeAlgorithm implements greedy and dynamic programming algorithms for optimal coin change
 type MoneyChangeAlgorithm struct {
-	coins []int
+	coins        []int
+	smallestBill int  // denominations >= smallestBill are bills, 0 means everything is a coin
+	canonical    bool // greedy change is optimal for every amount
 }
 
 // NewMoneyChangeAlgorithm creates a new instance with available coin denominations
@@ -18,8 +45,79 @@ func NewMoneyChangeAlgorithm(coins []int) *MoneyChangeAlgorithm {
 	sort.Sort(sort.Reverse(sort.IntSlice(sortedCoins)))
 
 	return &MoneyChangeAlgorithm{
-		coins: sortedCoins,
+		coins:     sortedCoins,
+		canonical: isCanonical(sortedCoins),
+	}
+}
+
+// IsCanonical reports whether the greedy algorithm gives optimal change for every amount
+func (mca *MoneyChangeAlgorithm) IsCanonical() bool {
+	return mca.canonical
+}
+
+// ResolveMode maps the auto mode to greedy for canonical coin systems and dp otherwise
+func (mca *MoneyChangeAlgorithm) ResolveMode(mode string) string {
+	if mode != ChangeModeAuto {
+		return mode
 	}
+	if mca.canonical {
+		return ChangeModeGreedy
+	}
+	return ChangeModeDP
+}
+
+// isCanonical runs Pearson's O(n³) test on coins sorted in descending order: if greedy is not
+// optimal, the smallest counterexample is the greedy breakdown of c[i-1]-1 truncated after
+// c[j] with one more c[j], for some i <= j. Coins are first divided by their common step
+// (e.g. 50 for COP); systems whose smallest coin is not that step are not canonical
+func isCanonical(coins []int) bool {
+	n := len(coins)
+	if n == 0 {
+		return false
+	}
+
+	step := 0
+	for _, coin := range coins {
+		step = gcd(step, coin)
+	}
+	if coins[n-1] != step {
+		return false
+	}
+	scaled := make([]int, n)
+	for k, coin := range coins {
+		scaled[k] = coin / step
+	}
+	coins = scaled
+
+	greedy := func(amount int) ([]int, int) {
+		counts := make([]int, n)
+		pieces := 0
+		for k, coin := range coins {
+			counts[k] = amount / coin
+			amount %= coin
+			pieces += counts[k]
+		}
+		return counts, pieces
+	}
+
+	for i := 1; i < n; i++ {
+		below, _ := greedy(coins[i-1] - 1)
+		for j := i; j < n; j++ {
+			amount, pieces := 0, 0
+			for k := 0; k <= j; k++ {
+				count := below[k]
+				if k == j {
+					count++
+				}
+				amount += count * coins[k]
+				pieces += count
+			}
+			if _, greedyPieces := greedy(amount); greedyPieces > pieces {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // ChangeResult represents the result of the change calculation
@@ -28,6 +126,41 @@ type ChangeResult struct {
 	Breakdown  map[int]int // coin value -> quantity
 	Success    bool
 	Message    string
+
+	// Drawer constraints: set when the available coins cannot cover the amount
+	Insufficient bool
+	Shortfall    int
+
+	// Algorithm that produced the breakdown when it may differ from the requested mode
+	Algorithm string
+
+	// Breakdown split into bills and coins (see SetSmallestBill)
+	Bills map[int]int
+	Coins map[int]int
+}
+
+// SetSmallestBill sets the lowest denomination issued as a bill; smaller ones are coins
+func (mca *MoneyChangeAlgorithm) SetSmallestBill(value int) {
+	mca.smallestBill = value
+}
+
+// IsBill reports whether a denomination is a bill rather than a coin
+func (mca *MoneyChangeAlgorithm) IsBill(value int) bool {
+	return mca.smallestBill > 0 && value >= mca.smallestBill
+}
+
+// splitPieces fills the Bills and Coins maps of a result from its breakdown
+func (mca *MoneyChangeAlgorithm) splitPieces(result ChangeResult) ChangeResult {
+	result.Bills = make(map[int]int)
+	result.Coins = make(map[int]int)
+	for value, quantity := range result.Breakdown {
+		if mca.IsBill(value) {
+			result.Bills[value] = quantity
+		} else {
+			result.Coins[value] = quantity
+		}
+	}
+	return result
 }
 
 // CalculateChange finds the optimal combination of coins for a given amount
@@ -80,6 +213,130 @@ func (mca *MoneyChangeAlgorithm) CalculateChange(amount int) ChangeResult {
 	}
 }
 
+// CalculateChangeWithMode dispatches the change calculation to the requested algorithm
+func (mca *MoneyChangeAlgorithm) CalculateChangeWithMode(amount int, mode string) ChangeResult {
+	var result ChangeResult
+	switch mca.ResolveMode(mode) {
+	case ChangeModeDP:
+		result = mca.CalculateChangeDP(amount)
+		result.Algorithm = ChangeModeDP
+	default:
+		result = mca.CalculateChange(amount)
+		result.Algorithm = ChangeModeGreedy
+	}
+	return mca.splitPieces(result)
+}
+
+// CalculateChangeDP finds the minimum number of coins for a given amount using dynamic programming.
+// Unlike the greedy approach it is optimal for any coin system, e.g. {4, 3, 1} for an amount of 6
+func (mca *MoneyChangeAlgorithm) CalculateChangeDP(amount int) ChangeResult {
+	if amount < 0 {
+		return ChangeResult{
+			Success: false,
+			Message: "Amount cannot be negative",
+		}
+	}
+
+	if amount == 0 {
+		return ChangeResult{
+			TotalCoins: 0,
+			Breakdown:  make(map[int]int),
+			Success:    true,
+			Message:    "No change needed",
+		}
+	}
+
+	// Large amounts are mostly made of the largest coin; only the rest needs a table
+	largest := mca.largestCoinReduction(amount, nil)
+	rest := amount
+	if largest > 0 {
+		rest -= largest * mca.coins[0]
+	}
+	if rest > maxDPAmount {
+		return ChangeResult{
+			Success: false,
+			Message: fmt.Sprintf("Amount %d is too large for the dp solver with these denominations", amount),
+		}
+	}
+
+	const unreachable = math.MaxInt32
+	minCoins, lastCoin := mca.minCoinTable(rest)
+
+	if minCoins[rest] == unreachable {
+		return ChangeResult{
+			Success: false,
+			Message: fmt.Sprintf("Cannot make exact change for %d with the available coins", amount),
+		}
+	}
+
+	// Walk back through the chosen coins to rebuild the breakdown
+	breakdown := make(map[int]int)
+	for v := rest; v > 0; v -= lastCoin[v] {
+		breakdown[lastCoin[v]]++
+	}
+	if largest > 0 {
+		breakdown[mca.coins[0]] += largest
+	}
+	totalCoins := minCoins[rest] + largest
+
+	return ChangeResult{
+		TotalCoins: totalCoins,
+		Breakdown:  breakdown,
+		Success:    true,
+		Message:    fmt.Sprintf("Change calculated with %d coins", totalCoins),
+	}
+}
+
+// largestCoinReduction returns how many of the largest coin c1 every optimal breakdown of
+// amount contains, with pieceCost pricing each piece (nil for 1 each). A smaller coin c appears
+// fewer than lcm(c1, c)/c times in an optimal breakdown as long as that many cost at least the
+// lcm(c1, c)/c1 pieces of c1 they could be swapped for, which are fewer, so the smaller coins
+// cover at most the sum of lcm(c1, c) - c and the rest of the amount must be made of c1
+func (mca *MoneyChangeAlgorithm) largestCoinReduction(amount int, pieceCost func(int) float64) int {
+	if len(mca.coins) == 0 {
+		return 0
+	}
+	if pieceCost == nil {
+		pieceCost = func(int) float64 { return 1 }
+	}
+
+	largest := mca.coins[0]
+	bound := 0
+	for _, coin := range mca.coins[1:] {
+		multiple := largest / gcd(largest, coin)
+		if multiple > maxDPAmount/coin {
+			return 0 // the bound alone is beyond any table we would build
+		}
+		if float64(multiple)*pieceCost(coin) < float64(coin/gcd(largest, coin))*pieceCost(largest) {
+			return 0 // the smaller coin is cheaper in bulk, so any number of them may be optimal
+		}
+		bound += multiple*coin - coin
+	}
+
+	if amount <= bound {
+		return 0
+	}
+	return (amount - bound) / largest
+}
+
+// minCoinTable returns the fewest coins (math.MaxInt32 if impossible) for every amount up to
+// limit using an unlimited supply, plus the last coin used to rebuild each breakdown
+func (mca *MoneyChangeAlgorithm) minCoinTable(limit int) ([]int, []int) {
+	const unreachable = math.MaxInt32
+	minCoins := make([]int, limit+1)
+	lastCoin := make([]int, limit+1)
+	for v := 1; v <= limit; v++ {
+		minCoins[v] = unreachable
+		for _, coin := range mca.coins {
+			if coin <= v && minCoins[v-coin] != unreachable && minCoins[v-coin]+1 < minCoins[v] {
+				minCoins[v] = minCoins[v-coin] + 1
+				lastCoin[v] = coin
+			}
+		}
+	}
+	return minCoins, lastCoin
+}
+
 // GetAvailableCoins returns the available coin denominations
 func (mca *MoneyChangeAlgorithm) GetAvailableCoins() []int {
 	return append([]int(nil), mca.coins...)
@@ -0,0 +1,274 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+)
+
+// disfavoredPieceCost is the cost of a bill (prefer_coins) or coin (prefer_bills) relative to
+// a favored piece costing 1, so the preference biases the breakdown without forcing it
+const disfavoredPieceCost = 3
+
+// CalculateChangeLimited finds change using only the coins physically available in the drawer.
+// available maps coin value -> quantity on hand; coins missing from the map are treated as empty.
+// Greedy is not optimal once the supply is limited, even for canonical systems (USD 40 from one
+// quarter and four dimes), so auto uses the bounded DP solver. The greedy mode, only when asked
+// for, falls back to the DP solver when the greedy pick runs out of a coin.
+// If exact change is impossible the result reports the largest dispensable amount and the shortfall
+func (mca *MoneyChangeAlgorithm) CalculateChangeLimited(amount int, available map[int]int, mode string) ChangeResult {
+	if mode == ChangeModeGreedy && amount > 0 {
+		if result, ok := mca.greedyLimited(amount, available); ok {
+			result.Algorithm = ChangeModeGreedy
+			return mca.splitPieces(result)
+		}
+	}
+
+	result := mca.CalculateChangeWeighted(amount, nil, available)
+	result.Algorithm = ChangeModeDP
+	return result
+}
+
+// CalculateChangePreferred biases the breakdown towards bills or coins. available may be nil
+// for an unlimited supply; the balanced preference simply minimizes the number of pieces
+func (mca *MoneyChangeAlgorithm) CalculateChangePreferred(amount int, available map[int]int, preference string) ChangeResult {
+	costs := make(map[int]float64, len(mca.coins))
+	for _, coin := range mca.coins {
+		costs[coin] = 1
+		if (preference == PreferenceCoins && mca.IsBill(coin)) || (preference == PreferenceBills && !mca.IsBill(coin)) {
+			costs[coin] = disfavoredPieceCost
+		}
+	}
+
+	result := mca.CalculateChangeWeighted(amount, costs, available)
+	result.Algorithm = ChangeModeDP
+	return result
+}
+
+// CalculateChangeBalanced prefers dispensing denominations the drawer holds in surplus.
+// weights maps coin value -> surplus weight (> 0, missing means 1); a piece costs 1/weight,
+// so a weight of 4 on quarters makes four quarters as cheap as a single neutral bill
+func (mca *MoneyChangeAlgorithm) CalculateChangeBalanced(amount int, available map[int]int, weights map[int]float64) ChangeResult {
+	costs := make(map[int]float64, len(weights))
+	for coin, weight := range weights {
+		if weight > 0 {
+			costs[coin] = 1 / weight
+		}
+	}
+
+	result := mca.CalculateChangeWeighted(amount, costs, available)
+	result.Algorithm = ChangeModeDP
+	return result
+}
+
+// greedyLimited applies the greedy approach capped by the available quantities
+func (mca *MoneyChangeAlgorithm) greedyLimited(amount int, available map[int]int) (ChangeResult, bool) {
+	remaining := amount
+	breakdown := make(map[int]int)
+	totalCoins := 0
+
+	for _, coin := range mca.coins {
+		quantity := remaining / coin
+		if quantity > available[coin] {
+			quantity = available[coin]
+		}
+		if quantity > 0 {
+			breakdown[coin] = quantity
+			remaining -= quantity * coin
+			totalCoins += quantity
+		}
+	}
+
+	if remaining > 0 {
+		return ChangeResult{}, false
+	}
+
+	return ChangeResult{
+		TotalCoins: totalCoins,
+		Breakdown:  breakdown,
+		Success:    true,
+		Message:    fmt.Sprintf("Change calculated with %d coins", totalCoins),
+	}, true
+}
+
+// coinLot is a bundle of identical coins produced by binary splitting of a drawer quantity
+type coinLot struct {
+	coin     int
+	quantity int
+}
+
+// CalculateChangeWeighted finds the breakdown with the lowest total cost, where costs maps
+// coin value -> cost per piece (nil or missing means 1, i.e. minimum number of pieces).
+// available limits the quantity of each coin (nil means unlimited). Ties are broken by
+// fewer pieces. If exact change is impossible the largest dispensable amount is returned
+// together with the shortfall.
+//
+// Quantities are split into lots of 1, 2, 4, ... coins so the bounded problem becomes a 0/1 knapsack;
+// an unlimited supply needs only the O(amount) unbounded table
+func (mca *MoneyChangeAlgorithm) CalculateChangeWeighted(amount int, costs map[int]float64, available map[int]int) ChangeResult {
+	if amount < 0 {
+		return ChangeResult{
+			Success: false,
+			Message: "Amount cannot be negative",
+		}
+	}
+
+	if amount == 0 {
+		return mca.splitPieces(ChangeResult{
+			TotalCoins: 0,
+			Breakdown:  make(map[int]int),
+			Success:    true,
+			Message:    "No change needed",
+		})
+	}
+
+	pieceCost := func(coin int) float64 {
+		if cost, ok := costs[coin]; ok {
+			return cost
+		}
+		return 1
+	}
+
+	if available == nil {
+		return mca.changeWeightedUnlimited(amount, pieceCost)
+	}
+
+	if amount > maxDPAmount {
+		return ChangeResult{
+			Success: false,
+			Message: fmt.Sprintf("Amount %d exceeds the dp solver limit of %d", amount, maxDPAmount),
+		}
+	}
+
+	var lots []coinLot
+	for _, coin := range mca.coins {
+		count := amount / coin
+		if available != nil && available[coin] < count {
+			count = available[coin]
+		}
+		for size := 1; count > 0; size *= 2 {
+			if size > count {
+				size = count
+			}
+			lots = append(lots, coinLot{coin: coin, quantity: size})
+			count -= size
+		}
+	}
+
+	// minCost[v] and pieces[v] describe the cheapest way to reach v; taken[i][v] records whether
+	// lot i was used to reach v so the breakdown can be rebuilt correctly afterwards
+	const epsilon = 1e-9
+	minCost := make([]float64, amount+1)
+	pieces := make([]int, amount+1)
+	for v := 1; v <= amount; v++ {
+		minCost[v] = math.Inf(1)
+	}
+	taken := make([][]bool, len(lots))
+	for i, lot := range lots {
+		taken[i] = make([]bool, amount+1)
+		value := lot.coin * lot.quantity
+		cost := pieceCost(lot.coin) * float64(lot.quantity)
+		for v := amount; v >= value; v-- {
+			if math.IsInf(minCost[v-value], 1) {
+				continue
+			}
+			candidate := minCost[v-value] + cost
+			candidatePieces := pieces[v-value] + lot.quantity
+			if candidate < minCost[v]-epsilon || (candidate < minCost[v]+epsilon && candidatePieces < pieces[v]) {
+				minCost[v] = candidate
+				pieces[v] = candidatePieces
+				taken[i][v] = true
+			}
+		}
+	}
+
+	// Dispense as much as possible when the exact amount cannot be reached
+	best := amount
+	for best > 0 && math.IsInf(minCost[best], 1) {
+		best--
+	}
+
+	breakdown := make(map[int]int)
+	for i, v := len(lots)-1, best; i >= 0 && v > 0; i-- {
+		if taken[i][v] {
+			breakdown[lots[i].coin] += lots[i].quantity
+			v -= lots[i].coin * lots[i].quantity
+		}
+	}
+
+	if best < amount {
+		return mca.splitPieces(ChangeResult{
+			TotalCoins:   pieces[best],
+			Breakdown:    breakdown,
+			Success:      false,
+			Insufficient: true,
+			Shortfall:    amount - best,
+			Message:      fmt.Sprintf("Insufficient change in drawer. Shortfall: %d", amount-best),
+		})
+	}
+
+	return mca.splitPieces(ChangeResult{
+		TotalCoins: pieces[amount],
+		Breakdown:  breakdown,
+		Success:    true,
+		Message:    fmt.Sprintf("Change calculated with %d coins", pieces[amount]),
+	})
+}
+
+// changeWeightedUnlimited finds the cheapest breakdown from an unlimited supply with an
+// unbounded table over the amount left once the largest-coin reduction is taken out
+func (mca *MoneyChangeAlgorithm) changeWeightedUnlimited(amount int, pieceCost func(int) float64) ChangeResult {
+	largest := mca.largestCoinReduction(amount, pieceCost)
+	rest := amount
+	if largest > 0 {
+		rest -= largest * mca.coins[0]
+	}
+	if rest > maxDPAmount {
+		return ChangeResult{
+			Success: false,
+			Message: fmt.Sprintf("Amount %d is too large for the dp solver with these denominations", amount),
+		}
+	}
+
+	const epsilon = 1e-9
+	minCost := make([]float64, rest+1)
+	pieces := make([]int, rest+1)
+	lastCoin := make([]int, rest+1)
+	for v := 1; v <= rest; v++ {
+		minCost[v] = math.Inf(1)
+		for _, coin := range mca.coins {
+			if coin > v || math.IsInf(minCost[v-coin], 1) {
+				continue
+			}
+			candidate := minCost[v-coin] + pieceCost(coin)
+			candidatePieces := pieces[v-coin] + 1
+			if candidate < minCost[v]-epsilon || (candidate < minCost[v]+epsilon && candidatePieces < pieces[v]) {
+				minCost[v] = candidate
+				pieces[v] = candidatePieces
+				lastCoin[v] = coin
+			}
+		}
+	}
+
+	if math.IsInf(minCost[rest], 1) {
+		return ChangeResult{
+			Success: false,
+			Message: fmt.Sprintf("Cannot make exact change for %d with the available coins", amount),
+		}
+	}
+
+	breakdown := make(map[int]int)
+	for v := rest; v > 0; v -= lastCoin[v] {
+		breakdown[lastCoin[v]]++
+	}
+	if largest > 0 {
+		breakdown[mca.coins[0]] += largest
+	}
+	totalCoins := pieces[rest] + largest
+
+	return mca.splitPieces(ChangeResult{
+		TotalCoins: totalCoins,
+		Breakdown:  breakdown,
+		Success:    true,
+		Message:    fmt.Sprintf("Change calculated with %d coins", totalCoins),
+	})
+}
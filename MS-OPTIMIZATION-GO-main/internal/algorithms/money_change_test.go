@@ -0,0 +1,146 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateChangeWithMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		coins     []int
+		amount    int
+		mode      string
+		pieces    int
+		algorithm string
+	}{
+		{"canonical greedy", []int{1, 5, 10, 25}, 67, ChangeModeGreedy, 6, ChangeModeGreedy},
+		{"canonical dp", []int{1, 5, 10, 25}, 67, ChangeModeDP, 6, ChangeModeDP},
+		{"canonical auto picks greedy", []int{1, 5, 10, 25}, 67, ChangeModeAuto, 6, ChangeModeGreedy},
+		{"non-canonical greedy", []int{1, 3, 4}, 6, ChangeModeGreedy, 3, ChangeModeGreedy},
+		{"non-canonical dp", []int{1, 3, 4}, 6, ChangeModeDP, 2, ChangeModeDP},
+		{"non-canonical auto picks dp", []int{1, 3, 4}, 6, ChangeModeAuto, 2, ChangeModeDP},
+		{"zero amount", []int{1, 3, 4}, 0, ChangeModeDP, 0, ChangeModeDP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewMoneyChangeAlgorithm(tt.coins).CalculateChangeWithMode(tt.amount, tt.mode)
+			if !result.Success {
+				t.Fatalf("change failed: %s", result.Message)
+			}
+			if result.TotalCoins != tt.pieces || result.Algorithm != tt.algorithm {
+				t.Errorf("got %d pieces with %s, want %d with %s", result.TotalCoins, result.Algorithm, tt.pieces, tt.algorithm)
+			}
+			if sum := breakdownSum(result.Breakdown); sum != tt.amount {
+				t.Errorf("breakdown adds up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestIsCanonical(t *testing.T) {
+	tests := []struct {
+		coins []int
+		want  bool
+	}{
+		{[]int{1, 5, 10, 25}, true},
+		{[]int{1, 2, 5, 10, 20, 50, 100, 200}, true},
+		{[]int{50, 100, 200, 500, 1000, 2000, 5000, 10000, 20000, 50000, 100000}, true},
+		{[]int{1, 3, 4}, false},
+		{[]int{1, 10, 25}, false},
+		{[]int{}, false},
+	}
+	for _, tt := range tests {
+		if got := NewMoneyChangeAlgorithm(tt.coins).IsCanonical(); got != tt.want {
+			t.Errorf("IsCanonical(%v) = %v, want %v", tt.coins, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateChangeDPLargeAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		coins   []int
+		amount  int
+		pieces  int
+		largest int // pieces of the largest coin
+	}{
+		// 10^15 minor units, the API maximum: all but the remainder in the largest coin
+		{"canonical at the API maximum", []int{1, 5, 10, 25, 100, 500, 1000, 2000, 5000, 10000}, 1000000000000000, 100000000000, 100000000000},
+		{"non-canonical far beyond the table", []int{1, 3, 4}, 1000000000006, 250000000002, 250000000000},
+		{"non-canonical just past the table", []int{1, 3, 4}, maxDPAmount + 2, 250001, 249999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mca := NewMoneyChangeAlgorithm(tt.coins)
+			result := mca.CalculateChangeDP(tt.amount)
+			if !result.Success {
+				t.Fatalf("change failed: %s", result.Message)
+			}
+			if result.TotalCoins != tt.pieces {
+				t.Errorf("got %d pieces, want %d", result.TotalCoins, tt.pieces)
+			}
+			if got := result.Breakdown[mca.GetAvailableCoins()[0]]; got != tt.largest {
+				t.Errorf("got %d of the largest coin, want %d", got, tt.largest)
+			}
+			if sum := breakdownSum(result.Breakdown); sum != tt.amount {
+				t.Errorf("breakdown adds up to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestLargestCoinReductionKeepsDPOptimal(t *testing.T) {
+	// Below the table size the reduced DP must match a full table for every amount
+	coinSets := [][]int{{1, 3, 4}, {1, 10, 25}, {2, 7, 9}, {1, 5, 10, 25}, {6, 9, 20}}
+	for _, coins := range coinSets {
+		mca := NewMoneyChangeAlgorithm(coins)
+		minCoins, _ := mca.minCoinTable(2000)
+		for amount := 1; amount <= 2000; amount++ {
+			result := mca.CalculateChangeDP(amount)
+			reachable := minCoins[amount] != math.MaxInt32
+			if result.Success != reachable {
+				t.Fatalf("coins %v amount %d: success %v, want %v", coins, amount, result.Success, reachable)
+			}
+			if reachable && result.TotalCoins != minCoins[amount] {
+				t.Fatalf("coins %v amount %d: %d pieces, want %d", coins, amount, result.TotalCoins, minCoins[amount])
+			}
+		}
+	}
+}
+
+func TestCalculateChangeDPTableLimit(t *testing.T) {
+	// The largest coin is too far from the next for the reduction, so the whole amount
+	// needs a table and maxDPAmount is the last amount one is built for
+	coins := []int{1, maxDPAmount + 3}
+	tests := []struct {
+		amount int
+		ok     bool
+	}{
+		{maxDPAmount, true},
+		{maxDPAmount + 1, false},
+	}
+	for _, tt := range tests {
+		result := NewMoneyChangeAlgorithm(coins).CalculateChangeDP(tt.amount)
+		if result.Success != tt.ok {
+			t.Errorf("amount %d: success %v, want %v (%s)", tt.amount, result.Success, tt.ok, result.Message)
+		}
+	}
+}
+
+func TestCalculateChangeRejectsNegativeAmounts(t *testing.T) {
+	mca := NewMoneyChangeAlgorithm([]int{1, 5})
+	for _, mode := range []string{ChangeModeGreedy, ChangeModeDP} {
+		if result := mca.CalculateChangeWithMode(-1, mode); result.Success {
+			t.Errorf("%s accepted a negative amount", mode)
+		}
+	}
+}
+
+func breakdownSum(breakdown map[int]int) int {
+	sum := 0
+	for value, quantity := range breakdown {
+		sum += value * quantity
+	}
+	return sum
+}
@@ -0,0 +1,233 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// DrawerSource is the cash a single drawer (e.g. main till or terrace till) holds
+type DrawerSource struct {
+	ID        string
+	Available map[int]int // coin value -> quantity on hand
+}
+
+// MultiDrawerResult represents change dispensed from one drawer, possibly topped up by others
+type MultiDrawerResult struct {
+	Primary    string                 // drawer that hands the change to the customer
+	Breakdown  map[int]int            // all pieces handed over
+	ByDrawer   map[string]map[int]int // drawer id -> pieces it contributes
+	TotalCoins int
+	Transfers  int // pieces moved from other drawers to the primary one
+	Algorithm  string
+	Success    bool
+	Message    string
+
+	Insufficient bool
+	Shortfall    int
+}
+
+// drawerWindowEntry is a candidate in the sliding-window minimum of the multi-drawer DP
+type drawerWindowEntry struct {
+	index int   // position along the residue class
+	key   int64 // cost there minus index times the piece weight
+}
+
+// CalculateChangeMultiDrawer picks the drawer to dispense change from. A drawer that can cover
+// the amount on its own is preferred (fewest pieces, then the earliest listed); otherwise the
+// combination with the fewest pieces transferred between drawers, then the fewest pieces, is used.
+// The greedy mode, only when asked for, first tries each drawer greedily. A cancelled ctx stops
+// the search between drawers
+func (mca *MoneyChangeAlgorithm) CalculateChangeMultiDrawer(ctx context.Context, amount int, drawers []DrawerSource, mode string) MultiDrawerResult {
+	if amount < 0 || len(drawers) == 0 {
+		return MultiDrawerResult{Success: false, Message: "A non-negative amount and at least one drawer are required"}
+	}
+	if amount > maxDPAmount {
+		return MultiDrawerResult{Success: false, Message: fmt.Sprintf("Amount %d exceeds the dp solver limit of %d", amount, maxDPAmount)}
+	}
+
+	if mode == ChangeModeGreedy {
+		best := -1
+		var bestResult ChangeResult
+		for i, drawer := range drawers {
+			result, ok := mca.greedyLimited(amount, drawer.Available)
+			if ok && (best < 0 || result.TotalCoins < bestResult.TotalCoins) {
+				best, bestResult = i, result
+			}
+		}
+		if best >= 0 {
+			return MultiDrawerResult{
+				Primary:    drawers[best].ID,
+				Breakdown:  bestResult.Breakdown,
+				ByDrawer:   map[string]map[int]int{drawers[best].ID: bestResult.Breakdown},
+				TotalCoins: bestResult.TotalCoins,
+				Algorithm:  ChangeModeGreedy,
+				Success:    true,
+				Message:    fmt.Sprintf("Change dispensed from drawer %s with %d coins", drawers[best].ID, bestResult.TotalCoins),
+			}
+		}
+	}
+
+	// One table is reused for every candidate primary drawer; a drawer covering the amount on
+	// its own is the cheapest combination for it, with no transfers
+	cost := make([]int64, amount+1)
+	var window []drawerWindowEntry
+	best, bestReached := -1, 0
+	var bestCost int64
+	for primary := range drawers {
+		if ctx.Err() != nil {
+			return MultiDrawerResult{Success: false, Message: cancelledMessage}
+		}
+		mca.fillDrawerCosts(cost, drawers, primary, nil, &window)
+		reached := reachedAmount(cost)
+		if best < 0 || reached > bestReached || (reached == bestReached && cost[reached] < bestCost) {
+			best, bestReached, bestCost = primary, reached, cost[reached]
+		}
+	}
+	return mca.combineDrawers(amount, drawers, best, cost, &window)
+}
+
+// combineDrawers rebuilds the cheapest combination for the given primary drawer, splitting the
+// pieces taken from the other drawers among them in the order they are listed
+func (mca *MoneyChangeAlgorithm) combineDrawers(amount int, drawers []DrawerSource, primary int, cost []int64, window *[]drawerWindowEntry) MultiDrawerResult {
+	counts := make([][]int32, 2*len(mca.coins))
+	for pass := range counts {
+		counts[pass] = make([]int32, amount+1)
+	}
+	mca.fillDrawerCosts(cost, drawers, primary, counts, window)
+	reached := reachedAmount(cost)
+
+	breakdown := make(map[int]int)
+	byDrawer := make(map[string]map[int]int)
+	take := func(d, coin, quantity int) {
+		id := drawers[d].ID
+		if byDrawer[id] == nil {
+			byDrawer[id] = make(map[int]int)
+		}
+		byDrawer[id][coin] += quantity
+		breakdown[coin] += quantity
+	}
+	for pass, v := len(counts)-1, reached; pass >= 0 && v > 0; pass-- {
+		coin := mca.coins[pass/2]
+		quantity := int(counts[pass][v])
+		if quantity == 0 {
+			continue
+		}
+		v -= quantity * coin
+		if pass%2 == 0 {
+			take(primary, coin, quantity)
+			continue
+		}
+		for d := range drawers {
+			if d == primary || quantity == 0 {
+				continue
+			}
+			moved := drawers[d].Available[coin]
+			if moved > quantity {
+				moved = quantity
+			}
+			if moved > 0 {
+				take(d, coin, moved)
+				quantity -= moved
+			}
+		}
+	}
+
+	scale := int64(amount) + 1
+	result := MultiDrawerResult{
+		Primary:    drawers[primary].ID,
+		Breakdown:  breakdown,
+		ByDrawer:   byDrawer,
+		TotalCoins: int(cost[reached] % scale),
+		Transfers:  int(cost[reached] / scale),
+		Algorithm:  ChangeModeDP,
+		Success:    reached == amount,
+	}
+	if !result.Success {
+		result.Insufficient = true
+		result.Shortfall = amount - reached
+		result.Message = fmt.Sprintf("Insufficient change across drawers. Shortfall: %d", result.Shortfall)
+		return result
+	}
+
+	if result.Transfers == 0 {
+		result.Message = fmt.Sprintf("Change dispensed from drawer %s with %d coins", result.Primary, result.TotalCoins)
+		return result
+	}
+	result.Message = fmt.Sprintf("Change dispensed from drawer %s with %d coins, %d transferred from other drawers",
+		result.Primary, result.TotalCoins, result.Transfers)
+	return result
+}
+
+// fillDrawerCosts fills cost[v] with transfers·(len(cost)) + pieces for the cheapest way to make
+// v when primary hands over the change, where every piece from another drawer is a transfer.
+// Pieces never exceed the amount, so the encoding orders by transfers first. Each coin takes two
+// bounded passes, the primary drawer's pieces and then the others'; counts, if not nil, receives
+// the pieces each pass adds at every amount
+func (mca *MoneyChangeAlgorithm) fillDrawerCosts(cost []int64, drawers []DrawerSource, primary int, counts [][]int32, window *[]drawerWindowEntry) {
+	amount := len(cost) - 1
+	scale := int64(len(cost))
+	cost[0] = 0
+	for v := 1; v <= amount; v++ {
+		cost[v] = math.MaxInt64
+	}
+	for k, coin := range mca.coins {
+		own, others := drawers[primary].Available[coin], 0
+		for d, drawer := range drawers {
+			if d != primary {
+				others += drawer.Available[coin]
+			}
+		}
+		var ownCounts, otherCounts []int32
+		if counts != nil {
+			ownCounts, otherCounts = counts[2*k], counts[2*k+1]
+		}
+		addBoundedPieces(cost, coin, own, 1, ownCounts, window)
+		addBoundedPieces(cost, coin, others, scale+1, otherCounts, window)
+	}
+}
+
+// addBoundedPieces adds up to bound pieces of coin, each costing weight, to the table:
+// cost[v] becomes the minimum over k <= bound of cost[v-k·coin] + k·weight. Along each residue
+// class of the coin that is a sliding-window minimum of cost[r+j·coin] - j·weight, kept in a
+// monotone deque, so a pass is O(amount) whatever the bound
+func addBoundedPieces(cost []int64, coin, bound int, weight int64, counts []int32, window *[]drawerWindowEntry) {
+	amount := len(cost) - 1
+	if bound > amount/coin {
+		bound = amount / coin
+	}
+	if bound <= 0 {
+		return
+	}
+	for r := 0; r < coin && r <= amount; r++ {
+		entries, head := (*window)[:0], 0
+		for j, v := 0, r; v <= amount; j, v = j+1, v+coin {
+			if cost[v] != math.MaxInt64 {
+				key := cost[v] - int64(j)*weight
+				for len(entries) > head && entries[len(entries)-1].key >= key {
+					entries = entries[:len(entries)-1]
+				}
+				entries = append(entries, drawerWindowEntry{index: j, key: key})
+			}
+			for len(entries) > head && entries[head].index < j-bound {
+				head++
+			}
+			if len(entries) > head {
+				cost[v] = entries[head].key + int64(j)*weight
+				if counts != nil {
+					counts[v] = int32(j - entries[head].index)
+				}
+			}
+		}
+		*window = entries
+	}
+}
+
+// reachedAmount returns the largest amount the table can make
+func reachedAmount(cost []int64) int {
+	reached := len(cost) - 1
+	for reached > 0 && cost[reached] == math.MaxInt64 {
+		reached--
+	}
+	return reached
+}
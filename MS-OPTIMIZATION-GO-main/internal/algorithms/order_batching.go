@@ -0,0 +1,135 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BatchItem is a drink the bar can make several of at once
+type BatchItem struct {
+	SetupMinutes float64 // once per batch: fetching bottles, prepping and rinsing the shaker
+	UnitMinutes  float64 // per drink
+	MaxBatch     int     // drinks per batch, 0 for no limit; a larger order is a batch of its own
+}
+
+// BatchOrder is a pending drink order, with times in minutes
+type BatchOrder struct {
+	Item     int
+	Station  int
+	Quantity int
+	Placed   float64
+	MaxDelay float64 // longest the order may wait for later ones to join its batch
+}
+
+// DrinkBatch is a run of one drink at one station
+type DrinkBatch struct {
+	Item     int
+	Station  int
+	Orders   []int
+	Quantity int
+	Start    float64
+	Finish   float64
+}
+
+// BatchPlan groups orders into batches and times them against making each order on its own
+type BatchPlan struct {
+	Batches         []DrinkBatch // by station, then start
+	Ready           []float64    // by order, when its batch finishes
+	BaselineReady   []float64    // by order, when it is ready made on its own in the order placed
+	SetupSaved      float64      // setup minutes no longer spent
+	BaselineAverage float64      // minutes from placing to ready, averaged over the orders
+	BatchedAverage  float64
+	Success         bool
+	Message         string
+}
+
+// PlanBatches groups the orders for the same drink at the same station into batches. Taking
+// each group's orders as they were placed, a batch keeps taking the next one while that
+// arrives before any order in the batch has waited longer than its maximum delay and the
+// batch stays within the drink's size limit. Each station then makes its batches as they
+// are released, when their last order is in, paying the setup once per batch
+func PlanBatches(orders []BatchOrder, items []BatchItem, stations int) BatchPlan {
+	if len(orders) == 0 {
+		return BatchPlan{Success: false, Message: "At least one order is required"}
+	}
+	for i, order := range orders {
+		if order.Item < 0 || order.Item >= len(items) || order.Station < 0 || order.Station >= stations {
+			return BatchPlan{Success: false, Message: fmt.Sprintf("Order %d has an unknown item or station", i)}
+		}
+		if order.Quantity < 1 {
+			return BatchPlan{Success: false, Message: fmt.Sprintf("Order %d needs at least one drink", i)}
+		}
+	}
+
+	byPlaced := make([]int, len(orders))
+	for i := range byPlaced {
+		byPlaced[i] = i
+	}
+	sort.SliceStable(byPlaced, func(a, b int) bool { return orders[byPlaced[a]].Placed < orders[byPlaced[b]].Placed })
+
+	// open holds, by station and item, the batch still taking orders and the last time one may join
+	type openBatch struct {
+		batch    *DrinkBatch
+		deadline float64
+	}
+	open := make(map[[2]int]*openBatch)
+	var batches []*DrinkBatch
+	release := make(map[*DrinkBatch]float64)
+	for _, i := range byPlaced {
+		order := orders[i]
+		key := [2]int{order.Station, order.Item}
+		limit := items[order.Item].MaxBatch
+		ob := open[key]
+		if ob == nil || order.Placed > ob.deadline || (limit > 0 && ob.batch.Quantity+order.Quantity > limit) {
+			ob = &openBatch{batch: &DrinkBatch{Item: order.Item, Station: order.Station}, deadline: order.Placed + order.MaxDelay}
+			open[key] = ob
+			batches = append(batches, ob.batch)
+		}
+		ob.batch.Orders = append(ob.batch.Orders, i)
+		ob.batch.Quantity += order.Quantity
+		release[ob.batch] = order.Placed
+		if deadline := order.Placed + order.MaxDelay; deadline < ob.deadline {
+			ob.deadline = deadline
+		}
+	}
+
+	// Batches were opened in the order of their first order, so sorting stably by release
+	// keeps that order between batches released together
+	sort.SliceStable(batches, func(a, b int) bool { return release[batches[a]] < release[batches[b]] })
+	plan := BatchPlan{Ready: make([]float64, len(orders)), BaselineReady: make([]float64, len(orders)), Success: true}
+	free := make([]float64, stations)
+	for _, batch := range batches {
+		item := items[batch.Item]
+		batch.Start = math.Max(free[batch.Station], release[batch])
+		batch.Finish = batch.Start + item.SetupMinutes + item.UnitMinutes*float64(batch.Quantity)
+		free[batch.Station] = batch.Finish
+		for _, i := range batch.Orders {
+			plan.Ready[i] = batch.Finish
+		}
+		plan.SetupSaved += item.SetupMinutes * float64(len(batch.Orders)-1)
+	}
+
+	free = make([]float64, stations)
+	for _, i := range byPlaced {
+		order, item := orders[i], items[orders[i].Item]
+		start := math.Max(free[order.Station], order.Placed)
+		free[order.Station] = start + item.SetupMinutes + item.UnitMinutes*float64(order.Quantity)
+		plan.BaselineReady[i] = free[order.Station]
+	}
+
+	for i, order := range orders {
+		plan.BaselineAverage += plan.BaselineReady[i] - order.Placed
+		plan.BatchedAverage += plan.Ready[i] - order.Placed
+	}
+	plan.BaselineAverage /= float64(len(orders))
+	plan.BatchedAverage /= float64(len(orders))
+
+	sort.SliceStable(batches, func(a, b int) bool { return batches[a].Station < batches[b].Station })
+	plan.Batches = make([]DrinkBatch, len(batches))
+	for b, batch := range batches {
+		plan.Batches[b] = *batch
+	}
+	plan.Message = fmt.Sprintf("%d orders in %d batches, saving %.1f setup minutes", len(orders), len(batches), plan.SetupSaved)
+	return plan
+}
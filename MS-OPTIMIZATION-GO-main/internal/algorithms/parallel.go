@@ -0,0 +1,42 @@
+package algorithms
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minParallelCells is the matrix size below which rows are filled on the calling goroutine
+const minParallelCells = 20000
+
+// ParallelRows calls fill once for every row of a matrix of the given number of cells,
+// spreading the rows over a pool of one worker per CPU when the matrix is large enough to
+// pay for the goroutines. fill must only write to its own row
+func ParallelRows(rows, cells int, fill func(row int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > rows {
+		workers = rows
+	}
+	if cells < minParallelCells || workers < 2 {
+		for row := 0; row < rows; row++ {
+			fill(row)
+		}
+		return
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for row := range next {
+				fill(row)
+			}
+		}()
+	}
+	for row := 0; row < rows; row++ {
+		next <- row
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,160 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PaymentSuggestion represents the best way for a customer to pay from their wallet
+type PaymentSuggestion struct {
+	AmountPaid    int
+	Payment       map[int]int // coin value -> quantity handed over by the customer
+	Change        map[int]int // coin value -> quantity returned by the cashier
+	PaymentPieces int
+	ChangePieces  int
+	Success       bool
+	Message       string
+}
+
+// SuggestPayment chooses which pieces of the customer's wallet (coin value -> quantity) to hand
+// over for a bill so that the pieces exchanged (payment + change) are minimized. Change is made
+// with this algorithm's denominations. Payments are searched from the total up to the total plus
+// the largest wallet piece, since overpaying by a whole piece more never helps. A cancelled ctx
+// stops the search
+func (mca *MoneyChangeAlgorithm) SuggestPayment(ctx context.Context, total int, wallet map[int]int) PaymentSuggestion {
+	if total <= 0 {
+		return PaymentSuggestion{
+			Success: false,
+			Message: "Total must be positive",
+		}
+	}
+
+	walletTotal := 0
+	largestPiece := 0
+	for value, quantity := range wallet {
+		walletTotal += value * quantity
+		if quantity > 0 && value > largestPiece {
+			largestPiece = value
+		}
+	}
+	if walletTotal < total {
+		return PaymentSuggestion{
+			Success: false,
+			Message: fmt.Sprintf("Wallet holds %d, which does not cover the total of %d", walletTotal, total),
+		}
+	}
+
+	limit := total + largestPiece - 1
+	if limit > walletTotal {
+		limit = walletTotal
+	}
+	if limit > maxDPAmount {
+		return PaymentSuggestion{
+			Success: false,
+			Message: fmt.Sprintf("Total %d exceeds the payment suggestion limit of %d", total, maxDPAmount),
+		}
+	}
+
+	paymentPieces, rebuildPayment, err := boundedMinPieces(ctx, wallet, limit)
+	if err != nil {
+		return PaymentSuggestion{Success: false, Message: cancelledMessage}
+	}
+	changePieces, changeCoin := mca.minCoinTable(limit - total)
+
+	const unreachable = math.MaxInt32
+	bestPaid, bestPieces := -1, unreachable
+	for paid := total; paid <= limit; paid++ {
+		if paymentPieces[paid] == unreachable || changePieces[paid-total] == unreachable {
+			continue
+		}
+		if pieces := paymentPieces[paid] + changePieces[paid-total]; pieces < bestPieces {
+			bestPaid, bestPieces = paid, pieces
+		}
+	}
+
+	if bestPaid < 0 {
+		return PaymentSuggestion{
+			Success: false,
+			Message: "No payment from the wallet can be settled with the available change denominations",
+		}
+	}
+
+	change := make(map[int]int)
+	for v := bestPaid - total; v > 0; v -= changeCoin[v] {
+		change[changeCoin[v]]++
+	}
+
+	return PaymentSuggestion{
+		AmountPaid:    bestPaid,
+		Payment:       rebuildPayment(bestPaid),
+		Change:        change,
+		PaymentPieces: paymentPieces[bestPaid],
+		ChangePieces:  changePieces[bestPaid-total],
+		Success:       true,
+		Message:       fmt.Sprintf("Pay %d using %d pieces and receive %d pieces in change", bestPaid, paymentPieces[bestPaid], changePieces[bestPaid-total]),
+	}
+}
+
+// boundedMinPieces returns the fewest pieces (math.MaxInt32 if impossible) that sum to every
+// amount up to limit using limited quantities, plus a function rebuilding the pieces for an amount.
+// It stops with ctx's error once ctx is cancelled
+func boundedMinPieces(ctx context.Context, available map[int]int, limit int) ([]int, func(amount int) map[int]int, error) {
+	// Iterate in a fixed order so equally good payments are always rebuilt the same way
+	coins := make([]int, 0, len(available))
+	for coin := range available {
+		if coin > 0 {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(coins)))
+
+	var lots []coinLot
+	for _, coin := range coins {
+		count := available[coin]
+		if count > limit/coin {
+			count = limit / coin
+		}
+		for size := 1; count > 0; size *= 2 {
+			if size > count {
+				size = count
+			}
+			lots = append(lots, coinLot{coin: coin, quantity: size})
+			count -= size
+		}
+	}
+
+	const unreachable = math.MaxInt32
+	minPieces := make([]int, limit+1)
+	for v := 1; v <= limit; v++ {
+		minPieces[v] = unreachable
+	}
+	taken := make([][]bool, len(lots))
+	for i, lot := range lots {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		taken[i] = make([]bool, limit+1)
+		value := lot.coin * lot.quantity
+		for v := limit; v >= value; v-- {
+			if minPieces[v-value] != unreachable && minPieces[v-value]+lot.quantity < minPieces[v] {
+				minPieces[v] = minPieces[v-value] + lot.quantity
+				taken[i][v] = true
+			}
+		}
+	}
+
+	rebuild := func(amount int) map[int]int {
+		pieces := make(map[int]int)
+		for i, v := len(lots)-1, amount; i >= 0 && v > 0; i-- {
+			if taken[i][v] {
+				pieces[lots[i].coin] += lots[i].quantity
+				v -= lots[i].coin * lots[i].quantity
+			}
+		}
+		return pieces
+	}
+
+	return minPieces, rebuild, nil
+}
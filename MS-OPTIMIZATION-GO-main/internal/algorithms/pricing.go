@@ -0,0 +1,102 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+)
+
+// PriceInput describes one product for a price recommendation
+type PriceInput struct {
+	Price           float64 // current price
+	UnitCost        float64
+	DailyUnits      float64 // recent sales velocity at the current price
+	Elasticity      float64 // relative change in units per relative change in price, at most 0
+	MinMargin       float64 // lowest (price - cost) / price allowed, below 1
+	MaxChange       float64 // largest relative change from the current price
+	PriceStep       float64 // prices are multiples of it, 0 for any price
+	MaximizeRevenue bool    // instead of profit
+}
+
+// PriceRecommendation is the recommended price of a product and its projected sales
+type PriceRecommendation struct {
+	Price             float64
+	Change            float64 // relative to the current price
+	DailyUnits        float64
+	Revenue           float64 // per day
+	Profit            float64 // per day
+	AtMaxChange       bool    // the best price lies beyond the allowed change
+	AtMinMargin       bool    // the best price would leave less than the minimum margin
+	MarginUnreachable bool    // the minimum margin needs more than the allowed change
+	Success           bool
+	Message           string
+}
+
+// RecommendPrice finds the price with the highest daily profit, or revenue, under a
+// constant-elasticity demand curve units = DailyUnits · (price/Price)^Elasticity, within the
+// allowed change and above the minimum margin. With elasticity e below -1 profit peaks at
+// cost · e/(1+e); otherwise raising the price always pays, and the curve's single peak means
+// the best allowed price is the peak clamped to the guardrails
+func RecommendPrice(input PriceInput) PriceRecommendation {
+	if !(input.Price > 0) || !(input.UnitCost >= 0) || !(input.DailyUnits >= 0) {
+		return PriceRecommendation{Success: false, Message: "Price must be positive and cost and units non-negative"}
+	}
+	if !(input.Elasticity <= 0) || !(input.MinMargin < 1) || !(input.MaxChange >= 0 && input.MaxChange < 1) || !(input.PriceStep >= 0) {
+		return PriceRecommendation{Success: false, Message: "Elasticity must be at most 0, the margin below 1 and the change between 0 and 1"}
+	}
+
+	units := func(price float64) float64 {
+		return input.DailyUnits * math.Pow(price/input.Price, input.Elasticity)
+	}
+	value := func(price float64) float64 {
+		if input.MaximizeRevenue {
+			return price * units(price)
+		}
+		return (price - input.UnitCost) * units(price)
+	}
+
+	e := input.Elasticity
+	ideal := math.Inf(1)
+	switch {
+	case input.MaximizeRevenue && e < -1:
+		ideal = 0
+	case input.MaximizeRevenue && e == -1:
+		ideal = input.Price
+	case !input.MaximizeRevenue && e < -1:
+		ideal = input.UnitCost * e / (1 + e)
+	}
+
+	// Past either guardrail the value only falls further from the peak, so the nearest
+	// allowed price is best
+	recommendation := PriceRecommendation{Success: true}
+	low, high := input.Price*(1-input.MaxChange), input.Price*(1+input.MaxChange)
+	price := math.Min(math.Max(ideal, low), high)
+	recommendation.AtMaxChange = ideal < low || ideal > high
+	if floor := input.UnitCost / (1 - input.MinMargin); floor > low {
+		low = math.Min(floor, high)
+		recommendation.MarginUnreachable = floor > high
+		if price < low {
+			price = low
+			recommendation.AtMinMargin = true
+			recommendation.AtMaxChange = recommendation.MarginUnreachable
+		}
+	}
+
+	if step := input.PriceStep; step > 0 {
+		best := math.Inf(-1)
+		rounded := price
+		for _, candidate := range []float64{math.Floor(price/step) * step, math.Ceil(price/step) * step} {
+			if candidate >= low-1e-9 && candidate <= high+1e-9 && candidate > 0 && value(candidate) > best {
+				best, rounded = value(candidate), candidate
+			}
+		}
+		price = rounded
+	}
+
+	recommendation.Price = price
+	recommendation.Change = price/input.Price - 1
+	recommendation.DailyUnits = units(price)
+	recommendation.Revenue = price * recommendation.DailyUnits
+	recommendation.Profit = (price - input.UnitCost) * recommendation.DailyUnits
+	recommendation.Message = fmt.Sprintf("Price %.2f to %.2f (%+.1f%%)", input.Price, price, recommendation.Change*100)
+	return recommendation
+}
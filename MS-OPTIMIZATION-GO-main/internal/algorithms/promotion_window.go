@@ -0,0 +1,115 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+)
+
+// PromotionWindow is a run of hours [Start, End) under one promotion option
+type PromotionWindow struct {
+	Start  int
+	End    int
+	Option int
+	Gain   float64
+}
+
+// PromotionPlan is the set of promotion windows with the highest total gain
+type PromotionPlan struct {
+	Windows []PromotionWindow
+	Gain    float64
+	Success bool
+	Message string
+}
+
+// PlanPromotionWindows picks at most maxWindows non-overlapping windows of minLength to
+// maxLength hours, each under a single option, with the highest total gain, where gain[h][k]
+// is what running option k in hour h adds. follows[h] tells whether hour h comes right after
+// hour h-1, as a window cannot span a gap. Only windows that gain are used. Dynamic
+// programming over the hours and the windows placed so far, in O(n · L · k · w)
+func PlanPromotionWindows(gain [][]float64, follows []bool, minLength, maxLength, maxWindows int) PromotionPlan {
+	n := len(gain)
+	if n == 0 || len(follows) != n {
+		return PromotionPlan{Success: false, Message: "At least one hour is required"}
+	}
+	options := len(gain[0])
+	for h, row := range gain {
+		if len(row) != options {
+			return PromotionPlan{Success: false, Message: fmt.Sprintf("Hour %d has %d options, expected %d", h, len(row), options)}
+		}
+	}
+	if minLength < 1 || maxLength < minLength || maxWindows < 0 {
+		return PromotionPlan{Success: false, Message: "Window lengths must satisfy 1 <= min <= max and the window count cannot be negative"}
+	}
+
+	// prefix[k][h] is the gain of option k over hours before h
+	prefix := make([][]float64, options)
+	for k := range prefix {
+		prefix[k] = make([]float64, n+1)
+		for h := 0; h < n; h++ {
+			prefix[k][h+1] = prefix[k][h] + gain[h][k]
+		}
+	}
+
+	// best[i][w] is the highest gain over the hours before i with w windows placed, and
+	// from[i][w] how it was reached: the start of the window ending at i and its option,
+	// or start -1 when hour i-1 is left out
+	type step struct{ start, option int }
+	best := make([][]float64, n+1)
+	from := make([][]step, n+1)
+	for i := range best {
+		best[i] = make([]float64, maxWindows+1)
+		from[i] = make([]step, maxWindows+1)
+		for w := range best[i] {
+			best[i][w] = math.Inf(-1)
+		}
+	}
+	best[0][0] = 0
+	for i := 0; i < n; i++ {
+		for w := 0; w <= maxWindows; w++ {
+			if math.IsInf(best[i][w], -1) {
+				continue
+			}
+			if best[i][w] > best[i+1][w] {
+				best[i+1][w], from[i+1][w] = best[i][w], step{-1, 0}
+			}
+			if w == maxWindows {
+				continue
+			}
+			for length := 1; length <= maxLength && i+length <= n; length++ {
+				if length > 1 && !follows[i+length-1] {
+					break
+				}
+				if length < minLength {
+					continue
+				}
+				j := i + length
+				for k := 0; k < options; k++ {
+					g := prefix[k][j] - prefix[k][i]
+					if g > 1e-9 && best[i][w]+g > best[j][w+1] {
+						best[j][w+1], from[j][w+1] = best[i][w]+g, step{i, k}
+					}
+				}
+			}
+		}
+	}
+
+	count := 0
+	for w := 1; w <= maxWindows; w++ {
+		if best[n][w] > best[n][count]+1e-9 {
+			count = w
+		}
+	}
+	plan := PromotionPlan{Gain: best[n][count], Success: true}
+	for i, w := n, count; i > 0; {
+		s := from[i][w]
+		if s.start < 0 {
+			i--
+			continue
+		}
+		g := prefix[s.option][i] - prefix[s.option][s.start]
+		plan.Windows = append([]PromotionWindow{{Start: s.start, End: i, Option: s.option, Gain: g}}, plan.Windows...)
+		i, w = s.start, w-1
+	}
+	plan.Message = fmt.Sprintf("%d promotion windows adding %.2f", len(plan.Windows), plan.Gain)
+	return plan
+}
@@ -0,0 +1,398 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// LPSense is the relation of a constraint's left-hand side to its right-hand side
+type LPSense string
+
+// Constraint senses
+const (
+	LPLessEqual    LPSense = "<="
+	LPGreaterEqual LPSense = ">="
+	LPEqual        LPSense = "="
+)
+
+// LPStatus is the outcome of solving a linear program
+type LPStatus string
+
+// Linear program outcomes
+const (
+	LPOptimal        LPStatus = "optimal"
+	LPInfeasible     LPStatus = "infeasible"
+	LPUnbounded      LPStatus = "unbounded"
+	LPIterationLimit LPStatus = "iteration_limit"
+	LPCancelled      LPStatus = "cancelled"
+)
+
+// Simplex limits. Each pivot updates the whole dense tableau, so the pivots allowed also
+// shrink with its size to keep a solve to about a second
+const (
+	maxSimplexIterations = 50000
+	maxSimplexWork       = 1000000000 // pivots times tableau entries
+	maxDegeneratePivots  = 50         // in a row before switching to Bland's rule
+	simplexEpsilon       = 1e-9
+)
+
+// LPConstraint is one linear constraint, Coefficients · x Sense RHS
+type LPConstraint struct {
+	Coefficients []float64
+	Sense        LPSense
+	RHS          float64
+}
+
+// LPProblem is a linear program over len(Objective) variables
+type LPProblem struct {
+	Objective   []float64
+	Maximize    bool
+	Constraints []LPConstraint
+	Lower       []float64 // per variable, -Inf for none; nil for all 0
+	Upper       []float64 // per variable, +Inf for none; nil for none
+}
+
+// LPSolution is the result of a linear program
+type LPSolution struct {
+	Status     LPStatus
+	X          []float64
+	Objective  float64
+	Duals      []float64 // per constraint, the change in the objective per unit of its RHS
+	Iterations int
+	Success    bool
+	Message    string
+}
+
+// lpTableau is a dense simplex tableau. cost holds the reduced costs z_j - c_j of the current
+// objective, which is maximized; rows hold B⁻¹A with the right-hand side last
+type lpTableau struct {
+	rows       [][]float64
+	cost       []float64
+	basis      []int
+	columns    int
+	iterations int
+	limit      int // pivots allowed over both phases
+	ctx        context.Context
+}
+
+// SolveLP solves a linear program with the two-phase simplex method on a dense tableau. The
+// most negative reduced cost enters, switching to Bland's rule on a run of degenerate pivots
+// so they cannot cycle. Variables with finite bounds are shifted to
+// start at zero, free variables are split into two, and finite upper bounds become extra
+// rows. The duals are read off the final tableau's slack and artificial columns
+func SolveLP(ctx context.Context, p LPProblem) LPSolution {
+	n := len(p.Objective)
+	if n == 0 {
+		return LPSolution{Success: false, Message: "At least one variable is required"}
+	}
+	if (p.Lower != nil && len(p.Lower) != n) || (p.Upper != nil && len(p.Upper) != n) {
+		return LPSolution{Success: false, Message: fmt.Sprintf("Bounds must be given for all %d variables", n)}
+	}
+	for j, c := range p.Objective {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return LPSolution{Success: false, Message: fmt.Sprintf("Objective coefficient %d is invalid", j)}
+		}
+	}
+	for i, c := range p.Constraints {
+		if len(c.Coefficients) != n {
+			return LPSolution{Success: false, Message: fmt.Sprintf("Constraint %d has %d coefficients, expected %d", i, len(c.Coefficients), n)}
+		}
+		if c.Sense != LPLessEqual && c.Sense != LPGreaterEqual && c.Sense != LPEqual {
+			return LPSolution{Success: false, Message: fmt.Sprintf("Constraint %d has unknown sense %q", i, c.Sense)}
+		}
+	}
+
+	// x_j = offset[j] + Σ terms[j], each term a coefficient on a non-negative column y
+	type term struct {
+		column int
+		coef   float64
+	}
+	offset := make([]float64, n)
+	terms := make([][]term, n)
+	var boundRows []LPConstraint
+	var boundColumns []int
+	columns := 0
+	for j := 0; j < n; j++ {
+		lower, upper := 0.0, math.Inf(1)
+		if p.Lower != nil {
+			lower = p.Lower[j]
+		}
+		if p.Upper != nil {
+			upper = p.Upper[j]
+		}
+		if math.IsNaN(lower) || math.IsNaN(upper) || lower > upper || math.IsInf(lower, 1) || math.IsInf(upper, -1) {
+			return LPSolution{Success: false, Message: fmt.Sprintf("Variable %d has invalid bounds", j)}
+		}
+		switch {
+		case !math.IsInf(lower, -1):
+			offset[j] = lower
+			terms[j] = []term{{columns, 1}}
+			if !math.IsInf(upper, 1) {
+				boundRows = append(boundRows, LPConstraint{Sense: LPLessEqual, RHS: upper - lower})
+				boundColumns = append(boundColumns, columns)
+			}
+			columns++
+		case !math.IsInf(upper, 1):
+			offset[j] = upper
+			terms[j] = []term{{columns, -1}}
+			columns++
+		default:
+			terms[j] = []term{{columns, 1}, {columns + 1, -1}}
+			columns += 2
+		}
+	}
+
+	// Rewrite every constraint over the columns with a non-negative right-hand side
+	m := len(p.Constraints) + len(boundRows)
+	coefficients := make([][]float64, m)
+	senses := make([]LPSense, m)
+	rhs := make([]float64, m)
+	flipped := make([]bool, m)
+	for i := 0; i < m; i++ {
+		coefficients[i] = make([]float64, columns)
+		if i < len(p.Constraints) {
+			c := p.Constraints[i]
+			senses[i], rhs[i] = c.Sense, c.RHS
+			for j, a := range c.Coefficients {
+				if math.IsNaN(a) || math.IsInf(a, 0) {
+					return LPSolution{Success: false, Message: fmt.Sprintf("Constraint %d has an invalid coefficient", i)}
+				}
+				rhs[i] -= a * offset[j]
+				for _, t := range terms[j] {
+					coefficients[i][t.column] += a * t.coef
+				}
+			}
+			if math.IsNaN(rhs[i]) || math.IsInf(rhs[i], 0) {
+				return LPSolution{Success: false, Message: fmt.Sprintf("Constraint %d has an invalid right-hand side", i)}
+			}
+		} else {
+			b := i - len(p.Constraints)
+			senses[i], rhs[i] = boundRows[b].Sense, boundRows[b].RHS
+			coefficients[i][boundColumns[b]] = 1
+		}
+		if rhs[i] < 0 {
+			flipped[i] = true
+			rhs[i] = -rhs[i]
+			for j := range coefficients[i] {
+				coefficients[i][j] = -coefficients[i][j]
+			}
+			switch senses[i] {
+			case LPLessEqual:
+				senses[i] = LPGreaterEqual
+			case LPGreaterEqual:
+				senses[i] = LPLessEqual
+			}
+		}
+	}
+
+	// Columns: the variables, a slack or surplus per inequality, then an artificial per
+	// row without a slack to start the basis. identity[i] is the column that started as
+	// the unit vector of row i, which ends up holding row i's dual
+	slacks, artificials := 0, 0
+	for _, sense := range senses {
+		if sense != LPEqual {
+			slacks++
+		}
+		if sense != LPLessEqual {
+			artificials++
+		}
+	}
+	total := columns + slacks + artificials
+	firstArtificial := columns + slacks
+	t := &lpTableau{rows: make([][]float64, m), basis: make([]int, m), columns: total, limit: maxSimplexIterations, ctx: ctx}
+	if size := m * (total + 1); size > 0 && maxSimplexWork/size < t.limit {
+		t.limit = maxSimplexWork/size + 1
+	}
+	identity := make([]int, m)
+	slack, artificial := columns, firstArtificial
+	for i := 0; i < m; i++ {
+		row := make([]float64, total+1)
+		copy(row, coefficients[i])
+		row[total] = rhs[i]
+		switch senses[i] {
+		case LPLessEqual:
+			row[slack] = 1
+			identity[i] = slack
+			slack++
+		case LPGreaterEqual:
+			row[slack] = -1
+			slack++
+			fallthrough
+		case LPEqual:
+			row[artificial] = 1
+			identity[i] = artificial
+			artificial++
+		}
+		t.rows[i] = row
+		t.basis[i] = identity[i]
+	}
+
+	// Phase 1 maximizes minus the sum of the artificials, reaching 0 only if feasible
+	if artificials > 0 {
+		costs := make([]float64, total)
+		for j := firstArtificial; j < total; j++ {
+			costs[j] = -1
+		}
+		t.price(costs)
+		if status := t.run(total); status != LPOptimal {
+			message := "Phase 1 did not finish within the iteration limit"
+			if status == LPCancelled {
+				message = cancelledMessage
+			}
+			return LPSolution{Status: status, Iterations: t.iterations, Success: false, Message: message}
+		}
+		if t.cost[total] < -1e-7 {
+			return LPSolution{Status: LPInfeasible, Iterations: t.iterations, Success: false, Message: "The constraints cannot all be met"}
+		}
+		// Drive artificials still basic at zero out of the basis where a real column can
+		// take their place; rows where none can are redundant and stay as they are
+		for i, b := range t.basis {
+			if b < firstArtificial {
+				continue
+			}
+			for j := 0; j < firstArtificial; j++ {
+				if math.Abs(t.rows[i][j]) > simplexEpsilon {
+					t.pivot(i, j)
+					break
+				}
+			}
+		}
+	}
+
+	// Phase 2 keeps the artificials out of the basis
+	costs := make([]float64, total)
+	for j := 0; j < n; j++ {
+		c := p.Objective[j]
+		if !p.Maximize {
+			c = -c
+		}
+		for _, term := range terms[j] {
+			costs[term.column] += c * term.coef
+		}
+	}
+	t.price(costs)
+	if status := t.run(firstArtificial); status != LPOptimal {
+		message := "The objective can grow without limit"
+		switch status {
+		case LPIterationLimit:
+			message = "The simplex method did not finish within the iteration limit"
+		case LPCancelled:
+			message = cancelledMessage
+		}
+		return LPSolution{Status: status, Iterations: t.iterations, Success: false, Message: message}
+	}
+
+	y := make([]float64, total)
+	for i, b := range t.basis {
+		y[b] = t.rows[i][total]
+	}
+	solution := LPSolution{Status: LPOptimal, X: make([]float64, n), Duals: make([]float64, len(p.Constraints)), Iterations: t.iterations, Success: true}
+	for j := 0; j < n; j++ {
+		solution.X[j] = offset[j]
+		for _, term := range terms[j] {
+			solution.X[j] += term.coef * y[term.column]
+		}
+		solution.Objective += p.Objective[j] * solution.X[j]
+	}
+	for i := range solution.Duals {
+		dual := t.cost[identity[i]]
+		if flipped[i] != !p.Maximize {
+			dual = -dual
+		}
+		solution.Duals[i] = dual + 0 // turns -0 into 0
+	}
+	solution.Message = fmt.Sprintf("Optimal objective %.6g after %d iterations", solution.Objective, t.iterations)
+	return solution
+}
+
+// price sets the reduced costs of the objective with the given column costs for the current
+// basis, along with the objective value in the last entry
+func (t *lpTableau) price(costs []float64) {
+	t.cost = make([]float64, t.columns+1)
+	for j := 0; j < t.columns; j++ {
+		t.cost[j] = -costs[j]
+	}
+	for i, b := range t.basis {
+		if costs[b] == 0 {
+			continue
+		}
+		for j, a := range t.rows[i] {
+			t.cost[j] += costs[b] * a
+		}
+	}
+}
+
+// run pivots until no column before limit can improve the objective. The column with the most
+// negative reduced cost enters; once maxDegeneratePivots pivots in a row leave the objective
+// unchanged, Bland's rule takes over for the rest of the phase: the lowest column with a
+// negative reduced cost enters, and the ratio test breaks ties by the lowest basic column.
+// It stops early once the tableau's context is cancelled
+func (t *lpTableau) run(limit int) LPStatus {
+	degenerate := 0
+	for ; t.iterations < t.limit; t.iterations++ {
+		if t.ctx.Err() != nil {
+			return LPCancelled
+		}
+		bland := degenerate >= maxDegeneratePivots
+		entering := -1
+		for j := 0; j < limit; j++ {
+			if t.cost[j] < -simplexEpsilon && (entering < 0 || (!bland && t.cost[j] < t.cost[entering])) {
+				entering = j
+				if bland {
+					break
+				}
+			}
+		}
+		if entering < 0 {
+			return LPOptimal
+		}
+		leaving := -1
+		ratio := math.Inf(1)
+		for i, row := range t.rows {
+			a := row[entering]
+			if a <= simplexEpsilon {
+				continue
+			}
+			r := row[t.columns] / a
+			if r < ratio-simplexEpsilon || (r <= ratio+simplexEpsilon && leaving >= 0 && t.basis[i] < t.basis[leaving]) {
+				ratio, leaving = r, i
+			}
+		}
+		if leaving < 0 {
+			return LPUnbounded
+		}
+		if ratio <= simplexEpsilon {
+			degenerate++
+		} else if !bland {
+			degenerate = 0
+		}
+		t.pivot(leaving, entering)
+	}
+	return LPIterationLimit
+}
+
+// pivot makes column j basic in row r
+func (t *lpTableau) pivot(r, j int) {
+	pivotRow := t.rows[r]
+	scale := pivotRow[j]
+	for k := range pivotRow {
+		pivotRow[k] /= scale
+	}
+	eliminate := func(row []float64) {
+		factor := row[j]
+		if factor == 0 {
+			return
+		}
+		for k := range row {
+			row[k] -= factor * pivotRow[k]
+		}
+	}
+	for i, row := range t.rows {
+		if i != r {
+			eliminate(row)
+		}
+	}
+	eliminate(t.cost)
+	t.basis[r] = j
+}
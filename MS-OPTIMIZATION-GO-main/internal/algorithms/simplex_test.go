@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSolveLP(t *testing.T) {
+	tests := []struct {
+		name      string
+		problem   LPProblem
+		status    LPStatus
+		objective float64
+		x         []float64
+	}{
+		{
+			name: "maximize",
+			problem: LPProblem{
+				Objective: []float64{3, 5},
+				Maximize:  true,
+				Constraints: []LPConstraint{
+					{Coefficients: []float64{1, 0}, Sense: LPLessEqual, RHS: 4},
+					{Coefficients: []float64{0, 2}, Sense: LPLessEqual, RHS: 12},
+					{Coefficients: []float64{3, 2}, Sense: LPLessEqual, RHS: 18},
+				},
+			},
+			status: LPOptimal, objective: 36, x: []float64{2, 6},
+		},
+		{
+			name: "minimize with greater-equal and equal rows",
+			problem: LPProblem{
+				Objective: []float64{2, 3},
+				Constraints: []LPConstraint{
+					{Coefficients: []float64{1, 1}, Sense: LPGreaterEqual, RHS: 10},
+					{Coefficients: []float64{1, -1}, Sense: LPEqual, RHS: 2},
+				},
+			},
+			status: LPOptimal, objective: 24, x: []float64{6, 4},
+		},
+		{
+			name: "bounds",
+			problem: LPProblem{
+				Objective: []float64{1, 1},
+				Maximize:  true,
+				Constraints: []LPConstraint{
+					{Coefficients: []float64{1, 1}, Sense: LPLessEqual, RHS: 10},
+				},
+				Lower: []float64{1, 2},
+				Upper: []float64{3, math.Inf(1)},
+			},
+			status: LPOptimal, objective: 10,
+		},
+		{
+			name: "infeasible",
+			problem: LPProblem{
+				Objective: []float64{1},
+				Constraints: []LPConstraint{
+					{Coefficients: []float64{1}, Sense: LPLessEqual, RHS: 1},
+					{Coefficients: []float64{1}, Sense: LPGreaterEqual, RHS: 2},
+				},
+			},
+			status: LPInfeasible,
+		},
+		{
+			name: "unbounded",
+			problem: LPProblem{
+				Objective: []float64{1, 1},
+				Maximize:  true,
+				Constraints: []LPConstraint{
+					{Coefficients: []float64{1, -1}, Sense: LPLessEqual, RHS: 1},
+				},
+			},
+			status: LPUnbounded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution := SolveLP(context.Background(), tt.problem)
+			if solution.Status != tt.status {
+				t.Fatalf("status %s, want %s (%s)", solution.Status, tt.status, solution.Message)
+			}
+			if tt.status != LPOptimal {
+				return
+			}
+			if math.Abs(solution.Objective-tt.objective) > 1e-6 {
+				t.Errorf("objective %g, want %g", solution.Objective, tt.objective)
+			}
+			for i, want := range tt.x {
+				if math.Abs(solution.X[i]-want) > 1e-6 {
+					t.Errorf("x[%d] = %g, want %g", i, solution.X[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSolveLPCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	problem := LPProblem{
+		Objective:   []float64{1},
+		Maximize:    true,
+		Constraints: []LPConstraint{{Coefficients: []float64{1}, Sense: LPLessEqual, RHS: 1}},
+	}
+	if solution := SolveLP(ctx, problem); solution.Status != LPCancelled {
+		t.Errorf("status %s, want %s", solution.Status, LPCancelled)
+	}
+}
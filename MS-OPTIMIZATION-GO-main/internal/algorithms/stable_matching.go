@@ -0,0 +1,50 @@
+package algorithms
+
+// StableMatching pairs proposers with receivers by deferred acceptance (Gale–Shapley).
+// preferences[p] lists the receivers proposer p accepts, best first; ranks[r][p] is
+// receiver r's rank of proposer p, lower is better and negative for unacceptable. Each
+// receiver takes at most one proposer. The result gives each proposer's receiver, -1 if
+// unmatched. The matching is stable, no proposer and receiver both prefer each other to
+// their partners, and the best stable matching for every proposer
+func StableMatching(preferences [][]int, ranks [][]int) []int {
+	match := make([]int, len(preferences))
+	for p := range match {
+		match[p] = -1
+	}
+	holder := make([]int, len(ranks)) // proposer held by each receiver
+	for r := range holder {
+		holder[r] = -1
+	}
+	next := make([]int, len(preferences)) // next receiver each proposer will try
+
+	free := make([]int, 0, len(preferences))
+	for p := len(preferences) - 1; p >= 0; p-- {
+		free = append(free, p)
+	}
+	for len(free) > 0 {
+		p := free[len(free)-1]
+		free = free[:len(free)-1]
+
+		for next[p] < len(preferences[p]) {
+			r := preferences[p][next[p]]
+			next[p]++
+			rank := ranks[r][p]
+			if rank < 0 {
+				continue
+			}
+			current := holder[r]
+			if current != -1 && ranks[r][current] <= rank {
+				continue
+			}
+
+			holder[r] = p
+			match[p] = r
+			if current != -1 {
+				match[current] = -1
+				free = append(free, current)
+			}
+			break
+		}
+	}
+	return match
+}
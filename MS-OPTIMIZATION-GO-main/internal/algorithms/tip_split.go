@@ -0,0 +1,84 @@
+package algorithms
+
+// TipShare describes one staff member's claim on a tip pool
+type TipShare struct {
+	ID         string
+	Hours      float64
+	RoleWeight float64
+	Sales      int // in minor units
+}
+
+// TipAllocation is the part of the pool assigned to one staff member
+type TipAllocation struct {
+	ID     string
+	Share  float64 // exact fraction of the pool before rounding
+	Amount int     // in minor units
+}
+
+// TipSplitResult represents the result of a tip pool distribution
+type TipSplitResult struct {
+	Allocations []TipAllocation // same order as the staff passed in
+	Distributed int
+	Success     bool
+	Message     string
+}
+
+// TipSplitAlgorithm distributes tip pools among staff
+type TipSplitAlgorithm struct{}
+
+// NewTipSplitAlgorithm creates a new instance
+func NewTipSplitAlgorithm() *TipSplitAlgorithm {
+	return &TipSplitAlgorithm{}
+}
+
+// SplitTips distributes pool (in minor units) among staff. Each share blends hours × role
+// weight with sales, where salesFactor (0..1) is the part of the pool split by sales
+func (ta *TipSplitAlgorithm) SplitTips(pool int, staff []TipShare, salesFactor float64) TipSplitResult {
+	if len(staff) == 0 {
+		return TipSplitResult{Success: false, Message: "No staff to share the pool"}
+	}
+
+	totalWeighted := 0.0
+	totalSales := 0
+	for _, member := range staff {
+		totalWeighted += member.Hours * member.RoleWeight
+		totalSales += member.Sales
+	}
+
+	// A component nobody earned anything in hands its part of the pool to the other one
+	hoursFactor := 1 - salesFactor
+	switch {
+	case totalWeighted <= 0 && totalSales <= 0:
+		return TipSplitResult{Success: false, Message: "Staff have no hours or sales to share the pool"}
+	case totalWeighted <= 0:
+		hoursFactor, salesFactor = 0, 1
+	case totalSales <= 0:
+		hoursFactor, salesFactor = 1, 0
+	}
+
+	shares := make([]float64, len(staff))
+	for i, member := range staff {
+		if hoursFactor > 0 {
+			shares[i] += hoursFactor * member.Hours * member.RoleWeight / totalWeighted
+		}
+		if salesFactor > 0 {
+			shares[i] += salesFactor * float64(member.Sales) / float64(totalSales)
+		}
+	}
+
+	// Largest remainder keeps whole minor units while summing exactly to the pool
+	amounts := Apportion(pool, shares)
+	allocations := make([]TipAllocation, len(staff))
+	distributed := 0
+	for i, member := range staff {
+		allocations[i] = TipAllocation{ID: member.ID, Share: shares[i], Amount: amounts[i]}
+		distributed += amounts[i]
+	}
+
+	return TipSplitResult{
+		Allocations: allocations,
+		Distributed: distributed,
+		Success:     true,
+		Message:     "Tip pool distributed",
+	}
+}
@@ -0,0 +1,124 @@
+package algorithms
+
+import "fmt"
+
+// maxTwoOptPasses bounds the 2-opt improvement passes over a tour
+const maxTwoOptPasses = 100
+
+// TSPTour is a visiting order of the stops
+type TSPTour struct {
+	Order           []int // stops in visiting order, starting with the start stop
+	Distance        float64
+	InitialDistance float64 // of the nearest-neighbor tour before 2-opt
+	Success         bool
+	Message         string
+}
+
+// SolveTSP orders the stops of a distance matrix, which need not be symmetric, starting at
+// start and, with returnToStart, going back to it at the end. A nearest-neighbor tour is
+// improved with 2-opt, reversing stretches of it while that shortens it
+func SolveTSP(dist [][]float64, start int, returnToStart bool) TSPTour {
+	n := len(dist)
+	if n == 0 {
+		return TSPTour{Success: false, Message: "At least one stop is required"}
+	}
+	for i, row := range dist {
+		if len(row) != n {
+			return TSPTour{Success: false, Message: fmt.Sprintf("Row %d of the distance matrix has %d entries, expected %d", i, len(row), n)}
+		}
+	}
+	if start < 0 || start >= n {
+		return TSPTour{Success: false, Message: "Start stop is out of range"}
+	}
+
+	order := nearestNeighborTour(dist, start)
+	initial := tourLength(dist, order, returnToStart)
+	order = twoOpt(dist, order, returnToStart)
+	distance := tourLength(dist, order, returnToStart)
+
+	return TSPTour{
+		Order:           order,
+		Distance:        distance,
+		InitialDistance: initial,
+		Success:         true,
+		Message:         fmt.Sprintf("Route through %d stops of length %.2f", n, distance),
+	}
+}
+
+// nearestNeighborTour starts at start and always goes on to the closest unvisited stop
+func nearestNeighborTour(dist [][]float64, start int) []int {
+	visited := make([]bool, len(dist))
+	order := make([]int, 0, len(dist))
+	for current := start; current >= 0; {
+		visited[current] = true
+		order = append(order, current)
+		next := -1
+		for j := range dist {
+			if !visited[j] && (next == -1 || dist[current][j] < dist[current][next]) {
+				next = j
+			}
+		}
+		current = next
+	}
+	return order
+}
+
+// tourLength adds up the legs of a tour, and the way back to its first stop if closed
+func tourLength(dist [][]float64, order []int, closed bool) float64 {
+	length := 0.0
+	for i := 1; i < len(order); i++ {
+		length += dist[order[i-1]][order[i]]
+	}
+	if closed && len(order) > 1 {
+		length += dist[order[len(order)-1]][order[0]]
+	}
+	return length
+}
+
+// twoOpt reverses the stretch order[i..j] whenever that shortens the tour, keeping the first
+// stop in place, until no reversal helps or the passes run out
+func twoOpt(dist [][]float64, order []int, closed bool) []int {
+	n := len(order)
+	// leg is the distance from position a to position b, with n standing for the way back
+	// to the first stop on a closed tour and for nowhere on an open one
+	leg := func(a, b int) float64 {
+		if b == n {
+			if !closed {
+				return 0
+			}
+			return dist[order[a]][order[0]]
+		}
+		return dist[order[a]][order[b]]
+	}
+
+	symmetric := true
+	for a := range dist {
+		for b := a + 1; b < len(dist); b++ {
+			symmetric = symmetric && dist[a][b] == dist[b][a]
+		}
+	}
+
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				// Reversing order[i..j] replaces the legs into i and out of j, and turns
+				// every leg inside the stretch around, which matters for one-way distances
+				delta := dist[order[i-1]][order[j]] + leg(i, j+1) - leg(i-1, i) - leg(j, j+1)
+				for k := i; k < j && !symmetric; k++ {
+					delta += dist[order[k+1]][order[k]] - dist[order[k]][order[k+1]]
+				}
+				if delta < -1e-9 {
+					for a, b := i, j; a < b; a, b = a+1, b-1 {
+						order[a], order[b] = order[b], order[a]
+					}
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return order
+}
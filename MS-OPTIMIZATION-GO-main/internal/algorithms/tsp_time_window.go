@@ -0,0 +1,181 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxWindowPasses bounds the improvement passes over a tour with time windows, each of which
+// tries O(n²) moves at O(n) apiece
+const maxWindowPasses = 20
+
+// TSPWindows holds the timing of the stops, in minutes from leaving the start stop. The start
+// stop's own entries are not used
+type TSPWindows struct {
+	Travel   [][]float64 // minutes from stop to stop
+	Service  []float64   // minutes spent at each stop
+	Earliest []float64   // a stop reached sooner waits until then
+	Latest   []float64   // latest arrival, +Inf for none
+}
+
+// TSPStopTime is the timing of one stop of a tour
+type TSPStopTime struct {
+	Arrive float64
+	Wait   float64 // until the stop's earliest time
+	Late   float64 // minutes past the stop's latest arrival
+}
+
+// TSPWindowTour is a visiting order of stops with time windows
+type TSPWindowTour struct {
+	Order    []int
+	Times    []TSPStopTime // by position in Order, zero for the start
+	Finish   float64       // leaving the last stop, or back at the start on a round trip
+	Distance float64
+	Lateness float64 // minutes late over all stops, 0 when every window is met
+	Success  bool
+	Message  string
+}
+
+// ScheduleTour times a tour that leaves its first stop at minute 0, returning each position's
+// timing, the finish and the total lateness
+func ScheduleTour(w TSPWindows, order []int, closed bool) ([]TSPStopTime, float64, float64) {
+	times := make([]TSPStopTime, len(order))
+	clock, lateness := 0.0, 0.0
+	for p := 1; p < len(order); p++ {
+		s := order[p]
+		arrive := clock + w.Travel[order[p-1]][s]
+		begin := math.Max(arrive, w.Earliest[s])
+		late := math.Max(0, arrive-w.Latest[s])
+		times[p] = TSPStopTime{Arrive: arrive, Wait: begin - arrive, Late: late}
+		lateness += late
+		clock = begin + w.Service[s]
+	}
+	if closed && len(order) > 1 {
+		clock += w.Travel[order[len(order)-1]][order[0]]
+	}
+	return times, clock, lateness
+}
+
+// SolveTSPWindows orders stops that must each be reached within a time window, first keeping
+// the total lateness lowest and then the distance. It starts from the better of the
+// nearest-neighbor tour and the stops by latest arrival, and improves it by reversing
+// stretches and moving single stops while that helps
+func SolveTSPWindows(dist [][]float64, w TSPWindows, start int, returnToStart bool) TSPWindowTour {
+	n := len(dist)
+	if n == 0 {
+		return TSPWindowTour{Success: false, Message: "At least one stop is required"}
+	}
+	if len(w.Travel) != n || len(w.Service) != n || len(w.Earliest) != n || len(w.Latest) != n {
+		return TSPWindowTour{Success: false, Message: "Travel, service and window times are required for every stop"}
+	}
+	for i, row := range dist {
+		if len(row) != n || len(w.Travel[i]) != n {
+			return TSPWindowTour{Success: false, Message: fmt.Sprintf("Row %d of the distance or travel matrix does not have %d entries", i, n)}
+		}
+	}
+	if start < 0 || start >= n {
+		return TSPWindowTour{Success: false, Message: "Start stop is out of range"}
+	}
+
+	byDeadline := make([]int, 0, n)
+	for s := range dist {
+		if s != start {
+			byDeadline = append(byDeadline, s)
+		}
+	}
+	sort.SliceStable(byDeadline, func(a, b int) bool {
+		sa, sb := byDeadline[a], byDeadline[b]
+		if w.Latest[sa] != w.Latest[sb] {
+			return w.Latest[sa] < w.Latest[sb]
+		}
+		return w.Earliest[sa] < w.Earliest[sb]
+	})
+	byDeadline = append([]int{start}, byDeadline...)
+
+	order := nearestNeighborTour(dist, start)
+	_, _, lateness := ScheduleTour(w, order, returnToStart)
+	_, _, deadlineLateness := ScheduleTour(w, byDeadline, returnToStart)
+	if windowBetter(deadlineLateness, tourLength(dist, byDeadline, returnToStart), lateness, tourLength(dist, order, returnToStart)) {
+		order = byDeadline
+	}
+	order = improveWindowTour(dist, w, order, returnToStart)
+
+	times, finish, lateness := ScheduleTour(w, order, returnToStart)
+	distance := tourLength(dist, order, returnToStart)
+	message := fmt.Sprintf("Route through %d stops of length %.2f meeting every time window", n, distance)
+	if lateness > 0 {
+		message = fmt.Sprintf("Route through %d stops of length %.2f, %.0f minutes late over its time windows", n, distance, lateness)
+	}
+	return TSPWindowTour{
+		Order:    order,
+		Times:    times,
+		Finish:   finish,
+		Distance: distance,
+		Lateness: lateness,
+		Success:  true,
+		Message:  message,
+	}
+}
+
+// windowBetter reports whether a tour late by lateA over distA beats one late by lateB over distB
+func windowBetter(lateA, distA, lateB, distB float64) bool {
+	if math.Abs(lateA-lateB) > 1e-9 {
+		return lateA < lateB
+	}
+	return distA < distB-1e-9
+}
+
+// improveWindowTour applies the first reversal of a stretch or move of a stop that makes the
+// tour better, keeping the first stop in place, until none does or the passes run out
+func improveWindowTour(dist [][]float64, w TSPWindows, order []int, closed bool) []int {
+	n := len(order)
+	cost := func(o []int) (float64, float64) {
+		_, _, lateness := ScheduleTour(w, o, closed)
+		return lateness, tourLength(dist, o, closed)
+	}
+	bestLate, bestDist := cost(order)
+	candidate := make([]int, n)
+	try := func() bool {
+		late, distance := cost(candidate)
+		if !windowBetter(late, distance, bestLate, bestDist) {
+			return false
+		}
+		copy(order, candidate)
+		bestLate, bestDist = late, distance
+		return true
+	}
+
+	for pass := 0; pass < maxWindowPasses; pass++ {
+		improved := false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				copy(candidate, order)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+				improved = try() || improved
+			}
+		}
+		for i := 1; i < n; i++ {
+			for p := 1; p < n; p++ {
+				if p == i {
+					continue
+				}
+				copy(candidate, order)
+				s := candidate[i]
+				if p < i {
+					copy(candidate[p+1:i+1], candidate[p:i])
+				} else {
+					copy(candidate[i:p], candidate[i+1:p+1])
+				}
+				candidate[p] = s
+				improved = try() || improved
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return order
+}
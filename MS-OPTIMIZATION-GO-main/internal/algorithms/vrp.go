@@ -0,0 +1,241 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// VRPVehicle is a vehicle available for the deliveries
+type VRPVehicle struct {
+	Capacity   float64
+	MaxMinutes float64 // longest route it can drive, stops included, 0 for no limit
+}
+
+// VRPInput holds a capacitated vehicle routing problem. Index 0 of Dist, Demand and Service
+// is the depot every route starts and ends at, the rest are the stops
+type VRPInput struct {
+	Dist     [][]float64
+	Demand   []float64
+	Service  []float64 // minutes spent at each stop
+	Speed    float64   // distance covered per minute, needed when a vehicle has MaxMinutes
+	Vehicles []VRPVehicle
+}
+
+// VRPRoute is one vehicle's round trip from the depot
+type VRPRoute struct {
+	Vehicle  int
+	Stops    []int // in visiting order, without the depot
+	Load     float64
+	Distance float64
+	Minutes  float64
+}
+
+// VRPSolution splits the stops into vehicle routes
+type VRPSolution struct {
+	Routes   []VRPRoute // by vehicle
+	Unserved []int      // stops no vehicle could take
+	Distance float64
+	Success  bool
+	Message  string
+}
+
+// vrpRoute is a route being built, before it has a vehicle
+type vrpRoute struct {
+	stops                   []int
+	load, distance, service float64
+}
+
+// SolveVRP builds routes with the Clarke–Wright savings heuristic: every stop starts on its
+// own round trip, and two routes are joined end to start, largest saving first, while some
+// vehicle could still drive the result. The routes then go to the smallest vehicles that fit
+// them, the heaviest first; stops left over when the vehicles run out are inserted where they
+// add the least distance if a route has room, and each route is finally improved with 2-opt.
+// A cancelled ctx stops the search between steps
+func SolveVRP(ctx context.Context, in VRPInput) VRPSolution {
+	n := len(in.Dist)
+	if n < 2 {
+		return VRPSolution{Success: false, Message: "A depot and at least one stop are required"}
+	}
+	for i, row := range in.Dist {
+		if len(row) != n {
+			return VRPSolution{Success: false, Message: fmt.Sprintf("Row %d of the distance matrix has %d entries, expected %d", i, len(row), n)}
+		}
+	}
+	if len(in.Demand) != n || len(in.Service) != n {
+		return VRPSolution{Success: false, Message: "Demand and service times are required for the depot and every stop"}
+	}
+	if len(in.Vehicles) == 0 {
+		return VRPSolution{Success: false, Message: "At least one vehicle is required"}
+	}
+	for _, vehicle := range in.Vehicles {
+		if vehicle.MaxMinutes > 0 && !(in.Speed > 0) {
+			return VRPSolution{Success: false, Message: "A speed is required for vehicles with a time limit"}
+		}
+	}
+
+	minutes := func(distance, service float64) float64 {
+		if in.Speed > 0 {
+			return service + distance/in.Speed
+		}
+		return service
+	}
+	drives := func(vehicle VRPVehicle, load, distance, service float64) bool {
+		return load <= vehicle.Capacity && (vehicle.MaxMinutes == 0 || minutes(distance, service) <= vehicle.MaxMinutes)
+	}
+	fits := func(load, distance, service float64) bool {
+		for _, vehicle := range in.Vehicles {
+			if drives(vehicle, load, distance, service) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// routeOf[s] is the route stop s is on, -1 for none
+	var routes []*vrpRoute
+	var unserved []int
+	routeOf := make([]int, n)
+	for s := 1; s < n; s++ {
+		r := &vrpRoute{stops: []int{s}, load: in.Demand[s], distance: in.Dist[0][s] + in.Dist[s][0], service: in.Service[s]}
+		if !fits(r.load, r.distance, r.service) {
+			routeOf[s] = -1
+			unserved = append(unserved, s)
+			continue
+		}
+		routeOf[s] = len(routes)
+		routes = append(routes, r)
+	}
+
+	// Joining a route ending at i to one starting at j saves the way back from i and out to j
+	type saving struct {
+		i, j  int
+		value float64
+	}
+	var savings []saving
+	for i := 1; i < n; i++ {
+		for j := 1; j < n; j++ {
+			if i == j || routeOf[i] < 0 || routeOf[j] < 0 {
+				continue
+			}
+			if value := in.Dist[i][0] + in.Dist[0][j] - in.Dist[i][j]; value > 0 {
+				savings = append(savings, saving{i, j, value})
+			}
+		}
+	}
+	sort.SliceStable(savings, func(a, b int) bool { return savings[a].value > savings[b].value })
+
+	cancelled := VRPSolution{Success: false, Message: cancelledMessage}
+	for _, sv := range savings {
+		if ctx.Err() != nil {
+			return cancelled
+		}
+		a, b := routeOf[sv.i], routeOf[sv.j]
+		if a == b {
+			continue
+		}
+		ra, rb := routes[a], routes[b]
+		if ra.stops[len(ra.stops)-1] != sv.i || rb.stops[0] != sv.j {
+			continue
+		}
+		load, distance, service := ra.load+rb.load, ra.distance+rb.distance-sv.value, ra.service+rb.service
+		if !fits(load, distance, service) {
+			continue
+		}
+		ra.stops = append(ra.stops, rb.stops...)
+		ra.load, ra.distance, ra.service = load, distance, service
+		for _, s := range rb.stops {
+			routeOf[s] = a
+		}
+		routes[b] = nil
+	}
+
+	var built []*vrpRoute
+	for _, r := range routes {
+		if r != nil {
+			built = append(built, r)
+		}
+	}
+	sort.SliceStable(built, func(a, b int) bool { return built[a].load > built[b].load })
+
+	// Each route takes the smallest free vehicle that can drive it
+	vehicleOf := make(map[*vrpRoute]int, len(built))
+	used := make([]bool, len(in.Vehicles))
+	var assigned []*vrpRoute
+	var leftover []int
+	for _, r := range built {
+		best := -1
+		for v, vehicle := range in.Vehicles {
+			if used[v] || !drives(vehicle, r.load, r.distance, r.service) {
+				continue
+			}
+			if best < 0 || vehicle.Capacity < in.Vehicles[best].Capacity {
+				best = v
+			}
+		}
+		if best < 0 {
+			leftover = append(leftover, r.stops...)
+			continue
+		}
+		used[best] = true
+		vehicleOf[r] = best
+		assigned = append(assigned, r)
+	}
+
+	sort.Ints(leftover)
+	for _, s := range leftover {
+		if ctx.Err() != nil {
+			return cancelled
+		}
+		var into *vrpRoute
+		at, added := 0, math.Inf(1)
+		for _, r := range assigned {
+			vehicle := in.Vehicles[vehicleOf[r]]
+			for p := 0; p <= len(r.stops); p++ {
+				before, after := 0, 0
+				if p > 0 {
+					before = r.stops[p-1]
+				}
+				if p < len(r.stops) {
+					after = r.stops[p]
+				}
+				extra := in.Dist[before][s] + in.Dist[s][after] - in.Dist[before][after]
+				if extra < added && drives(vehicle, r.load+in.Demand[s], r.distance+extra, r.service+in.Service[s]) {
+					into, at, added = r, p, extra
+				}
+			}
+		}
+		if into == nil {
+			unserved = append(unserved, s)
+			continue
+		}
+		into.stops = append(into.stops[:at], append([]int{s}, into.stops[at:]...)...)
+		into.load += in.Demand[s]
+		into.distance += added
+		into.service += in.Service[s]
+	}
+	sort.Ints(unserved)
+
+	solution := VRPSolution{Unserved: unserved, Success: true}
+	served := 0
+	for _, r := range assigned {
+		if ctx.Err() != nil {
+			return cancelled
+		}
+		order := twoOpt(in.Dist, append([]int{0}, r.stops...), true)
+		distance := tourLength(in.Dist, order, true)
+		solution.Routes = append(solution.Routes, VRPRoute{
+			Vehicle:  vehicleOf[r],
+			Stops:    order[1:],
+			Load:     r.load,
+			Distance: distance,
+			Minutes:  minutes(distance, r.service),
+		})
+		solution.Distance += distance
+		served += len(r.stops)
+	}
+	sort.Slice(solution.Routes, func(a, b int) bool { return solution.Routes[a].Vehicle < solution.Routes[b].Vehicle })
+	solution.Message = fmt.Sprintf("%d routes serve %d of %d stops over %.2f", len(solution.Routes), served, n-1, solution.Distance)
+	return solution
+}
@@ -0,0 +1,137 @@
+package algorithms
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// lineDistances places the depot and stops on a line at the given positions
+func lineDistances(positions []float64) [][]float64 {
+	dist := make([][]float64, len(positions))
+	for i := range dist {
+		dist[i] = make([]float64, len(positions))
+		for j := range dist[i] {
+			dist[i][j] = math.Abs(positions[i] - positions[j])
+		}
+	}
+	return dist
+}
+
+func TestSolveVRP(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    VRPInput
+		routes   int
+		unserved []int
+		distance float64
+	}{
+		{
+			name: "one vehicle takes every stop",
+			input: VRPInput{
+				Dist:     lineDistances([]float64{0, 1, 2, 3}),
+				Demand:   []float64{0, 1, 1, 1},
+				Service:  []float64{0, 0, 0, 0},
+				Vehicles: []VRPVehicle{{Capacity: 10}},
+			},
+			routes: 1, distance: 6,
+		},
+		{
+			name: "capacity splits the stops",
+			input: VRPInput{
+				Dist:     lineDistances([]float64{0, -2, -1, 1, 2}),
+				Demand:   []float64{0, 2, 2, 2, 2},
+				Service:  []float64{0, 0, 0, 0, 0},
+				Vehicles: []VRPVehicle{{Capacity: 4}, {Capacity: 4}},
+			},
+			routes: 2, distance: 8,
+		},
+		{
+			name: "a stop too heavy for every vehicle",
+			input: VRPInput{
+				Dist:     lineDistances([]float64{0, 1, 2}),
+				Demand:   []float64{0, 1, 9},
+				Service:  []float64{0, 0, 0},
+				Vehicles: []VRPVehicle{{Capacity: 5}},
+			},
+			routes: 1, unserved: []int{2}, distance: 2,
+		},
+		{
+			name: "time limit",
+			input: VRPInput{
+				Dist:     lineDistances([]float64{0, 10, 20}),
+				Demand:   []float64{0, 1, 1},
+				Service:  []float64{0, 5, 5},
+				Speed:    1,
+				Vehicles: []VRPVehicle{{Capacity: 10, MaxMinutes: 30}},
+			},
+			routes: 1, unserved: []int{2}, distance: 20,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solution := SolveVRP(context.Background(), tt.input)
+			if !solution.Success {
+				t.Fatalf("solve failed: %s", solution.Message)
+			}
+			if math.Abs(solution.Distance-tt.distance) > 1e-9 {
+				t.Errorf("distance %g, want %g", solution.Distance, tt.distance)
+			}
+			if len(solution.Unserved) != len(tt.unserved) {
+				t.Fatalf("unserved %v, want %v", solution.Unserved, tt.unserved)
+			}
+			for i := range tt.unserved {
+				if solution.Unserved[i] != tt.unserved[i] {
+					t.Fatalf("unserved %v, want %v", solution.Unserved, tt.unserved)
+				}
+			}
+
+			served := make(map[int]bool)
+			routes := 0
+			for _, route := range solution.Routes {
+				if len(route.Stops) == 0 {
+					continue
+				}
+				routes++
+				vehicle := tt.input.Vehicles[route.Vehicle]
+				load := 0.0
+				for _, stop := range route.Stops {
+					if served[stop] {
+						t.Fatalf("stop %d is served twice", stop)
+					}
+					served[stop] = true
+					load += tt.input.Demand[stop]
+				}
+				if load > vehicle.Capacity {
+					t.Errorf("vehicle %d carries %g over its capacity %g", route.Vehicle, load, vehicle.Capacity)
+				}
+				if vehicle.MaxMinutes > 0 && route.Minutes > vehicle.MaxMinutes {
+					t.Errorf("vehicle %d drives %g minutes over its limit %g", route.Vehicle, route.Minutes, vehicle.MaxMinutes)
+				}
+			}
+			if routes != tt.routes {
+				t.Errorf("%d routes, want %d", routes, tt.routes)
+			}
+			if len(served)+len(tt.unserved) != len(tt.input.Dist)-1 {
+				t.Errorf("%d stops served and %d unserved of %d", len(served), len(tt.unserved), len(tt.input.Dist)-1)
+			}
+		})
+	}
+}
+
+func TestSolveVRPRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input VRPInput
+	}{
+		{"no stops", VRPInput{Dist: [][]float64{{0}}, Demand: []float64{0}, Service: []float64{0}, Vehicles: []VRPVehicle{{Capacity: 1}}}},
+		{"ragged distances", VRPInput{Dist: [][]float64{{0, 1}, {1}}, Demand: []float64{0, 1}, Service: []float64{0, 0}, Vehicles: []VRPVehicle{{Capacity: 1}}}},
+		{"no vehicles", VRPInput{Dist: lineDistances([]float64{0, 1}), Demand: []float64{0, 1}, Service: []float64{0, 0}}},
+		{"time limit without a speed", VRPInput{Dist: lineDistances([]float64{0, 1}), Demand: []float64{0, 1}, Service: []float64{0, 0}, Vehicles: []VRPVehicle{{Capacity: 1, MaxMinutes: 10}}}},
+	}
+	for _, tt := range tests {
+		if solution := SolveVRP(context.Background(), tt.input); solution.Success {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
@@ -0,0 +1,632 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WaitTable is a table a waiting group can be seated at
+type WaitTable struct {
+	Capacity  int
+	FreeAt    float64 // minutes from now until the table is free, 0 if it is free now
+	HeldUntil float64 // minutes from now until the table may go to groups without priority
+	Section   int     // server section from 1, 0 for none
+}
+
+// WaitGroup is a party on the waitlist
+type WaitGroup struct {
+	Size     int
+	Waited   float64 // minutes the group has already waited
+	ArriveAt float64 // minutes from now until the group arrives, 0 if it is waiting now
+	Turn     float64 // minutes the group keeps its table, 0 for the plan's default
+	Priority bool    // may be seated at held tables before the hold ends
+	Rank     int     // priority level compared by the fairness rule
+	Spend    float64 // expected revenue from the group, used by the revenue objective
+	Booked   bool    // a reservation or event: its table is set aside for it from ArriveAt
+	Grace    float64 // minutes a booked group's table stays held if it is late
+	NoShow   float64 // probability the group never comes, weighting its spend and seats
+}
+
+// WaitSeating is the table and time planned for one group
+type WaitSeating struct {
+	Group       int     // index into the groups
+	Table       int     // index into the tables, the first of a combination's
+	Combination int     // index into the combinations when the group takes one, -1 otherwise
+	SeatAt      float64 // minutes from now
+	Turn        float64 // minutes the group keeps the table
+	Penalty     float64 // minutes of penalty the seating carries, that of its combination included
+	Balance     float64 // minutes the section balance added to the seating
+	// Overbooked is set for a booked group sharing its table's time with other bookings,
+	// counting on no-shows
+	Overbooked bool
+}
+
+// WaitPool is a space without tables, such as bar seats or a standing area, that takes
+// groups once waiting for a table has gone on too long
+type WaitPool struct {
+	Capacity int     // people the pool holds at once
+	Turn     float64 // minutes a group stays, 0 for the group's table turn
+}
+
+// WaitCombination is a set of tables a group no single table can take may take together
+type WaitCombination struct {
+	Tables   []int   // indexes into the tables
+	Capacity int     // seats of the tables together
+	Penalty  float64 // minutes added to the seating time when comparing seatings, e.g. for splitting the group
+}
+
+// WaitlistInput is the floor and the waitlist to plan
+type WaitlistInput struct {
+	Tables      []WaitTable
+	Groups      []WaitGroup
+	TurnMinutes float64 // default turn of a seated group
+
+	// Unless negative, no group is seated before a waiting group of at least its rank that
+	// arrived more than FairnessMinutes earlier, so a best fit cannot keep passing over a
+	// group that has waited longer
+	FairnessMinutes float64
+
+	// A group still waiting for a table OverflowAfter minutes after it arrived takes the
+	// first of the Pools with room for its whole stay, as does a group larger than every table
+	Pools         []WaitPool
+	OverflowAfter float64
+
+	// Give a free table to the group with the highest expected spend per seat hour of the
+	// table, fewest empty seats on ties, instead of to the best fit
+	MaximizeRevenue bool
+
+	// A booked group may take a table already booked for part of its stay as long as the
+	// chance that two of the overlapping bookings show up stays within OverbookRisk
+	OverbookRisk float64
+
+	// Minutes added to a seating per cover the plan already gave the table's server section,
+	// spreading new tables over the servers
+	SectionBalance float64
+
+	// Table sets a group no single table can take may take instead. A combination's tables
+	// are taken and freed together, it is never overbooked, and its penalty adds to the
+	// highest penalty of its tables
+	Combinations []WaitCombination
+
+	// Seat the walk-ins here now at the tables free now all at once, by the assignment that
+	// seats the most of them, then at the lowest penalties and best fit, or spend rate with
+	// MaximizeRevenue. A best fit one group at a time cannot promise that. Needs
+	// FairnessMinutes negative, as the fairness rule does not apply to a matching
+	MatchNow bool
+
+	// Penalty[g][t] is added, in minutes, to the time group g would be seated at table t
+	// when choosing between seatings; +Inf rules the table out, and a group only takes a
+	// pool on arrival if every table it fits is ruled out. Nil for no penalties
+	Penalty [][]float64
+
+	// Bias[g] is added, in minutes, to every seating of walk-in group g when choosing which
+	// group a table goes to, as AnnealWaitlist does. Nil for none
+	Bias []float64
+}
+
+// WaitlistPlan is the order in which waiting groups are seated
+type WaitlistPlan struct {
+	Seatings   []WaitSeating // in seating order
+	Overflow   []WaitSeating // groups placed in pools, Table indexing into the pools
+	Unseatable []int         // groups no table can take, left without a pool spot
+	Success    bool
+	Message    string
+}
+
+// PlanWaitlist simulates the evening from now. Booked groups are placed first, in order of
+// their booked time, each at the best-fitting table free through its grace window and turn.
+// Walk-ins then fill the gaps: each table, as soon as it is free, goes to the waiting group
+// it fits with the fewest empty seats, the longest-waiting first on ties, and is held for
+// that group's turn. The fields of WaitlistInput change how that choice is made. Tables too
+// small for every remaining group are passed over
+func PlanWaitlist(input WaitlistInput) WaitlistPlan {
+	tables, groups, pools := input.Tables, input.Groups, input.Pools
+	if input.TurnMinutes <= 0 {
+		return WaitlistPlan{Success: false, Message: "Turn time must be positive"}
+	}
+	if input.OverflowAfter < 0 {
+		return WaitlistPlan{Success: false, Message: "Overflow wait cannot be negative"}
+	}
+	if input.OverbookRisk < 0 || input.OverbookRisk >= 1 {
+		return WaitlistPlan{Success: false, Message: "Overbooking risk must be at least 0 and below 1"}
+	}
+	if input.MatchNow && input.FairnessMinutes >= 0 {
+		return WaitlistPlan{Success: false, Message: "Matching the groups here now cannot keep the fairness rule"}
+	}
+	if input.Bias != nil && len(input.Bias) != len(groups) {
+		return WaitlistPlan{Success: false, Message: "Biases must be given for every group"}
+	}
+	// units[u] lists the tables seating unit u takes: table u itself, then each combination's
+	units := make([][]int, len(tables), len(tables)+len(input.Combinations))
+	for t := range tables {
+		units[t] = []int{t}
+	}
+	for c, combination := range input.Combinations {
+		if len(combination.Tables) < 2 {
+			return WaitlistPlan{Success: false, Message: fmt.Sprintf("Combination %d needs at least two tables", c)}
+		}
+		seen := make(map[int]bool, len(combination.Tables))
+		for _, t := range combination.Tables {
+			if t < 0 || t >= len(tables) || seen[t] {
+				return WaitlistPlan{Success: false, Message: fmt.Sprintf("Combination %d must name different tables", c)}
+			}
+			seen[t] = true
+		}
+		units = append(units, combination.Tables)
+	}
+	combinationOf := func(u int) int {
+		if u < len(tables) {
+			return -1
+		}
+		return u - len(tables)
+	}
+
+	turnOf := func(g int) float64 {
+		if groups[g].Turn > 0 {
+			return groups[g].Turn
+		}
+		return input.TurnMinutes
+	}
+	// What each group is worth at each table does not change as the evening goes on, so it
+	// is worked out once, in parallel for large instances, rather than at every seating
+	fit := make([][]waitFit, len(groups))
+	ParallelRows(len(groups), len(groups)*len(units), func(g int) {
+		fit[g] = make([]waitFit, len(units))
+		alone := false
+		for t, table := range tables {
+			f := waitFit{
+				allowed: table.Capacity >= groups[g].Size,
+				empty:   float64(table.Capacity) - float64(groups[g].Size)*(1-groups[g].NoShow),
+			}
+			if input.Penalty != nil {
+				f.penalty = input.Penalty[g][t]
+				f.allowed = f.allowed && !math.IsInf(f.penalty, 1)
+			}
+			if input.MaximizeRevenue {
+				f.rate = groups[g].Spend * (1 - groups[g].NoShow) / (float64(table.Capacity) * turnOf(g) / 60)
+			}
+			fit[g][t] = f
+			alone = alone || f.allowed
+		}
+		for c, combination := range input.Combinations {
+			f := waitFit{
+				allowed: !alone && combination.Capacity >= groups[g].Size,
+				empty:   float64(combination.Capacity) - float64(groups[g].Size)*(1-groups[g].NoShow),
+			}
+			highest := 0.0
+			for _, t := range combination.Tables {
+				if input.Penalty != nil {
+					highest = math.Max(highest, input.Penalty[g][t])
+				}
+			}
+			f.penalty = combination.Penalty + highest
+			f.allowed = f.allowed && !math.IsInf(f.penalty, 1)
+			if input.MaximizeRevenue {
+				f.rate = groups[g].Spend * (1 - groups[g].NoShow) / (float64(combination.Capacity) * turnOf(g) / 60)
+			}
+			fit[g][len(tables)+c] = f
+		}
+	})
+	stays := make([][]poolStay, len(pools))
+	var overflow []WaitSeating
+	// toPool places group g in the first pool with room from at for its stay
+	toPool := func(g int, at float64) bool {
+		for p, pool := range pools {
+			turn := pool.Turn
+			if turn <= 0 {
+				turn = turnOf(g)
+			}
+			if poolHasRoom(stays[p], pool.Capacity, at, at+turn, groups[g].Size) {
+				stays[p] = append(stays[p], poolStay{start: at, end: at + turn, size: groups[g].Size})
+				overflow = append(overflow, WaitSeating{Group: g, Table: p, Combination: -1, SeatAt: at, Turn: turn})
+				return true
+			}
+		}
+		return false
+	}
+
+	waiting := make([]int, 0, len(groups))
+	var unseatable []int
+	for i, group := range groups {
+		fits := false
+		for u := range units {
+			fits = fits || fit[i][u].allowed
+		}
+		if !fits {
+			if !toPool(i, group.ArriveAt) {
+				unseatable = append(unseatable, i)
+			}
+			continue
+		}
+		waiting = append(waiting, i)
+	}
+
+	freeAt := make([]float64, len(tables))
+	for i, table := range tables {
+		freeAt[i] = table.FreeAt
+	}
+	sectionCovers := make(map[int]int)
+	// balance returns the minutes the covers already seated in table t's section add
+	balance := func(t int) float64 {
+		if tables[t].Section == 0 {
+			return 0
+		}
+		return input.SectionBalance * float64(sectionCovers[tables[t].Section])
+	}
+
+	seatings := make([]WaitSeating, 0, len(waiting))
+	var bookedGroups []int
+	walkIns := make([]int, 0, len(waiting))
+	for _, g := range waiting {
+		if groups[g].Booked {
+			bookedGroups = append(bookedGroups, g)
+		} else {
+			walkIns = append(walkIns, g)
+		}
+	}
+	sort.SliceStable(bookedGroups, func(a, b int) bool {
+		return groups[bookedGroups[a]].ArriveAt < groups[bookedGroups[b]].ArriveAt
+	})
+	bookings := make([][]booking, len(tables))
+	// ready returns when group g could first sit down at unit u's tables, bookings aside
+	ready := func(g, u int) float64 {
+		start := groups[g].ArriveAt
+		for _, t := range units[u] {
+			start = math.Max(start, freeAt[t])
+			if !groups[g].Priority {
+				start = math.Max(start, tables[t].HeldUntil)
+			}
+		}
+		return start
+	}
+	// gap returns the earliest time from from when every table of unit u is free of bookings
+	// for length minutes
+	gap := func(u int, from, length float64) float64 {
+		if len(units[u]) == 1 {
+			return nextGap(bookings[u], from, length)
+		}
+		for {
+			next := from
+			for _, t := range units[u] {
+				next = nextGap(bookings[t], next, length)
+			}
+			if next == from {
+				return from
+			}
+			from = next
+		}
+	}
+
+	for _, g := range bookedGroups {
+		hold := groups[g].Grace + turnOf(g)
+		table := -1
+		var bestStart, bestScore, bestEmpty float64
+		var bestOverbooked bool
+		for t := range units {
+			if !fit[g][t].allowed {
+				continue
+			}
+			empty := fit[g][t].empty
+			start := ready(g, t)
+			overbooked := false
+			if next := gap(t, start, hold); next > start && input.OverbookRisk > 0 && t < len(tables) &&
+				clashChance(bookings[t], start, start+hold, groups[g].NoShow) <= input.OverbookRisk {
+				overbooked = true
+			} else {
+				start = next
+			}
+			// Between equal starts, a table of its own beats an overbooked one
+			score := start + fit[g][t].penalty + balance(units[t][0])
+			better := table == -1 || score < bestScore
+			if !better && score == bestScore {
+				if overbooked != bestOverbooked {
+					better = !overbooked
+				} else {
+					better = empty < bestEmpty
+				}
+			}
+			if better {
+				table, bestStart, bestScore, bestEmpty, bestOverbooked = t, start, score, empty, overbooked
+			}
+		}
+
+		if bestOverbooked {
+			for _, b := range bookings[table] {
+				if b.start < bestStart+hold && bestStart < b.end {
+					seatings[b.seating].Overbooked = true
+				}
+			}
+		}
+		for _, t := range units[table] {
+			bookings[t] = append(bookings[t], booking{start: bestStart, end: bestStart + hold, noShow: groups[g].NoShow, seating: len(seatings)})
+			sort.Slice(bookings[t], func(a, b int) bool { return bookings[t][a].start < bookings[t][b].start })
+		}
+		seatings = append(seatings, WaitSeating{
+			Group:       g,
+			Table:       units[table][0],
+			Combination: combinationOf(table),
+			SeatAt:      bestStart,
+			Turn:        turnOf(g),
+			Penalty:     fit[g][table].penalty,
+			Balance:     balance(units[table][0]),
+			Overbooked:  bestOverbooked,
+		})
+		sectionCovers[tables[units[table][0]].Section] += groups[g].Size
+	}
+	waiting = walkIns
+
+	// starts[g][u] is when walk-in g could sit down at unit u. It only changes when a
+	// seating frees one of u's tables later, so it is kept rather than worked out again for
+	// every seating; unitsOf[t] lists the units that take table t
+	unitsOf := make([][]int, len(tables))
+	for u, members := range units {
+		for _, t := range members {
+			unitsOf[t] = append(unitsOf[t], u)
+		}
+	}
+	starts := make([][]float64, len(groups))
+	for _, g := range waiting {
+		starts[g] = make([]float64, len(units))
+		for u := range units {
+			if fit[g][u].allowed {
+				starts[g][u] = gap(u, ready(g, u), turnOf(g))
+			}
+		}
+	}
+	// refresh works out the starts again at the units sharing a table with members
+	refresh := func(members []int) {
+		for _, t := range members {
+			for _, u := range unitsOf[t] {
+				for _, g := range waiting {
+					if fit[g][u].allowed {
+						starts[g][u] = gap(u, ready(g, u), turnOf(g))
+					}
+				}
+			}
+		}
+	}
+
+	if input.MatchNow {
+		var matched []int
+		waiting = matchNow(input, fit, starts, waiting, func(g, t int, balance float64) {
+			seatings = append(seatings, WaitSeating{Group: g, Table: t, Combination: -1, SeatAt: 0, Turn: turnOf(g), Penalty: fit[g][t].penalty, Balance: balance})
+			freeAt[t] = turnOf(g)
+			sectionCovers[tables[t].Section] += groups[g].Size
+			matched = append(matched, t)
+		}, balance)
+		refresh(matched)
+	}
+
+	noPoolRoom := make([]bool, len(groups))
+	balances := make([]float64, len(units))
+	for len(waiting) > 0 {
+		// The earliest seating any table and waiting group allow, then the best fit
+		table, group := -1, -1
+		var bestStart, bestScore, bestWait, bestRate, bestEmpty float64
+		passedOver := make([]bool, len(waiting))
+		for position, g := range waiting {
+			passedOver[position] = input.FairnessMinutes >= 0 && passesOver(groups, waiting, g, input.FairnessMinutes)
+		}
+		for u := range units {
+			balances[u] = balance(units[u][0])
+		}
+		// Each group's row is scanned in turn, which keeps to memory order; full ties go to the
+		// first table, then the first group in the waitlist
+		for position, g := range waiting {
+			if passedOver[position] {
+				continue
+			}
+			bias := 0.0
+			if input.Bias != nil {
+				bias = input.Bias[g]
+			}
+			for t, f := range fit[g] {
+				if !f.allowed {
+					continue
+				}
+				start := starts[g][t]
+				wait := groups[g].Waited + start - groups[g].ArriveAt
+				score := start + f.penalty + balances[t] + bias
+				if table == -1 || score < bestScore || (score == bestScore && (f.rate > bestRate || (f.rate == bestRate &&
+					(f.empty < bestEmpty || (f.empty == bestEmpty && (wait > bestWait || (wait == bestWait && t < table))))))) {
+					table, group = t, position
+					bestStart, bestScore, bestWait, bestEmpty, bestRate = start, score, wait, f.empty, f.rate
+				}
+			}
+		}
+
+		// A group whose overflow wait runs out before that seating goes to a pool instead
+		if len(pools) > 0 {
+			next, nextAt := -1, 0.0
+			for position, g := range waiting {
+				at := math.Max(groups[g].ArriveAt, groups[g].ArriveAt-groups[g].Waited+input.OverflowAfter)
+				if !noPoolRoom[g] && at < bestStart && (next == -1 || at < nextAt) {
+					next, nextAt = position, at
+				}
+			}
+			if next >= 0 {
+				if g := waiting[next]; toPool(g, nextAt) {
+					waiting = append(waiting[:next], waiting[next+1:]...)
+				} else {
+					noPoolRoom[g] = true
+				}
+				continue
+			}
+		}
+
+		turn := turnOf(waiting[group])
+		seatings = append(seatings, WaitSeating{
+			Group:       waiting[group],
+			Table:       units[table][0],
+			Combination: combinationOf(table),
+			SeatAt:      bestStart,
+			Turn:        turn,
+			Penalty:     fit[waiting[group]][table].penalty,
+			Balance:     balances[table],
+		})
+		for _, t := range units[table] {
+			freeAt[t] = bestStart + turn
+		}
+		sectionCovers[tables[units[table][0]].Section] += groups[waiting[group]].Size
+		waiting = append(waiting[:group], waiting[group+1:]...)
+		refresh(units[table])
+	}
+
+	sort.SliceStable(seatings, func(a, b int) bool { return seatings[a].SeatAt < seatings[b].SeatAt })
+
+	message := fmt.Sprintf("%d groups seated", len(seatings))
+	if len(overflow) > 0 {
+		message += fmt.Sprintf(", %d in overflow areas", len(overflow))
+	}
+	if len(unseatable) > 0 {
+		message += fmt.Sprintf(", %d with no table to take them", len(unseatable))
+	}
+	return WaitlistPlan{Seatings: seatings, Overflow: overflow, Unseatable: unseatable, Success: true, Message: message}
+}
+
+// matchNow seats the walk-ins here now at the single tables they can take now, as many as
+// possible and then at the lowest cost, calling seat for each pair with the section balance
+// its cost counted, and returns the groups
+// still waiting. Costs put the penalty and section balance first, the number of groups
+// keeping any difference in fit or spend rate from outweighing a minute of them
+func matchNow(input WaitlistInput, fit [][]waitFit, starts [][]float64, waiting []int, seat func(g, t int, balance float64), balance func(t int) float64) []int {
+	var present []int
+	for _, g := range waiting {
+		if input.Groups[g].ArriveAt == 0 {
+			present = append(present, g)
+		}
+	}
+	if len(present) == 0 {
+		return waiting
+	}
+
+	largest, highestRate := 0.0, 0.0
+	for _, table := range input.Tables {
+		largest = math.Max(largest, float64(table.Capacity))
+	}
+	for _, g := range present {
+		for t := range input.Tables {
+			highestRate = math.Max(highestRate, fit[g][t].rate)
+		}
+	}
+	balances := make([]float64, len(input.Tables))
+	for t := range input.Tables {
+		balances[t] = balance(t)
+	}
+	cost := make([][]float64, len(present))
+	for i, g := range present {
+		cost[i] = make([]float64, len(input.Tables))
+		for t := range input.Tables {
+			f := fit[g][t]
+			if !f.allowed || starts[g][t] > 0 {
+				cost[i][t] = math.Inf(1)
+				continue
+			}
+			// Both lie in [0, 1)
+			tie := f.empty / (largest + 1)
+			if input.MaximizeRevenue {
+				tie = (highestRate - f.rate) / (highestRate + 1)
+			}
+			cost[i][t] = (f.penalty+balances[t])*float64(len(present)+1) + tie
+		}
+	}
+
+	seated := make(map[int]bool, len(present))
+	for i, t := range Hungarian(cost) {
+		if t >= 0 {
+			seat(present[i], t, balances[t])
+			seated[present[i]] = true
+		}
+	}
+	left := make([]int, 0, len(waiting)-len(seated))
+	for _, g := range waiting {
+		if !seated[g] {
+			left = append(left, g)
+		}
+	}
+	return left
+}
+
+// waitFit is what seating one group at one table is worth, the same all evening
+type waitFit struct {
+	allowed bool    // the group fits the table and no penalty rules it out
+	empty   float64 // seats left empty on average, counting the chance of a no-show
+	rate    float64 // expected spend per seat hour, with MaximizeRevenue
+	penalty float64 // minutes added to the seating time when comparing seatings
+}
+
+// booking is the time a table is set aside for a booked group
+type booking struct {
+	start, end float64
+	noShow     float64
+	seating    int // index of the booked group's seating
+}
+
+// clashChance returns the chance that at least two groups show up for a table from start
+// to end, counting the bookings overlapping that time and one more group with the given
+// no-show probability, each showing up independently
+func clashChance(bookings []booking, start, end, noShow float64) float64 {
+	none, one := noShow, 1-noShow // chance that no group, or exactly one, shows up
+	for _, b := range bookings {
+		if b.start < end && start < b.end {
+			none, one = none*b.noShow, one*b.noShow+none*(1-b.noShow)
+		}
+	}
+	return math.Max(0, 1-none-one)
+}
+
+// nextGap returns the earliest time from from when a table with the given bookings, sorted
+// by start, is free for length minutes
+func nextGap(bookings []booking, from, length float64) float64 {
+	for _, b := range bookings {
+		if from+length <= b.start {
+			break
+		}
+		if from < b.end {
+			from = b.end
+		}
+	}
+	return from
+}
+
+// poolStay is the time a group spends in a pool
+type poolStay struct {
+	start, end float64
+	size       int
+}
+
+// poolHasRoom reports whether size more people fit in a pool from start to end
+func poolHasRoom(stays []poolStay, capacity int, start, end float64, size int) bool {
+	// Occupancy only rises when a stay begins, so checking those instants is enough
+	instants := []float64{start}
+	for _, stay := range stays {
+		if stay.start > start && stay.start < end {
+			instants = append(instants, stay.start)
+		}
+	}
+	for _, at := range instants {
+		used := size
+		for _, stay := range stays {
+			if stay.start <= at && at < stay.end {
+				used += stay.size
+			}
+		}
+		if used > capacity {
+			return false
+		}
+	}
+	return true
+}
+
+// passesOver reports whether seating group g now would pass over a waiting group of at
+// least its rank that arrived more than fairnessMinutes before it
+func passesOver(groups []WaitGroup, waiting []int, g int, fairnessMinutes float64) bool {
+	arrived := groups[g].ArriveAt - groups[g].Waited
+	for _, h := range waiting {
+		if h != g && groups[h].Rank >= groups[g].Rank && groups[h].ArriveAt-groups[h].Waited < arrived-fairnessMinutes {
+			return true
+		}
+	}
+	return false
+}
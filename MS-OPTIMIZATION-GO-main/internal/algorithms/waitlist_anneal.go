@@ -0,0 +1,98 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// annealSeatWeight is the minutes of wait and penalty one more group seated at a table is
+// worth when annealing, more than any plan within a day can add up to per group
+const annealSeatWeight = 1e6
+
+// WaitlistAnnealing is a waitlist plan improved by simulated annealing, with the greedy plan
+// it started from for comparison
+type WaitlistAnnealing struct {
+	Plan         WaitlistPlan
+	GreedySeated int     // groups the greedy plan seats at tables
+	GreedyCost   float64 // total wait and penalty of the greedy plan, in minutes
+	Seated       int     // groups the returned plan seats at tables
+	Cost         float64 // total wait and penalty of the returned plan, in minutes
+	Steps        int
+}
+
+// AnnealWaitlist improves on PlanWaitlist for large floors and bursts of arrivals. It starts
+// from the greedy plan and searches over the biases of the walk-ins: each step shifts one
+// group's bias by a random number of minutes, up to half a turn either way, and plans again.
+// A plan seating more groups at tables is better, then one with less total wait and penalty;
+// a worse plan is kept as the next starting point with a chance that shrinks with every step,
+// which lets the search leave local optima. The best plan found is returned after steps
+// steps, or earlier once the time budget, if positive, runs out. Every plan follows the greedy
+// planner's rules, fairness included. The cooling depends on the step count alone, never on
+// the clock, so the same seed and steps give the same plan unless the budget stops the run
+func AnnealWaitlist(input WaitlistInput, budget time.Duration, steps int, seed int64) WaitlistAnnealing {
+	input.Bias = nil
+	plan := PlanWaitlist(input)
+	if !plan.Success {
+		return WaitlistAnnealing{Plan: plan}
+	}
+	greedyCost := waitlistCost(input, plan)
+	result := WaitlistAnnealing{
+		Plan:         plan,
+		GreedySeated: len(plan.Seatings),
+		GreedyCost:   greedyCost,
+		Seated:       len(plan.Seatings),
+		Cost:         greedyCost,
+	}
+	if len(input.Groups) < 2 || steps <= 0 {
+		return result
+	}
+
+	energy := func(seated int, cost float64) float64 {
+		return cost - float64(seated)*annealSeatWeight
+	}
+	// The starting temperature accepts a plan an average wait worse about a third of the time
+	temperature := math.Max(1, greedyCost/float64(len(input.Groups)))
+	scale := input.TurnMinutes / 2
+
+	random := rand.New(rand.NewSource(seed))
+	bias := make([]float64, len(input.Groups))
+	current := energy(result.Seated, result.Cost)
+	deadline := time.Now().Add(budget)
+	for result.Steps < steps {
+		if budget > 0 && !time.Now().Before(deadline) {
+			break
+		}
+		result.Steps++
+		progress := float64(result.Steps) / float64(steps)
+
+		g := random.Intn(len(input.Groups))
+		shift := (2*random.Float64() - 1) * scale
+		bias[g] += shift
+		input.Bias = bias
+		candidate := PlanWaitlist(input)
+		cost := waitlistCost(input, candidate)
+		next := energy(len(candidate.Seatings), cost)
+
+		t := temperature * (1 - progress)
+		if next <= current || (t > 0 && random.Float64() < math.Exp((current-next)/t)) {
+			current = next
+			if len(candidate.Seatings) > result.Seated || (len(candidate.Seatings) == result.Seated && cost < result.Cost) {
+				result.Plan, result.Seated, result.Cost = candidate, len(candidate.Seatings), cost
+			}
+		} else {
+			bias[g] -= shift
+		}
+	}
+	return result
+}
+
+// waitlistCost returns the minutes the groups seated at tables wait from their arrival plus
+// the penalties of their seatings
+func waitlistCost(input WaitlistInput, plan WaitlistPlan) float64 {
+	cost := 0.0
+	for _, seating := range plan.Seatings {
+		cost += seating.SeatAt - input.Groups[seating.Group].ArriveAt + seating.Penalty
+	}
+	return cost
+}
@@ -0,0 +1,79 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// twoForOneTable is a floor where seating the longest-waiting group at its best fit leaves
+// the other group no table until the first leaves
+func twoForOneTable(matchNow bool) WaitlistInput {
+	return WaitlistInput{
+		Tables:          []WaitTable{{Capacity: 4}, {Capacity: 4}},
+		Groups:          []WaitGroup{{Size: 4, Waited: 20}, {Size: 4, Waited: 10}},
+		TurnMinutes:     90,
+		FairnessMinutes: -1,
+		MatchNow:        matchNow,
+		Penalty:         [][]float64{{0, 10}, {0, math.Inf(1)}},
+	}
+}
+
+// busyFloor is a floor with a burst of arrivals, the same for the same seed
+func busyFloor(seed int64) WaitlistInput {
+	rng := rand.New(rand.NewSource(seed))
+	input := WaitlistInput{TurnMinutes: 60, FairnessMinutes: -1}
+	for t := 0; t < 8; t++ {
+		input.Tables = append(input.Tables, WaitTable{Capacity: 2 + 2*rng.Intn(3), FreeAt: float64(rng.Intn(30))})
+	}
+	for g := 0; g < 30; g++ {
+		input.Groups = append(input.Groups, WaitGroup{Size: 1 + rng.Intn(6), ArriveAt: float64(rng.Intn(90))})
+	}
+	return input
+}
+
+func TestAnnealWaitlist(t *testing.T) {
+	result := AnnealWaitlist(twoForOneTable(false), 0, 2000, 1)
+	if !result.Plan.Success {
+		t.Fatalf("anneal failed: %s", result.Plan.Message)
+	}
+	if result.GreedySeated != 2 || result.GreedyCost != 90 {
+		t.Errorf("greedy plan seats %d at a cost of %g, want 2 at 90", result.GreedySeated, result.GreedyCost)
+	}
+	if result.Seated != 2 || result.Cost != 10 {
+		t.Errorf("annealed plan seats %d at a cost of %g, want 2 at 10", result.Seated, result.Cost)
+	}
+	if result.Steps != 2000 {
+		t.Errorf("%d steps, want 2000", result.Steps)
+	}
+}
+
+func TestAnnealWaitlistIsReproducible(t *testing.T) {
+	tests := []struct {
+		name  string
+		input WaitlistInput
+		steps int
+		seed  int64
+	}{
+		{"two for one table", twoForOneTable(false), 500, 7},
+		{"busy floor", busyFloor(1), 1000, 1},
+		{"busy floor, other seed", busyFloor(1), 1000, 42},
+		{"busy floor with fairness", func() WaitlistInput { in := busyFloor(2); in.FairnessMinutes = 15; return in }(), 1000, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := AnnealWaitlist(tt.input, 0, tt.steps, tt.seed)
+			second := AnnealWaitlist(tt.input, 0, tt.steps, tt.seed)
+			if !first.Plan.Success {
+				t.Fatalf("anneal failed: %s", first.Plan.Message)
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("the same seed gave different plans:\n%+v\n%+v", first, second)
+			}
+			if first.Seated < first.GreedySeated || (first.Seated == first.GreedySeated && first.Cost > first.GreedyCost) {
+				t.Errorf("annealed plan (%d seated, cost %g) is worse than greedy (%d, %g)", first.Seated, first.Cost, first.GreedySeated, first.GreedyCost)
+			}
+		})
+	}
+}
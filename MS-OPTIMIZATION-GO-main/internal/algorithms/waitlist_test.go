@@ -0,0 +1,157 @@
+package algorithms
+
+import "testing"
+
+// seatingOf returns the seating of group g in the plan, false if it has none
+func seatingOf(plan WaitlistPlan, g int) (WaitSeating, bool) {
+	for _, seating := range plan.Seatings {
+		if seating.Group == g {
+			return seating, true
+		}
+	}
+	return WaitSeating{}, false
+}
+
+func TestPlanWaitlist(t *testing.T) {
+	type seat struct {
+		table       int
+		combination int
+		at          float64
+	}
+	tests := []struct {
+		name  string
+		input WaitlistInput
+		want  []seat // by group, table -1 for a group without a seating
+	}{
+		{
+			name: "best fit",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 2}, {Capacity: 4}, {Capacity: 6}},
+				Groups:          []WaitGroup{{Size: 5}, {Size: 3}},
+				TurnMinutes:     60,
+				FairnessMinutes: -1,
+			},
+			want: []seat{{2, -1, 0}, {1, -1, 0}},
+		},
+		{
+			name: "waits for the table to free up",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 4, FreeAt: 15}},
+				Groups:          []WaitGroup{{Size: 2}, {Size: 4}},
+				TurnMinutes:     60,
+				FairnessMinutes: -1,
+			},
+			want: []seat{{0, -1, 75}, {0, -1, 15}},
+		},
+		{
+			name: "fairness passes over the best fit",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 4}},
+				Groups:          []WaitGroup{{Size: 2, Waited: 30}, {Size: 4}},
+				TurnMinutes:     60,
+				FairnessMinutes: 10,
+			},
+			want: []seat{{0, -1, 0}, {0, -1, 60}},
+		},
+		{
+			name: "held table",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 4, HeldUntil: 30}},
+				Groups:          []WaitGroup{{Size: 4, Waited: 10}, {Size: 4, Priority: true}},
+				TurnMinutes:     60,
+				FairnessMinutes: -1,
+			},
+			want: []seat{{0, -1, 60}, {0, -1, 0}},
+		},
+		{
+			name: "combination",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 2}, {Capacity: 2}},
+				Groups:          []WaitGroup{{Size: 4}},
+				TurnMinutes:     60,
+				FairnessMinutes: -1,
+				Combinations:    []WaitCombination{{Tables: []int{0, 1}, Capacity: 4, Penalty: 5}},
+			},
+			want: []seat{{0, 0, 0}},
+		},
+		{
+			name: "too large for every table",
+			input: WaitlistInput{
+				Tables:          []WaitTable{{Capacity: 4}},
+				Groups:          []WaitGroup{{Size: 6}},
+				TurnMinutes:     60,
+				FairnessMinutes: -1,
+			},
+			want: []seat{{-1, -1, 0}},
+		},
+		{"greedy leaves a group waiting", twoForOneTable(false), []seat{{0, -1, 0}, {0, -1, 90}}},
+		{"matching seats both now", twoForOneTable(true), []seat{{1, -1, 0}, {0, -1, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanWaitlist(tt.input)
+			if !plan.Success {
+				t.Fatalf("plan failed: %s", plan.Message)
+			}
+			for g, want := range tt.want {
+				seating, ok := seatingOf(plan, g)
+				if want.table == -1 {
+					if ok {
+						t.Errorf("group %d seated at table %d, want none", g, seating.Table)
+					}
+					continue
+				}
+				if !ok {
+					t.Fatalf("group %d not seated", g)
+				}
+				if seating.Table != want.table || seating.Combination != want.combination || seating.SeatAt != want.at {
+					t.Errorf("group %d at table %d (combination %d) after %g minutes, want table %d (combination %d) after %g",
+						g, seating.Table, seating.Combination, seating.SeatAt, want.table, want.combination, want.at)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanWaitlistOverflow(t *testing.T) {
+	input := WaitlistInput{
+		Tables:          []WaitTable{{Capacity: 4}},
+		Groups:          []WaitGroup{{Size: 6}, {Size: 8}},
+		Pools:           []WaitPool{{Capacity: 6}},
+		TurnMinutes:     60,
+		FairnessMinutes: -1,
+	}
+	plan := PlanWaitlist(input)
+	if !plan.Success {
+		t.Fatalf("plan failed: %s", plan.Message)
+	}
+	if len(plan.Overflow) != 1 || plan.Overflow[0].Group != 0 {
+		t.Errorf("overflow %+v, want group 0 only", plan.Overflow)
+	}
+	if len(plan.Unseatable) != 1 || plan.Unseatable[0] != 1 {
+		t.Errorf("unseatable %v, want [1]", plan.Unseatable)
+	}
+}
+
+func TestPlanWaitlistRejectsInvalidInput(t *testing.T) {
+	valid := func() WaitlistInput {
+		return WaitlistInput{Tables: []WaitTable{{Capacity: 4}}, Groups: []WaitGroup{{Size: 2}}, TurnMinutes: 60, FairnessMinutes: -1}
+	}
+	tests := []struct {
+		name   string
+		change func(*WaitlistInput)
+	}{
+		{"no turn time", func(in *WaitlistInput) { in.TurnMinutes = 0 }},
+		{"negative overflow wait", func(in *WaitlistInput) { in.OverflowAfter = -1 }},
+		{"overbooking risk of 1", func(in *WaitlistInput) { in.OverbookRisk = 1 }},
+		{"matching with fairness", func(in *WaitlistInput) { in.MatchNow, in.FairnessMinutes = true, 10 }},
+		{"bias for some groups", func(in *WaitlistInput) { in.Bias = []float64{} }},
+	}
+	for _, tt := range tests {
+		input := valid()
+		tt.change(&input)
+		if plan := PlanWaitlist(input); plan.Success {
+			t.Errorf("%s: accepted", tt.name)
+		}
+	}
+}
@@ -13,9 +13,9 @@ type OptimizationHandler struct {
 }
 
 // NewOptimizationHandler creates a new optimization handler
-func NewOptimizationHandler() *OptimizationHandler {
+func NewOptimizationHandler(optimizationService *service.OptimizationService) *OptimizationHandler {
 	return &OptimizationHandler{
-		optimizationService: service.NewOptimizationService(),
+		optimizationService: optimizationService,
 	}
 }
 
@@ -26,6 +26,19 @@ func (h *OptimizationHandler) HealthCheck(c *gin.Context) {
 		"status":  "healthy",
 		"algorithms": []string{
 			"money_change",
+			"tip_split",
+			"inventory_planning",
+			"waitlist",
+			"event_seating",
+			"evening_plan",
+			"staff_assignment",
+			"route_tsp",
+			"route_vrp",
+			"linear_programming",
+			"order_batching",
+			"cocktail_batching",
+			"happy_hour",
+			"dynamic_pricing",
 			"sorting",
 			"search",
 		},
@@ -46,16 +59,1020 @@ func (h *OptimizationHandler) CalculateChange(c *gin.Context) {
 	}
 
 	// Validate request
-	if req.AmountPaid < 0 || req.TotalCost < 0 {
+	if req.AmountPaid < 0 || req.TotalCost < 0 || req.Alternatives < 0 ||
+		(req.AmountPaidCents != nil && *req.AmountPaidCents < 0) ||
+		(req.TotalCostCents != nil && *req.TotalCostCents < 0) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Amount paid and total cost must be non-negative",
+			"error":   "Amount paid, total cost and alternatives must be non-negative",
+		})
+		return
+	}
+
+	// Validate change mode
+	validModes := map[string]bool{
+		"":       true,
+		"auto":   true,
+		"greedy": true,
+		"dp":     true,
+	}
+
+	if !validModes[req.Mode] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":       false,
+			"error":         "Invalid change mode",
+			"valid_options": []string{"auto", "greedy", "dp"},
+		})
+		return
+	}
+
+	// Validate currency code (ISO 4217 style, e.g. USD, COP)
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	validPreferences := map[string]bool{
+		"":             true,
+		"balanced":     true,
+		"prefer_bills": true,
+		"prefer_coins": true,
+	}
+
+	if !validPreferences[req.Preference] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":       false,
+			"error":         "Invalid preference",
+			"valid_options": []string{"balanced", "prefer_bills", "prefer_coins"},
+		})
+		return
+	}
+
+	if req.Rounding != nil {
+		validRoundingModes := map[string]bool{
+			"up":      true,
+			"down":    true,
+			"nearest": true,
+		}
+
+		if !validRoundingModes[req.Rounding.Mode] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":       false,
+				"error":         "Invalid rounding mode",
+				"valid_options": []string{"up", "down", "nearest"},
+			})
+			return
+		}
+	}
+
+	validStrategies := map[string]bool{
+		"":               true,
+		"min_coins":      true,
+		"balance_drawer": true,
+	}
+
+	if !validStrategies[req.Strategy] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":       false,
+			"error":         "Invalid strategy",
+			"valid_options": []string{"min_coins", "balance_drawer"},
+		})
+		return
+	}
+
+	if req.DrawerID != "" && req.Available != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Use either drawer_id or available, not both",
 		})
 		return
 	}
 
 	result := h.optimizationService.CalculateOptimalChange(req)
 
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case result.InsufficientChange:
+		// The request is valid but the drawer cannot cover it
+		status = http.StatusConflict
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// CalculateMultiDrawerChange handles change requests that can draw on several drawers
+func (h *OptimizationHandler) CalculateMultiDrawerChange(c *gin.Context) {
+	var req service.MultiDrawerChangeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.CalculateMultiDrawerChange(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if result.InsufficientChange {
+		status = http.StatusConflict
+	} else if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// GetChangeHistory lists logged change calculations, e.g.
+// ?terminal_id=pos-1&from=2024-05-01T00:00:00Z&success=false&page=2&page_size=50
+func (h *OptimizationHandler) GetChangeHistory(c *gin.Context) {
+	var req service.ChangeHistoryRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.GetChangeHistory(req)
+
+	status := http.StatusOK
+	if result.StoreFailed {
+		status = http.StatusInternalServerError
+	} else if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SuggestPayment handles requests to suggest which wallet pieces a customer should pay with
+func (h *OptimizationHandler) SuggestPayment(c *gin.Context) {
+	var req service.SuggestPaymentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.SuggestPayment(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// CountChangeWays handles requests to count the ways of making an amount
+func (h *OptimizationHandler) CountChangeWays(c *gin.Context) {
+	var req service.ChangeWaysRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.CountChangeWays(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SplitBill handles requests to split an order among the guests at a table
+func (h *OptimizationHandler) SplitBill(c *gin.Context) {
+	var req service.SplitBillRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.SplitBill(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SplitTips handles tip pool distribution requests
+func (h *OptimizationHandler) SplitTips(c *gin.Context) {
+	var req service.SplitTipsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Staff) == 0 || (req.Pool <= 0 && req.PoolCents == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "A positive pool and at least one staff member are required",
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.SplitTips(req)
+
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// CalculateOrderPolicies handles economic order quantity and reorder point requests
+func (h *OptimizationHandler) CalculateOrderPolicies(c *gin.Context) {
+	var req service.OrderPolicyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Products) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one product is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.CalculateOrderPolicies(req)
+
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// CalculateSafetyStock handles safety stock and par level requests
+func (h *OptimizationHandler) CalculateSafetyStock(c *gin.Context) {
+	var req service.SafetyStockRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Products) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one product is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.CalculateSafetyStock(req)
+
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// ClassifyInventory handles ABC inventory classification requests
+func (h *OptimizationHandler) ClassifyInventory(c *gin.Context) {
+	var req service.ABCRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one item is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.ClassifyInventory(req)
+
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SimulateSeatingScenarios handles what-if seating simulations for a floor
+func (h *OptimizationHandler) SimulateSeatingScenarios(c *gin.Context) {
+	var req service.SeatingScenariosRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Scenarios) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one scenario is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.SimulateSeatingScenarios(req)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// MatchTablesStable handles stable matching of waiting groups to free tables
+func (h *OptimizationHandler) MatchTablesStable(c *gin.Context) {
+	var req service.StableMatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Groups) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one waiting group is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.MatchTablesStable(req)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// PlanEventSeating handles seating chart requests for private events
+func (h *OptimizationHandler) PlanEventSeating(c *gin.Context) {
+	var req service.EventSeatingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Guests) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one guest is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanEventSeating(req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// PlanEvening handles full-evening seating plan requests
+func (h *OptimizationHandler) PlanEvening(c *gin.Context) {
+	var req service.EveningPlanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanEvening(c.Request.Context(), req)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// AssignStaff handles bartender and waiter to station assignment requests
+func (h *OptimizationHandler) AssignStaff(c *gin.Context) {
+	var req service.StaffAssignmentRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.AssignStaff(req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// OptimizeRoute handles delivery route optimization requests
+func (h *OptimizationHandler) OptimizeRoute(c *gin.Context) {
+	var req service.RouteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.OptimizeRoute(req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// OptimizeVehicleRoutes handles multi-vehicle delivery routing requests
+func (h *OptimizationHandler) OptimizeVehicleRoutes(c *gin.Context) {
+	var req service.VehicleRoutingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.OptimizeVehicleRoutes(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// PlanOrderBatches handles bar order batching requests
+func (h *OptimizationHandler) PlanOrderBatches(c *gin.Context) {
+	var req service.OrderBatchingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanOrderBatches(req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// PlanCocktailBatches handles cocktail pre-batching requests
+func (h *OptimizationHandler) PlanCocktailBatches(c *gin.Context) {
+	var req service.CocktailBatchRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanCocktailBatches(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// PlanHappyHour handles happy-hour window and discount requests
+func (h *OptimizationHandler) PlanHappyHour(c *gin.Context) {
+	var req service.HappyHourRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanHappyHour(req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// RecommendPrices handles dynamic pricing requests
+func (h *OptimizationHandler) RecommendPrices(c *gin.Context) {
+	var req service.PricingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.RecommendPrices(req)
+
+	status := http.StatusOK
+	switch {
+	case result.Invalid:
+		status = http.StatusUnprocessableEntity
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SolveLinearProgram handles general linear programming requests
+func (h *OptimizationHandler) SolveLinearProgram(c *gin.Context) {
+	var req service.LPRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.SolveLinearProgram(c.Request.Context(), req)
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// GetFloorPlan returns a version of a stored floor plan
+func (h *OptimizationHandler) GetFloorPlan(c *gin.Context) {
+	var query service.FloorPlanQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.GetFloorPlan(c.Param("id"), query)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// SaveFloorPlan stores a new version of a floor plan
+func (h *OptimizationHandler) SaveFloorPlan(c *gin.Context) {
+	var req service.SaveFloorPlanRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Tables) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one table is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.SaveFloorPlan(c.Param("id"), req)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// ListFloorPlanVersions returns the version history of a floor plan
+func (h *OptimizationHandler) ListFloorPlanVersions(c *gin.Context) {
+	result := h.optimizationService.ListFloorPlanVersions(c.Param("id"))
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// GetFloorOccupancy returns which tables of a floor plan are occupied
+func (h *OptimizationHandler) GetFloorOccupancy(c *gin.Context) {
+	result := h.optimizationService.GetFloorOccupancy(c.Param("id"))
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// SeatTable marks a floor plan table occupied
+func (h *OptimizationHandler) SeatTable(c *gin.Context) {
+	var req service.SeatTableRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.SeatTable(c.Param("id"), c.Param("table"), req)
+	c.JSON(occupancyStatus(result), result)
+}
+
+// ReleaseTable marks a floor plan table free
+func (h *OptimizationHandler) ReleaseTable(c *gin.Context) {
+	var req service.ReleaseTableRequest
+
+	// The body is optional: without a version the release is unconditional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid request format",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	result := h.optimizationService.ReleaseTable(c.Param("id"), c.Param("table"), req)
+	c.JSON(occupancyStatus(result), result)
+}
+
+// HandleSeatingEvent receives cancellation and no-show events for a floor plan
+func (h *OptimizationHandler) HandleSeatingEvent(c *gin.Context) {
+	var req service.SeatingEventRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.HandleSeatingEvent(c.Param("id"), req)
+
+	status := http.StatusOK
+	switch {
+	case result.NotFound:
+		status = http.StatusNotFound
+	case result.Conflict:
+		status = http.StatusConflict
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// GetTableAnalytics returns table utilization over a period, e.g.
+// ?floor_plan_id=main&from=2024-05-01T00:00:00Z&to=2024-05-08T00:00:00Z
+func (h *OptimizationHandler) GetTableAnalytics(c *gin.Context) {
+	var req service.TableAnalyticsRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid query parameters",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.GetTableAnalytics(req)
+	c.JSON(floorPlanStatus(result.Success, result.NotFound), result)
+}
+
+// occupancyStatus maps a table occupancy response to its HTTP status code
+func occupancyStatus(result service.TableOccupancyResponse) int {
+	switch {
+	case result.NotFound:
+		return http.StatusNotFound
+	case result.Conflict:
+		return http.StatusConflict
+	case result.Invalid:
+		return http.StatusUnprocessableEntity
+	case !result.Success:
+		return http.StatusBadRequest
+	default:
+		return http.StatusOK
+	}
+}
+
+// floorPlanStatus maps a floor plan response to its HTTP status code
+func floorPlanStatus(success, notFound bool) int {
+	switch {
+	case notFound:
+		return http.StatusNotFound
+	case !success:
+		return http.StatusBadRequest
+	default:
+		return http.StatusOK
+	}
+}
+
+// PlanWaitlist handles waitlist ordering and wait estimate requests
+func (h *OptimizationHandler) PlanWaitlist(c *gin.Context) {
+	var req service.WaitlistRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Groups) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "At least one waiting group is required",
+		})
+		return
+	}
+
+	result := h.optimizationService.PlanWaitlist(req)
+
+	status := http.StatusOK
+	switch {
+	case result.NotFound:
+		status = http.StatusNotFound
+	case !result.Success:
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
+}
+
+// SubmitJob queues a calculation to run in the background and returns its job ID
+func (h *OptimizationHandler) SubmitJob(c *gin.Context) {
+	var req service.SubmitJobRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	validTypes := make(map[string]bool)
+	for _, jobType := range service.JobTypes() {
+		validTypes[jobType] = true
+	}
+
+	if !validTypes[req.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":       false,
+			"error":         "Invalid job type",
+			"valid_options": service.JobTypes(),
+		})
+		return
+	}
+
+	result := h.optimizationService.SubmitJob(req)
+	c.JSON(jobStatus(result, http.StatusAccepted), result)
+}
+
+// GetJob returns the status of a background job and its result once finished
+func (h *OptimizationHandler) GetJob(c *gin.Context) {
+	result := h.optimizationService.GetJob(c.Param("id"))
+	c.JSON(jobStatus(result, http.StatusOK), result)
+}
+
+// CancelJob cancels a queued or running background job
+func (h *OptimizationHandler) CancelJob(c *gin.Context) {
+	result := h.optimizationService.CancelJob(c.Param("id"))
+	c.JSON(jobStatus(result, http.StatusOK), result)
+}
+
+// jobStatus maps a job response to an HTTP status, using success for successful results
+func jobStatus(result service.JobResponse, success int) int {
+	switch {
+	case result.NotFound:
+		return http.StatusNotFound
+	case result.Conflict:
+		return http.StatusConflict
+	case result.QueueFull:
+		return http.StatusServiceUnavailable
+	case !result.Success:
+		return http.StatusBadRequest
+	default:
+		return success
+	}
+}
+
+// GetDrawer returns the current contents of a cash drawer
+func (h *OptimizationHandler) GetDrawer(c *gin.Context) {
+	result := h.optimizationService.GetDrawer(c.Param("id"))
+	c.JSON(drawerStatus(result), result)
+}
+
+// LoadDrawer sets the full contents of a cash drawer (e.g. the opening float)
+func (h *OptimizationHandler) LoadDrawer(c *gin.Context) {
+	var req service.LoadDrawerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.LoadDrawer(c.Param("id"), req)
+	c.JSON(drawerStatus(result), result)
+}
+
+// ReplenishDrawer adds pieces to a cash drawer
+func (h *OptimizationHandler) ReplenishDrawer(c *gin.Context) {
+	var req service.ReplenishDrawerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := h.optimizationService.ReplenishDrawer(c.Param("id"), req)
+	c.JSON(drawerStatus(result), result)
+}
+
+// ReconcileDrawer handles end-of-shift drawer reconciliation requests
+func (h *OptimizationHandler) ReconcileDrawer(c *gin.Context) {
+	var req service.ReconcileDrawerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Counted == nil || (req.Expected == nil && req.DrawerID == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "counted and either expected or drawer_id are required",
+		})
+		return
+	}
+
+	positiveFloat := req.FloatAmount > 0
+	if req.FloatAmountCents != nil {
+		positiveFloat = *req.FloatAmountCents > 0
+	}
+	if req.FloatContents == nil && !positiveFloat {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Either float_contents or a positive float_amount or float_amount_cents is required",
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.ReconcileDrawer(req)
+
 	status := http.StatusOK
 	if !result.Success {
 		status = http.StatusBadRequest
@@ -64,6 +1081,101 @@ func (h *OptimizationHandler) CalculateChange(c *gin.Context) {
 	c.JSON(status, result)
 }
 
+// drawerStatus maps a drawer response to its HTTP status code
+func drawerStatus(result service.DrawerResponse) int {
+	switch {
+	case result.NotFound:
+		return http.StatusNotFound
+	case result.Invalid:
+		return http.StatusUnprocessableEntity
+	case !result.Success:
+		return http.StatusBadRequest
+	default:
+		return http.StatusOK
+	}
+}
+
+// ListDenominationSets returns every configured denomination set
+func (h *OptimizationHandler) ListDenominationSets(c *gin.Context) {
+	c.JSON(http.StatusOK, h.optimizationService.ListDenominationSets())
+}
+
+// GetDenominationSet returns a single denomination set
+func (h *OptimizationHandler) GetDenominationSet(c *gin.Context) {
+	result := h.optimizationService.GetDenominationSet(c.Param("name"))
+	c.JSON(denominationSetStatus(result, http.StatusOK), result)
+}
+
+// CreateDenominationSet adds a named denomination set at runtime
+func (h *OptimizationHandler) CreateDenominationSet(c *gin.Context) {
+	var req service.DenominationSetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.CreateDenominationSet(req)
+	c.JSON(denominationSetStatus(result, http.StatusCreated), result)
+}
+
+// UpdateDenominationSet replaces a denomination set at runtime
+func (h *OptimizationHandler) UpdateDenominationSet(c *gin.Context) {
+	var req service.DenominationSetRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request format",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Currency != "" && !service.IsCurrencyCode(req.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid currency code, expected a 3-letter code such as USD or COP",
+		})
+		return
+	}
+
+	result := h.optimizationService.UpdateDenominationSet(c.Param("name"), req)
+	c.JSON(denominationSetStatus(result, http.StatusOK), result)
+}
+
+// DeleteDenominationSet removes a denomination set
+func (h *OptimizationHandler) DeleteDenominationSet(c *gin.Context) {
+	result := h.optimizationService.DeleteDenominationSet(c.Param("name"))
+	c.JSON(denominationSetStatus(result, http.StatusOK), result)
+}
+
+// denominationSetStatus maps a denomination set response to its HTTP status code
+func denominationSetStatus(result service.DenominationSetResponse, success int) int {
+	switch {
+	case result.NotFound:
+		return http.StatusNotFound
+	case result.Conflict:
+		return http.StatusConflict
+	case !result.Success:
+		return http.StatusBadRequest
+	default:
+		return success
+	}
+}
+
 // SortProducts handles product sorting requests
 func (h *OptimizationHandler) SortProducts(c *gin.Context) {
 	var req service.SortProductsRequest
@@ -185,13 +1297,16 @@ func (h *OptimizationHandler) AnalyzeOrder(c *gin.Context) {
 	c.JSON(status, result)
 }
 
-// GetAvailableCoins returns the available coin denominations
+// GetAvailableCoins returns the available coin denominations for a currency (?currency=USD&locale=en-US)
 func (h *OptimizationHandler) GetAvailableCoins(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"coins":   []string{"$50.00", "$20.00", "$10.00", "$5.00", "$2.00", "$1.00", "$0.50", "$0.25", "$0.10", "$0.05", "$0.01"},
-		"message": "Available coin denominations for change calculation",
-	})
+	result := h.optimizationService.GetAvailableCoins(c.Query("currency"), c.Query("locale"))
+
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, result)
 }
 
 // GetSupportedAlgorithms returns information about supported algorithms
@@ -200,9 +1315,94 @@ func (h *OptimizationHandler) GetSupportedAlgorithms(c *gin.Context) {
 		"success": true,
 		"algorithms": gin.H{
 			"money_change": gin.H{
-				"description": "Greedy algorithm for optimal coin change",
-				"complexity":  "O(n log n) for sorting + O(n) for processing",
-				"use_case":    "Calculate optimal change when customer pays in cash",
+				"description": "Greedy and dynamic programming algorithms for optimal coin change",
+				"modes":       []string{"auto", "greedy", "dp"},
+				"complexity": gin.H{
+					"auto":   "O(n³) canonical check (Pearson) when the denominations are set, then greedy or dp",
+					"greedy": "O(n log n) for sorting + O(n) for processing",
+					"dp":     "O(n * amount), optimal for any coin system",
+				},
+				"use_case": "Calculate optimal change when customer pays in cash",
+			},
+			"bill_split": gin.H{
+				"description": "Even, proportional and per-item bill splitting; even splits pick amounts that are easy to pay in cash",
+				"methods":     []string{"even", "proportional", "items"},
+				"complexity": gin.H{
+					"even":         "O(g² · d²) for g guests and d adjustment steps",
+					"proportional": "O(g log g)",
+					"items":        "O(i · g) for i items",
+				},
+				"use_case": "Split a shared table's order among guests",
+			},
+			"tip_split": gin.H{
+				"description": "Largest-remainder distribution of a tip pool by weighted hours and sales",
+				"complexity":  "O(n log n) for n staff members",
+				"use_case":    "Split the tip pool fairly, to the cent, at the end of a shift",
+			},
+			"inventory_planning": gin.H{
+				"description": "Economic order quantity (sqrt(2DS/H)) rounded to whole packs, reorder points, safety stock and par levels for a target service level, and ABC classification by cumulative value",
+				"complexity":  "O(n) for n products, O(n log n) for ABC classification",
+				"use_case":    "Decide when and how much of each product to order",
+			},
+			"waitlist": gin.H{
+				"description": "Event simulation that gives each table, as it frees up, to the best-fitting waiting group, longest wait first on ties",
+				"complexity":  "O(g² · t) for g groups and t tables",
+				"use_case":    "Order the waitlist, quote each group a table and seating time, and stress-test layouts against arrival scenarios",
+			},
+			"stable_matching": gin.H{
+				"description": "Gale–Shapley deferred acceptance: groups propose to tables in their preference order, tables rank groups by explicit lists and house rules",
+				"complexity":  "O(g · t) proposals for g groups and t tables",
+				"use_case":    "Seat the waitlist at the free tables with an explainable outcome no group and table would both rather change",
+			},
+			"event_seating": gin.H{
+				"description": "Graph partitioning of the guest list: greedy placement by strongest ties, then move and swap local search, with avoid pairs kept at different tables",
+				"complexity":  "O(p · g²) for g guests and p improvement passes",
+				"use_case":    "Build a seat-level chart for a private event from affinity and avoid pairs",
+			},
+			"evening_plan": gin.H{
+				"description": "Interval scheduling on a table-by-slot grid: reservations at their slot by best fit, moving one booking aside when needed, then forecast walk-ins in the gaps",
+				"complexity":  "O(g · t · s) for g groups, t tables and s slots",
+				"use_case":    "Plan the whole evening's tables from the day's reservations and forecast walk-ins to seat the most covers",
+			},
+			"staff_assignment": gin.H{
+				"description": "Hungarian method on a staff-by-position score matrix built from skills, seniority and expected station load",
+				"complexity":  "O(n² m) for n staff or positions, whichever is fewer, and m the other",
+				"use_case":    "Place bartenders and waiters at stations and sections, the busiest going to the most experienced staff with the right skills",
+			},
+			"route_tsp": gin.H{
+				"description": "Nearest-neighbor tour improved with 2-opt over coordinates or a distance matrix, open or back to the start; with time windows, the least late and then shortest tour by stretch reversals and stop moves",
+				"complexity":  "O(p · n²) for p 2-opt passes over n stops, O(p · n³) with one-way distances or time windows",
+				"use_case":    "Order a driver's home deliveries to cover the least distance",
+			},
+			"route_vrp": gin.H{
+				"description": "Clarke–Wright savings within vehicle capacities and shift limits, leftover stops by cheapest insertion, then 2-opt on each route",
+				"complexity":  "O(n² log n) for n stops, plus 2-opt on each route",
+				"use_case":    "Split the evening's delivery orders among drivers and give each an itinerary",
+			},
+			"linear_programming": gin.H{
+				"description": "Two-phase simplex method on a dense tableau pricing by the most negative reduced cost, with Bland's rule against cycling, returning the optimal solution, objective value and constraint duals",
+				"complexity":  "Exponential worst case, bounded to about a second of pivots, for problems up to 200 variables and constraints",
+				"use_case":    "Solve allocation, blending and mix problems for other services without a dedicated endpoint",
+			},
+			"order_batching": gin.H{
+				"description": "Greedy batching of the same drink at the same station in order placed, bounded by each order's maximum delay and the batch size, then batches made as released",
+				"complexity":  "O(n log n) for n orders",
+				"use_case":    "Group pending cocktail orders so each station sets up once per batch instead of once per order",
+			},
+			"cocktail_batching": gin.H{
+				"description": "Linear program solved with the two-phase simplex method, maximizing weighted servings over the millilitres of each ingredient in each mix under stock limits and recipe ratios",
+				"complexity":  "Exponential worst case, fast in practice for tens of cocktails",
+				"use_case":    "Decide how much of each cocktail mix to pre-batch before service from the ingredients on hand, and which ingredients limit it",
+			},
+			"happy_hour": gin.H{
+				"description": "Dynamic programming over the hours for the non-overlapping windows and discount levels with the highest projected profit gain",
+				"complexity":  "O(h · L · k · w) for h hours, windows up to L hours, k discount levels and w windows",
+				"use_case":    "Choose when to run happy hour and how deep a discount to offer from hourly sales and margins",
+			},
+			"dynamic_pricing": gin.H{
+				"description": "Closed-form profit or revenue peak under constant-elasticity demand, clamped to the minimum margin and the largest daily change",
+				"complexity":  "O(n) for n products",
+				"use_case":    "Adjust drink and food prices day by day from sales velocity and price elasticity without eroding margins",
 			},
 			"sorting": gin.H{
 				"description": "Various sorting algorithms for products and data",
@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+var (
+	// ErrJobNotFound is returned when no job exists with the given ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrQueueFull is returned when too many jobs are waiting to run
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrJobFinished is returned when cancelling a job that already finished
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// Finished jobs are kept for finishedJobTTL, and only the maxFinishedJobs that finished last
+const (
+	finishedJobTTL  = time.Hour
+	maxFinishedJobs = 1000
+)
+
+// Func does the work of a job. It should return early once ctx is cancelled; the result
+// is kept even when an error is returned
+type Func func(ctx context.Context) (interface{}, error)
+
+// Job is a snapshot of a background job
+type Job struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Status     string      `json:"status"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	StartedAt  *time.Time  `json:"started_at,omitempty"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
+
+type job struct {
+	Job
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Manager runs jobs in the background on a fixed number of workers
+type Manager struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	queue chan *job
+}
+
+// NewManager creates a manager running at most workers jobs at a time with up to
+// queueSize jobs waiting
+func NewManager(workers, queueSize int) *Manager {
+	m := &Manager{
+		jobs:  make(map[string]*job),
+		queue: make(chan *job, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Submit queues fn and returns the new job without waiting for it to run
+func (m *Manager) Submit(jobType string, fn Func) (Job, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		Job: Job{
+			ID:        newJobID(),
+			Type:      jobType,
+			Status:    StatusQueued,
+			CreatedAt: time.Now(),
+		},
+		fn:     fn,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune()
+	select {
+	case m.queue <- j:
+	default:
+		cancel()
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+// Get returns the current state of a job
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	return j.Job, nil
+}
+
+// Cancel stops a queued or running job. A running job's result is discarded even if
+// its work cannot be interrupted
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+	if j.FinishedAt != nil {
+		return j.Job, ErrJobFinished
+	}
+
+	j.cancel()
+	now := time.Now()
+	j.Status = StatusCancelled
+	j.FinishedAt = &now
+	return j.Job, nil
+}
+
+// work runs queued jobs until the process exits
+func (m *Manager) work() {
+	for j := range m.queue {
+		m.mu.Lock()
+		if j.Status == StatusCancelled {
+			m.mu.Unlock()
+			continue
+		}
+		now := time.Now()
+		j.Status = StatusRunning
+		j.StartedAt = &now
+		m.mu.Unlock()
+
+		result, err := j.fn(j.ctx)
+
+		m.mu.Lock()
+		if j.Status == StatusRunning {
+			now := time.Now()
+			j.Result = result
+			j.Status = StatusSucceeded
+			if err != nil {
+				j.Status = StatusFailed
+				j.Error = err.Error()
+			}
+			j.FinishedAt = &now
+		}
+		m.mu.Unlock()
+		j.cancel()
+	}
+}
+
+// prune forgets jobs that finished more than finishedJobTTL ago, then the oldest finished
+// jobs beyond maxFinishedJobs; m.mu must be held
+func (m *Manager) prune() {
+	cutoff := time.Now().Add(-finishedJobTTL)
+	var finished []*job
+	for id, j := range m.jobs {
+		if j.FinishedAt == nil {
+			continue
+		}
+		if j.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+			continue
+		}
+		finished = append(finished, j)
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool { return finished[a].FinishedAt.Before(*finished[b].FinishedAt) })
+	for _, j := range finished[:len(finished)-maxFinishedJobs] {
+		delete(m.jobs, j.ID)
+	}
+}
+
+// newJobID returns a random 128-bit job ID
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
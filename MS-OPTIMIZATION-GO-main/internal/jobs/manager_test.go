@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPruneKeepsTheLatestFinishedJobs(t *testing.T) {
+	m := &Manager{jobs: make(map[string]*job)}
+	now := time.Now()
+	for i := 0; i < maxFinishedJobs+10; i++ {
+		finished := now.Add(time.Duration(i) * time.Second)
+		m.jobs[newJobID()] = &job{Job: Job{Status: StatusSucceeded, FinishedAt: &finished}}
+	}
+	expired := now.Add(-2 * finishedJobTTL)
+	m.jobs["expired"] = &job{Job: Job{ID: "expired", Status: StatusSucceeded, FinishedAt: &expired}}
+	m.jobs["running"] = &job{Job: Job{ID: "running", Status: StatusRunning}}
+	for id, j := range m.jobs {
+		j.ID = id
+	}
+
+	m.prune()
+	if len(m.jobs) != maxFinishedJobs+1 {
+		t.Fatalf("%d jobs kept, want %d", len(m.jobs), maxFinishedJobs+1)
+	}
+	if _, ok := m.jobs["running"]; !ok {
+		t.Error("running job pruned")
+	}
+	if _, ok := m.jobs["expired"]; ok {
+		t.Error("expired job kept")
+	}
+	for _, j := range m.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(now.Add(10*time.Second)) {
+			t.Errorf("job finished at %v kept over later ones", j.FinishedAt)
+		}
+	}
+}
+
+func TestSubmitRunsAndCancels(t *testing.T) {
+	m := NewManager(1, 2)
+	done, err := m.Submit("test", func(ctx context.Context) (interface{}, error) { return 42, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		job, _ := m.Get(done.ID)
+		if job.Status == StatusSucceeded && job.Result == 42 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %+v", job)
+		}
+	}
+
+	blocked, _ := m.Submit("test", func(ctx context.Context) (interface{}, error) { <-ctx.Done(); return nil, ctx.Err() })
+	if job, err := m.Cancel(blocked.ID); err != nil || job.Status != StatusCancelled {
+		t.Errorf("cancel: %+v, %v", job, err)
+	}
+	if _, err := m.Cancel(blocked.ID); err != ErrJobFinished {
+		t.Errorf("second cancel: %v, want %v", err, ErrJobFinished)
+	}
+	if _, err := m.Get("missing"); err != ErrJobNotFound {
+		t.Errorf("get: %v, want %v", err, ErrJobNotFound)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// ChangeRecord is an audit log entry for one change calculation
+type ChangeRecord struct {
+	ID           int64       `json:"id"`
+	TerminalID   string      `json:"terminal_id,omitempty"`
+	DrawerID     string      `json:"drawer_id,omitempty"`
+	Currency     string      `json:"currency"`
+	AmountPaid   int         `json:"-"` // all amounts in minor units (cents)
+	TotalCost    int         `json:"-"`
+	ChangeAmount int         `json:"-"`
+	Breakdown    map[int]int `json:"-"` // denomination -> quantity handed out
+	DrawerBefore map[int]int `json:"-"` // drawer contents the change was calculated against, nil if unlimited
+	Algorithm    string      `json:"algorithm"`
+	Success      bool        `json:"success"`
+	Message      string      `json:"message"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// ChangeRecordFilter selects change records; zero fields match everything
+type ChangeRecordFilter struct {
+	TerminalID string
+	DrawerID   string
+	Currency   string
+	Success    *bool
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Limit      int
+	Offset     int
+}
+
+// Matches reports whether the record passes the filter, ignoring Limit and Offset
+func (f ChangeRecordFilter) Matches(record *ChangeRecord) bool {
+	return (f.TerminalID == "" || record.TerminalID == f.TerminalID) &&
+		(f.DrawerID == "" || record.DrawerID == f.DrawerID) &&
+		(f.Currency == "" || record.Currency == f.Currency) &&
+		(f.Success == nil || record.Success == *f.Success) &&
+		(f.From.IsZero() || !record.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || record.CreatedAt.Before(f.To))
+}
+
+// Copy returns a deep copy of the record so callers can't mutate stored state
+func (r *ChangeRecord) Copy() *ChangeRecord {
+	record := *r
+	record.Breakdown = copyCounts(r.Breakdown)
+	record.DrawerBefore = copyCounts(r.DrawerBefore)
+	return &record
+}
+
+// copyCounts copies a denomination -> quantity map, keeping nil as nil
+func copyCounts(counts map[int]int) map[int]int {
+	if counts == nil {
+		return nil
+	}
+	copied := make(map[int]int, len(counts))
+	for denomination, quantity := range counts {
+		copied[denomination] = quantity
+	}
+	return copied
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Drawer represents the physical contents of a cash drawer (till)
+type Drawer struct {
+	ID        string      `json:"id"`
+	Currency  string      `json:"currency"`
+	Counts    map[int]int `json:"-"` // denomination in minor units (cents) -> quantity
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Copy returns a deep copy of the drawer so callers can't mutate stored state
+func (d *Drawer) Copy() *Drawer {
+	counts := make(map[int]int, len(d.Counts))
+	for denomination, quantity := range d.Counts {
+		counts[denomination] = quantity
+	}
+
+	return &Drawer{
+		ID:        d.ID,
+		Currency:  d.Currency,
+		Counts:    counts,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// FloorPlan is one version of a venue's table layout
+type FloorPlan struct {
+	ID        string           `json:"id"`
+	Version   int              `json:"version"`
+	Name      string           `json:"name,omitempty"`
+	Days      []string         `json:"days,omitempty"` // lowercase weekdays the layout is used on, empty for every day
+	Tables    []FloorPlanTable `json:"tables"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// FloorPlanTable is a table and its place on the floor
+type FloorPlanTable struct {
+	ID         string   `json:"id"`
+	Capacity   int      `json:"capacity"`
+	X          float64  `json:"x"`
+	Y          float64  `json:"y"`
+	Zone       string   `json:"zone,omitempty"`       // e.g. patio, bar, window
+	Attributes []string `json:"attributes,omitempty"` // e.g. smoking, quiet, booth
+	Section    string   `json:"section,omitempty"`    // server section the table belongs to
+
+	WheelchairAccessible bool     `json:"wheelchair_accessible,omitempty"`
+	Adjacent             []string `json:"adjacent,omitempty"` // tables that can be pushed together with this one
+}
+
+// UsedOn reports whether the layout applies on the given day
+func (p *FloorPlan) UsedOn(day time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	name := strings.ToLower(day.String())
+	for _, d := range p.Days {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TableIndex returns the position of the table with the given ID, or -1
+func (p *FloorPlan) TableIndex(id string) int {
+	for i, table := range p.Tables {
+		if table.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Copy returns a deep copy of the floor plan so callers can't mutate stored state
+func (p *FloorPlan) Copy() *FloorPlan {
+	plan := *p
+	plan.Days = append([]string(nil), p.Days...)
+	plan.Tables = make([]FloorPlanTable, len(p.Tables))
+	for i, table := range p.Tables {
+		table.Adjacent = append([]string(nil), table.Adjacent...)
+		plan.Tables[i] = table
+	}
+	return &plan
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// TableOccupancy is the live state of one table of a floor plan
+type TableOccupancy struct {
+	FloorPlanID       string    `json:"floor_plan_id"`
+	TableID           string    `json:"table_id"`
+	Occupied          bool      `json:"occupied"`
+	GroupID           string    `json:"group_id,omitempty"`
+	Size              int       `json:"size,omitempty"`
+	SeatedAt          time.Time `json:"seated_at"`
+	ExpectedDeparture time.Time `json:"expected_departure"`
+	Version           int64     `json:"version"` // incremented on every seat and release
+}
+
+// TableSession is one finished stay of a group at a table, kept for utilization analytics
+type TableSession struct {
+	FloorPlanID string    `json:"floor_plan_id"`
+	TableID     string    `json:"table_id"`
+	GroupID     string    `json:"group_id,omitempty"`
+	Size        int       `json:"size"`
+	SeatedAt    time.Time `json:"seated_at"`
+	ReleasedAt  time.Time `json:"released_at"`
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Event types sent to the webhook
+const (
+	EventChangeShortage     = "change.shortage"
+	EventSeatingPlanUpdated = "seating.plan_updated"
+)
+
+// Event is the envelope POSTed to the webhook
+type Event struct {
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Notifier delivers events to an external alerting system
+type Notifier interface {
+	// Notify queues the event for delivery without blocking the caller
+	Notify(event Event)
+}
+
+type nopNotifier struct{}
+
+// NewNopNotifier creates a notifier that discards every event
+func NewNopNotifier() Notifier {
+	return nopNotifier{}
+}
+
+func (nopNotifier) Notify(Event) {}
+
+// Delivery settings for the webhook notifier
+const (
+	webhookQueueSize   = 100
+	webhookMaxAttempts = 5
+	webhookTimeout     = 5 * time.Second
+	webhookBackoff     = 500 * time.Millisecond
+)
+
+type webhookNotifier struct {
+	url         string
+	client      *http.Client
+	queue       chan Event
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewWebhookNotifier creates a notifier that POSTs events as JSON to url from a background
+// worker, retrying network errors and 429/5xx responses with exponential backoff
+func NewWebhookNotifier(url string) Notifier {
+	n := &webhookNotifier{
+		url:         url,
+		client:      &http.Client{Timeout: webhookTimeout},
+		queue:       make(chan Event, webhookQueueSize),
+		maxAttempts: webhookMaxAttempts,
+		backoff:     webhookBackoff,
+	}
+	go n.run()
+	return n
+}
+
+func (n *webhookNotifier) Notify(event Event) {
+	select {
+	case n.queue <- event:
+	default:
+		log.Printf("webhook queue full, dropping %s event", event.Type)
+	}
+}
+
+// run delivers queued events one at a time
+func (n *webhookNotifier) run() {
+	for event := range n.queue {
+		if err := n.deliver(event); err != nil {
+			log.Printf("failed to deliver %s event: %v", event.Type, err)
+		}
+	}
+}
+
+// deliver POSTs the event, retrying failures that may be temporary
+func (n *webhookNotifier) deliver(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	delay := n.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == n.maxAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post sends one delivery attempt and reports whether a failure is worth retrying
+func (n *webhookNotifier) post(body []byte) (retry bool, err error) {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
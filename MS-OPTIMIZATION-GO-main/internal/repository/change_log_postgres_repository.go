@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ms-optimization-go/internal/models"
+
+	_ "github.com/lib/pq"
+)
+
+type postgresChangeLogRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresChangeLogRepository creates a change log backed by PostgreSQL. Its tables are
+// created by the database scripts in INFRA-BAR-DK-main/database
+func NewPostgresChangeLogRepository(dbURL string) (ChangeLogRepository, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &postgresChangeLogRepository{db: db}, nil
+}
+
+func (r *postgresChangeLogRepository) AddChangeRecord(record *models.ChangeRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	breakdown, err := json.Marshal(record.Breakdown)
+	if err != nil {
+		return err
+	}
+	var drawerBefore []byte
+	if record.DrawerBefore != nil {
+		if drawerBefore, err = json.Marshal(record.DrawerBefore); err != nil {
+			return err
+		}
+	}
+
+	return r.db.QueryRow(`
+		INSERT INTO bar_system.change_log (terminal_id, drawer_id, currency, amount_paid_cents, total_cost_cents,
+			change_cents, breakdown, drawer_before, algorithm, success, message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, record.TerminalID, record.DrawerID, record.Currency, record.AmountPaid, record.TotalCost,
+		record.ChangeAmount, breakdown, drawerBefore, record.Algorithm, record.Success, record.Message, record.CreatedAt,
+	).Scan(&record.ID)
+}
+
+func (r *postgresChangeLogRepository) ListChangeRecords(filter models.ChangeRecordFilter) ([]*models.ChangeRecord, int, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.TerminalID != "" {
+		where("terminal_id = $%d", filter.TerminalID)
+	}
+	if filter.DrawerID != "" {
+		where("drawer_id = $%d", filter.DrawerID)
+	}
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.Success != nil {
+		where("success = $%d", *filter.Success)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	clause := ""
+	if len(conditions) > 0 {
+		clause = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM bar_system.change_log`+clause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, terminal_id, drawer_id, currency, amount_paid_cents, total_cost_cents, change_cents,
+			breakdown, drawer_before, algorithm, success, message, created_at
+		FROM bar_system.change_log` + clause + ` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", filter.Offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var records []*models.ChangeRecord
+	for rows.Next() {
+		record := &models.ChangeRecord{}
+		var breakdown, drawerBefore []byte
+		err := rows.Scan(&record.ID, &record.TerminalID, &record.DrawerID, &record.Currency, &record.AmountPaid,
+			&record.TotalCost, &record.ChangeAmount, &breakdown, &drawerBefore, &record.Algorithm, &record.Success,
+			&record.Message, &record.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(breakdown, &record.Breakdown); err != nil {
+			return nil, 0, err
+		}
+		if drawerBefore != nil {
+			if err := json.Unmarshal(drawerBefore, &record.DrawerBefore); err != nil {
+				return nil, 0, err
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, total, rows.Err()
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"ms-optimization-go/internal/models"
+)
+
+// maxMemoryChangeRecords bounds the in-memory change log; the oldest records are dropped first
+const maxMemoryChangeRecords = 10000
+
+// ChangeLogRepository stores the audit log of change calculations
+type ChangeLogRepository interface {
+	// AddChangeRecord stores a record, assigning its ID and, if unset, its creation time
+	AddChangeRecord(record *models.ChangeRecord) error
+	// ListChangeRecords returns the matching records newest first, with the total number of matches
+	ListChangeRecords(filter models.ChangeRecordFilter) ([]*models.ChangeRecord, int, error)
+}
+
+type memoryChangeLogRepository struct {
+	mu      sync.Mutex
+	records []*models.ChangeRecord // oldest first
+	nextID  int64
+}
+
+// NewMemoryChangeLogRepository creates an in-memory change log (state is lost on restart)
+func NewMemoryChangeLogRepository() ChangeLogRepository {
+	return &memoryChangeLogRepository{nextID: 1}
+}
+
+func (r *memoryChangeLogRepository) AddChangeRecord(record *models.ChangeRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.ID = r.nextID
+	r.nextID++
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	if len(r.records) == maxMemoryChangeRecords {
+		r.records = r.records[1:]
+	}
+	r.records = append(r.records, record.Copy())
+	return nil
+}
+
+func (r *memoryChangeLogRepository) ListChangeRecords(filter models.ChangeRecordFilter) ([]*models.ChangeRecord, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var records []*models.ChangeRecord
+	total := 0
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if !filter.Matches(r.records[i]) {
+			continue
+		}
+		if total >= filter.Offset && (filter.Limit <= 0 || len(records) < filter.Limit) {
+			records = append(records, r.records[i].Copy())
+		}
+		total++
+	}
+	return records, total, nil
+}
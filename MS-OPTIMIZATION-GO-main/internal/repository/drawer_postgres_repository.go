@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ms-optimization-go/internal/models"
+
+	_ "github.com/lib/pq"
+)
+
+type postgresDrawerRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDrawerRepository creates a drawer store backed by PostgreSQL. Its tables are
+// created by the database scripts in INFRA-BAR-DK-main/database
+func NewPostgresDrawerRepository(dbURL string) (DrawerRepository, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &postgresDrawerRepository{db: db}, nil
+}
+
+func (r *postgresDrawerRepository) GetDrawer(id string) (*models.Drawer, error) {
+	return getDrawer(r.db, id)
+}
+
+func (r *postgresDrawerRepository) CurrencyInUse(currency string) (bool, error) {
+	var inUse bool
+	err := r.db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM bar_system.cash_drawers WHERE currency = $1)`, currency,
+	).Scan(&inUse)
+	return inUse, err
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func getDrawer(q queryer, id string) (*models.Drawer, error) {
+	drawer := &models.Drawer{ID: id, Counts: make(map[int]int)}
+
+	err := q.QueryRow(
+		`SELECT currency, updated_at FROM bar_system.cash_drawers WHERE id = $1`, id,
+	).Scan(&drawer.Currency, &drawer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrDrawerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(
+		`SELECT denomination_cents, quantity FROM bar_system.cash_drawer_counts WHERE drawer_id = $1`, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var denomination, quantity int
+		if err := rows.Scan(&denomination, &quantity); err != nil {
+			return nil, err
+		}
+		drawer.Counts[denomination] = quantity
+	}
+
+	return drawer, rows.Err()
+}
+
+func (r *postgresDrawerRepository) SaveDrawer(drawer *models.Drawer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	drawer.UpdatedAt = time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO bar_system.cash_drawers (id, currency, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET currency = EXCLUDED.currency, updated_at = EXCLUDED.updated_at
+	`, drawer.ID, drawer.Currency, drawer.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM bar_system.cash_drawer_counts WHERE drawer_id = $1`, drawer.ID); err != nil {
+		return err
+	}
+
+	for denomination, quantity := range drawer.Counts {
+		_, err := tx.Exec(`
+			INSERT INTO bar_system.cash_drawer_counts (drawer_id, denomination_cents, quantity)
+			VALUES ($1, $2, $3)
+		`, drawer.ID, denomination, quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresDrawerRepository) AddToDrawer(id string, counts map[int]int) (*models.Drawer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE bar_system.cash_drawers SET updated_at = $2 WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrDrawerNotFound
+	}
+
+	for denomination, quantity := range counts {
+		_, err := tx.Exec(`
+			INSERT INTO bar_system.cash_drawer_counts (drawer_id, denomination_cents, quantity)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (drawer_id, denomination_cents)
+			DO UPDATE SET quantity = bar_system.cash_drawer_counts.quantity + EXCLUDED.quantity
+		`, id, denomination, quantity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	drawer, err := getDrawer(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return drawer, tx.Commit()
+}
+
+func (r *postgresDrawerRepository) Dispense(id string, counts map[int]int) (*models.Drawer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE bar_system.cash_drawers SET updated_at = $2 WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrDrawerNotFound
+	}
+
+	for denomination, quantity := range counts {
+		// The quantity guard makes concurrent dispenses safe without explicit row locks
+		result, err := tx.Exec(`
+			UPDATE bar_system.cash_drawer_counts
+			SET quantity = quantity - $3
+			WHERE drawer_id = $1 AND denomination_cents = $2 AND quantity >= $3
+		`, id, denomination, quantity)
+		if err != nil {
+			return nil, err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return nil, ErrInsufficientDrawer
+		}
+	}
+
+	drawer, err := getDrawer(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return drawer, tx.Commit()
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ms-optimization-go/internal/models"
+)
+
+var (
+	// ErrDrawerNotFound is returned when no drawer exists with the given ID
+	ErrDrawerNotFound = errors.New("drawer not found")
+	// ErrInsufficientDrawer is returned when a dispense needs more pieces than the drawer holds
+	ErrInsufficientDrawer = errors.New("drawer does not hold enough pieces to dispense")
+)
+
+// DrawerRepository stores cash drawer contents
+type DrawerRepository interface {
+	GetDrawer(id string) (*models.Drawer, error)
+	// SaveDrawer replaces the drawer contents, creating the drawer if needed
+	SaveDrawer(drawer *models.Drawer) error
+	// AddToDrawer adds pieces to an existing drawer
+	AddToDrawer(id string, counts map[int]int) (*models.Drawer, error)
+	// Dispense atomically removes pieces, failing with ErrInsufficientDrawer if any count would go negative
+	Dispense(id string, counts map[int]int) (*models.Drawer, error)
+	// CurrencyInUse reports whether any stored drawer holds the currency
+	CurrencyInUse(currency string) (bool, error)
+}
+
+type memoryDrawerRepository struct {
+	mu      sync.Mutex
+	drawers map[string]*models.Drawer
+}
+
+// NewMemoryDrawerRepository creates an in-memory drawer store (state is lost on restart)
+func NewMemoryDrawerRepository() DrawerRepository {
+	return &memoryDrawerRepository{
+		drawers: make(map[string]*models.Drawer),
+	}
+}
+
+func (r *memoryDrawerRepository) GetDrawer(id string) (*models.Drawer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drawer, ok := r.drawers[id]
+	if !ok {
+		return nil, ErrDrawerNotFound
+	}
+	return drawer.Copy(), nil
+}
+
+func (r *memoryDrawerRepository) SaveDrawer(drawer *models.Drawer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := drawer.Copy()
+	stored.UpdatedAt = time.Now()
+	r.drawers[drawer.ID] = stored
+	drawer.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (r *memoryDrawerRepository) AddToDrawer(id string, counts map[int]int) (*models.Drawer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drawer, ok := r.drawers[id]
+	if !ok {
+		return nil, ErrDrawerNotFound
+	}
+
+	for denomination, quantity := range counts {
+		drawer.Counts[denomination] += quantity
+	}
+	drawer.UpdatedAt = time.Now()
+	return drawer.Copy(), nil
+}
+
+func (r *memoryDrawerRepository) Dispense(id string, counts map[int]int) (*models.Drawer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	drawer, ok := r.drawers[id]
+	if !ok {
+		return nil, ErrDrawerNotFound
+	}
+
+	// Check everything first so a failed dispense leaves the drawer untouched
+	for denomination, quantity := range counts {
+		if drawer.Counts[denomination] < quantity {
+			return nil, ErrInsufficientDrawer
+		}
+	}
+	for denomination, quantity := range counts {
+		drawer.Counts[denomination] -= quantity
+	}
+	drawer.UpdatedAt = time.Now()
+	return drawer.Copy(), nil
+}
+
+func (r *memoryDrawerRepository) CurrencyInUse(currency string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, drawer := range r.drawers {
+		if drawer.Currency == currency {
+			return true, nil
+		}
+	}
+	return false, nil
+}
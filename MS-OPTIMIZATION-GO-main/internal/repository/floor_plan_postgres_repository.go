@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"ms-optimization-go/internal/models"
+
+	_ "github.com/lib/pq"
+)
+
+type postgresFloorPlanRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresFloorPlanRepository creates a floor plan store backed by PostgreSQL. Its tables are
+// created by the database scripts in INFRA-BAR-DK-main/database
+func NewPostgresFloorPlanRepository(dbURL string) (FloorPlanRepository, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &postgresFloorPlanRepository{db: db}, nil
+}
+
+func (r *postgresFloorPlanRepository) AddFloorPlanVersion(plan *models.FloorPlan) error {
+	days, err := json.Marshal(plan.Days)
+	if err != nil {
+		return err
+	}
+	tables, err := json.Marshal(plan.Tables)
+	if err != nil {
+		return err
+	}
+
+	// Two concurrent saves of the same plan can pick the same version; the primary key
+	// rejects the second one
+	return r.db.QueryRow(`
+		INSERT INTO bar_system.floor_plans (id, version, name, days, tables)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4
+		FROM bar_system.floor_plans WHERE id = $1
+		RETURNING version, created_at
+	`, plan.ID, plan.Name, days, tables).Scan(&plan.Version, &plan.CreatedAt)
+}
+
+func (r *postgresFloorPlanRepository) ListFloorPlanVersions(id string) ([]*models.FloorPlan, error) {
+	rows, err := r.db.Query(`
+		SELECT version, name, days, tables, created_at
+		FROM bar_system.floor_plans WHERE id = $1 ORDER BY version
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []*models.FloorPlan
+	for rows.Next() {
+		plan := &models.FloorPlan{ID: id}
+		var days, tables []byte
+		if err := rows.Scan(&plan.Version, &plan.Name, &days, &tables, &plan.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(days, &plan.Days); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(tables, &plan.Tables); err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, ErrFloorPlanNotFound
+	}
+	return plans, nil
+}
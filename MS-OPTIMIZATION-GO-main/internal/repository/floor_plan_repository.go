@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ms-optimization-go/internal/models"
+)
+
+// ErrFloorPlanNotFound is returned when no floor plan, or no such version, exists with the given ID
+var ErrFloorPlanNotFound = errors.New("floor plan not found")
+
+// FloorPlanRepository stores versioned floor plans. Versions are never changed once added
+type FloorPlanRepository interface {
+	// AddFloorPlanVersion stores the plan as the next version of its ID, assigning Version and CreatedAt
+	AddFloorPlanVersion(plan *models.FloorPlan) error
+	// ListFloorPlanVersions returns every version of a floor plan, oldest first
+	ListFloorPlanVersions(id string) ([]*models.FloorPlan, error)
+}
+
+type memoryFloorPlanRepository struct {
+	mu    sync.Mutex
+	plans map[string][]*models.FloorPlan // ID -> versions, oldest first
+}
+
+// NewMemoryFloorPlanRepository creates an in-memory floor plan store (state is lost on restart)
+func NewMemoryFloorPlanRepository() FloorPlanRepository {
+	return &memoryFloorPlanRepository{
+		plans: make(map[string][]*models.FloorPlan),
+	}
+}
+
+func (r *memoryFloorPlanRepository) AddFloorPlanVersion(plan *models.FloorPlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan.Version = len(r.plans[plan.ID]) + 1
+	plan.CreatedAt = time.Now()
+	r.plans[plan.ID] = append(r.plans[plan.ID], plan.Copy())
+	return nil
+}
+
+func (r *memoryFloorPlanRepository) ListFloorPlanVersions(id string) ([]*models.FloorPlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions, ok := r.plans[id]
+	if !ok {
+		return nil, ErrFloorPlanNotFound
+	}
+
+	plans := make([]*models.FloorPlan, len(versions))
+	for i, plan := range versions {
+		plans[i] = plan.Copy()
+	}
+	return plans, nil
+}
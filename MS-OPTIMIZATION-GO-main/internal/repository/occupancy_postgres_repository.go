@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"ms-optimization-go/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code for a duplicate key
+const uniqueViolation = "23505"
+
+type postgresOccupancyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOccupancyRepository creates an occupancy store backed by PostgreSQL. Its tables are
+// created by the database scripts in INFRA-BAR-DK-main/database
+func NewPostgresOccupancyRepository(dbURL string) (OccupancyRepository, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &postgresOccupancyRepository{db: db}, nil
+}
+
+func (r *postgresOccupancyRepository) ListOccupancy(floorPlanID string) ([]*models.TableOccupancy, error) {
+	rows, err := r.db.Query(`
+		SELECT table_id, occupied, group_id, size, seated_at, expected_departure, version
+		FROM bar_system.table_occupancy WHERE floor_plan_id = $1
+	`, floorPlanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []*models.TableOccupancy
+	for rows.Next() {
+		table := &models.TableOccupancy{FloorPlanID: floorPlanID}
+		var seatedAt, expectedDeparture sql.NullTime
+		err := rows.Scan(&table.TableID, &table.Occupied, &table.GroupID, &table.Size, &seatedAt,
+			&expectedDeparture, &table.Version)
+		if err != nil {
+			return nil, err
+		}
+		table.SeatedAt, table.ExpectedDeparture = seatedAt.Time, expectedDeparture.Time
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+func (r *postgresOccupancyRepository) SeatTable(occupancy *models.TableOccupancy, expectedVersion int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the table's row, if any, so the checks and the update see the same state
+	var occupied bool
+	var version int64
+	err = tx.QueryRow(`
+		SELECT occupied, version FROM bar_system.table_occupancy
+		WHERE floor_plan_id = $1 AND table_id = $2 FOR UPDATE
+	`, occupancy.FloorPlanID, occupancy.TableID).Scan(&occupied, &version)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return ErrOccupancyConflict
+	}
+	if occupied {
+		return ErrTableOccupied
+	}
+
+	// The row lock only covers tables seated before: two first-time seats of a table both find
+	// no row and race on the insert, where the primary key rejects the second
+	if err == sql.ErrNoRows {
+		_, err = tx.Exec(`
+			INSERT INTO bar_system.table_occupancy (floor_plan_id, table_id, occupied, group_id, size, seated_at,
+				expected_departure, version)
+			VALUES ($1, $2, TRUE, $3, $4, $5, $6, $7)
+		`, occupancy.FloorPlanID, occupancy.TableID, occupancy.GroupID, occupancy.Size, occupancy.SeatedAt,
+			occupancy.ExpectedDeparture, version+1)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return ErrOccupancyConflict
+		}
+	} else {
+		_, err = tx.Exec(`
+			UPDATE bar_system.table_occupancy
+			SET occupied = TRUE, group_id = $3, size = $4, seated_at = $5, expected_departure = $6, version = $7
+			WHERE floor_plan_id = $1 AND table_id = $2
+		`, occupancy.FloorPlanID, occupancy.TableID, occupancy.GroupID, occupancy.Size, occupancy.SeatedAt,
+			occupancy.ExpectedDeparture, version+1)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	occupancy.Occupied = true
+	occupancy.Version = version + 1
+	return nil
+}
+
+func (r *postgresOccupancyRepository) ReleaseTable(floorPlanID, tableID string, expectedVersion int64) (*models.TableOccupancy, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var occupied bool
+	var version int64
+	var groupID string
+	var size int
+	var seatedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT occupied, version, group_id, size, seated_at FROM bar_system.table_occupancy
+		WHERE floor_plan_id = $1 AND table_id = $2 FOR UPDATE
+	`, floorPlanID, tableID).Scan(&occupied, &version, &groupID, &size, &seatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return nil, ErrOccupancyConflict
+	}
+	if !occupied {
+		return nil, ErrTableNotOccupied
+	}
+
+	_, err = tx.Exec(`
+		UPDATE bar_system.table_occupancy
+		SET occupied = FALSE, group_id = '', size = 0, seated_at = NULL, expected_departure = NULL, version = $3
+		WHERE floor_plan_id = $1 AND table_id = $2
+	`, floorPlanID, tableID, version+1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO bar_system.table_sessions (floor_plan_id, table_id, group_id, size, seated_at, released_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, floorPlanID, tableID, groupID, size, seatedAt.Time, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.TableOccupancy{FloorPlanID: floorPlanID, TableID: tableID, Version: version + 1}, nil
+}
+
+func (r *postgresOccupancyRepository) ListTableSessions(floorPlanID string, from, to time.Time) ([]*models.TableSession, error) {
+	rows, err := r.db.Query(`
+		SELECT table_id, group_id, size, seated_at, released_at
+		FROM bar_system.table_sessions
+		WHERE floor_plan_id = $1 AND released_at > $2 AND seated_at < $3
+		ORDER BY id
+	`, floorPlanID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.TableSession
+	for rows.Next() {
+		session := &models.TableSession{FloorPlanID: floorPlanID}
+		if err := rows.Scan(&session.TableID, &session.GroupID, &session.Size, &session.SeatedAt, &session.ReleasedAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
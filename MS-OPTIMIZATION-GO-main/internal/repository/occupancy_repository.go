@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"ms-optimization-go/internal/models"
+)
+
+var (
+	// ErrTableOccupied is returned when seating a group at a table that is already occupied
+	ErrTableOccupied = errors.New("table is already occupied")
+	// ErrTableNotOccupied is returned when releasing a table that is free
+	ErrTableNotOccupied = errors.New("table is not occupied")
+	// ErrOccupancyConflict is returned when the table changed since the version the caller read
+	ErrOccupancyConflict = errors.New("table occupancy changed since it was read")
+)
+
+// OccupancyRepository stores which floor plan tables are occupied. Changes can be made
+// conditional on the table's version to prevent lost updates; a version of 0 skips the check
+type OccupancyRepository interface {
+	// ListOccupancy returns the state of every table of a floor plan that was ever seated
+	ListOccupancy(floorPlanID string) ([]*models.TableOccupancy, error)
+	// SeatTable marks a free table occupied, assigning the new Version
+	SeatTable(occupancy *models.TableOccupancy, expectedVersion int64) error
+	// ReleaseTable marks an occupied table free, recording the finished session, and returns
+	// the table's new state
+	ReleaseTable(floorPlanID, tableID string, expectedVersion int64) (*models.TableOccupancy, error)
+	// ListTableSessions returns the finished sessions of a floor plan's tables that overlap
+	// [from, to), oldest first
+	ListTableSessions(floorPlanID string, from, to time.Time) ([]*models.TableSession, error)
+}
+
+// maxMemoryTableSessions bounds the sessions kept per floor plan in memory; the oldest are dropped first
+const maxMemoryTableSessions = 10000
+
+type memoryOccupancyRepository struct {
+	mu       sync.Mutex
+	tables   map[string]map[string]*models.TableOccupancy // floor plan ID -> table ID -> state
+	sessions map[string][]*models.TableSession            // floor plan ID -> finished sessions, oldest first
+}
+
+// NewMemoryOccupancyRepository creates an in-memory occupancy store (state is lost on restart)
+func NewMemoryOccupancyRepository() OccupancyRepository {
+	return &memoryOccupancyRepository{
+		tables:   make(map[string]map[string]*models.TableOccupancy),
+		sessions: make(map[string][]*models.TableSession),
+	}
+}
+
+func (r *memoryOccupancyRepository) ListOccupancy(floorPlanID string) ([]*models.TableOccupancy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tables := make([]*models.TableOccupancy, 0, len(r.tables[floorPlanID]))
+	for _, table := range r.tables[floorPlanID] {
+		copied := *table
+		tables = append(tables, &copied)
+	}
+	return tables, nil
+}
+
+func (r *memoryOccupancyRepository) SeatTable(occupancy *models.TableOccupancy, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.tables[occupancy.FloorPlanID][occupancy.TableID]
+	version := int64(0)
+	if current != nil {
+		version = current.Version
+	}
+	if expectedVersion != 0 && expectedVersion != version {
+		return ErrOccupancyConflict
+	}
+	if current != nil && current.Occupied {
+		return ErrTableOccupied
+	}
+
+	if r.tables[occupancy.FloorPlanID] == nil {
+		r.tables[occupancy.FloorPlanID] = make(map[string]*models.TableOccupancy)
+	}
+	occupancy.Occupied = true
+	occupancy.Version = version + 1
+	stored := *occupancy
+	r.tables[occupancy.FloorPlanID][occupancy.TableID] = &stored
+	return nil
+}
+
+func (r *memoryOccupancyRepository) ReleaseTable(floorPlanID, tableID string, expectedVersion int64) (*models.TableOccupancy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.tables[floorPlanID][tableID]
+	if current == nil {
+		if expectedVersion != 0 {
+			return nil, ErrOccupancyConflict
+		}
+		return nil, ErrTableNotOccupied
+	}
+	if expectedVersion != 0 && expectedVersion != current.Version {
+		return nil, ErrOccupancyConflict
+	}
+	if !current.Occupied {
+		return nil, ErrTableNotOccupied
+	}
+
+	sessions := r.sessions[floorPlanID]
+	if len(sessions) == maxMemoryTableSessions {
+		sessions = sessions[1:]
+	}
+	r.sessions[floorPlanID] = append(sessions, &models.TableSession{
+		FloorPlanID: floorPlanID,
+		TableID:     tableID,
+		GroupID:     current.GroupID,
+		Size:        current.Size,
+		SeatedAt:    current.SeatedAt,
+		ReleasedAt:  time.Now(),
+	})
+
+	*current = models.TableOccupancy{
+		FloorPlanID: floorPlanID,
+		TableID:     tableID,
+		Version:     current.Version + 1,
+	}
+	released := *current
+	return &released, nil
+}
+
+func (r *memoryOccupancyRepository) ListTableSessions(floorPlanID string, from, to time.Time) ([]*models.TableSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sessions []*models.TableSession
+	for _, session := range r.sessions[floorPlanID] {
+		if session.SeatedAt.Before(to) && session.ReleasedAt.After(from) {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// ABC classification limits and defaults
+const (
+	maxABCItems       = 10000
+	defaultAThreshold = 0.8
+	defaultBThreshold = 0.95
+)
+
+// ABCRequest represents a request to classify items by their share of the total value.
+// Thresholds are cumulative value shares: by default A items make up the first 80% of
+// the value, B the next 15% and C the rest
+type ABCRequest struct {
+	Items      []ABCItemRequest `json:"items"`
+	AThreshold float64          `json:"a_threshold,omitempty"` // default 0.8
+	BThreshold float64          `json:"b_threshold,omitempty"` // default 0.95
+}
+
+// ABCItemRequest describes one item. Its value is annual_value when given, otherwise
+// annual_demand × unit_cost
+type ABCItemRequest struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name,omitempty"`
+	AnnualDemand float64 `json:"annual_demand,omitempty"` // units per year
+	UnitCost     float64 `json:"unit_cost,omitempty"`
+	AnnualValue  float64 `json:"annual_value,omitempty"`
+}
+
+// ABCItemResult represents the class of one item
+type ABCItemResult struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name,omitempty"`
+	Class           string  `json:"class"`
+	Rank            int     `json:"rank"` // 1 for the most valuable item
+	AnnualValue     float64 `json:"annual_value"`
+	Share           float64 `json:"share"`            // fraction of the total value
+	CumulativeShare float64 `json:"cumulative_share"` // fraction of the total value up to this item
+}
+
+// ABCClassSummary represents the items and value of one class
+type ABCClassSummary struct {
+	Class      string  `json:"class"`
+	Items      int     `json:"items"`
+	ItemShare  float64 `json:"item_share"` // fraction of all items
+	Value      float64 `json:"value"`
+	ValueShare float64 `json:"value_share"` // fraction of the total value
+}
+
+// ABCResponse represents the classification of a set of items, most valuable first
+type ABCResponse struct {
+	Success    bool              `json:"success"`
+	Items      []ABCItemResult   `json:"items"`
+	Classes    []ABCClassSummary `json:"classes"`
+	TotalValue float64           `json:"total_value"`
+	Errors     []ItemError       `json:"errors,omitempty"` // one entry per invalid item field
+	Message    string            `json:"message"`
+
+	Invalid bool `json:"-"`
+}
+
+// ClassifyInventory sorts items into A, B and C classes by cumulative value contribution
+func (os *OptimizationService) ClassifyInventory(req ABCRequest) ABCResponse {
+	if len(req.Items) == 0 || len(req.Items) > maxABCItems {
+		return ABCResponse{Success: false, Message: fmt.Sprintf("between 1 and %d items are required", maxABCItems)}
+	}
+
+	aThreshold, bThreshold := req.AThreshold, req.BThreshold
+	if aThreshold == 0 {
+		aThreshold = defaultAThreshold
+	}
+	if bThreshold == 0 {
+		bThreshold = defaultBThreshold
+	}
+	if !(aThreshold > 0 && aThreshold <= bThreshold && bThreshold <= 1) {
+		return ABCResponse{Success: false, Message: "thresholds must satisfy 0 < a_threshold <= b_threshold <= 1"}
+	}
+
+	if errs := validateABCItems(req.Items); len(errs) > 0 {
+		return ABCResponse{Success: false, Errors: errs, Message: invalidItemsMessage(errs), Invalid: true}
+	}
+
+	values := make([]float64, len(req.Items))
+	for i, item := range req.Items {
+		values[i] = item.AnnualValue
+		if values[i] == 0 {
+			values[i] = item.AnnualDemand * item.UnitCost
+		}
+	}
+
+	result := algorithms.ClassifyABC(values, aThreshold, bThreshold)
+	if !result.Success {
+		return ABCResponse{Success: false, Message: result.Message}
+	}
+
+	items := make([]ABCItemResult, len(result.Items))
+	summaries := map[string]*ABCClassSummary{
+		algorithms.ClassA: {Class: algorithms.ClassA},
+		algorithms.ClassB: {Class: algorithms.ClassB},
+		algorithms.ClassC: {Class: algorithms.ClassC},
+	}
+	for i, classified := range result.Items {
+		item := req.Items[classified.Index]
+		items[i] = ABCItemResult{
+			ID:              item.ID,
+			Name:            item.Name,
+			Class:           classified.Class,
+			Rank:            classified.Rank,
+			AnnualValue:     values[classified.Index],
+			Share:           classified.Share,
+			CumulativeShare: classified.CumulativeShare,
+		}
+
+		summary := summaries[classified.Class]
+		summary.Items++
+		summary.Value += values[classified.Index]
+	}
+
+	classes := make([]ABCClassSummary, 0, len(summaries))
+	for _, class := range []string{algorithms.ClassA, algorithms.ClassB, algorithms.ClassC} {
+		summary := summaries[class]
+		summary.ItemShare = float64(summary.Items) / float64(len(items))
+		summary.ValueShare = summary.Value / result.TotalValue
+		classes = append(classes, *summary)
+	}
+
+	return ABCResponse{
+		Success:    true,
+		Items:      items,
+		Classes:    classes,
+		TotalValue: result.TotalValue,
+		Message: fmt.Sprintf("%d A, %d B and %d C items", summaries[algorithms.ClassA].Items,
+			summaries[algorithms.ClassB].Items, summaries[algorithms.ClassC].Items),
+	}
+}
+
+// validateABCItems returns every invalid field of the items
+func validateABCItems(items []ABCItemRequest) []ItemError {
+	var errs itemErrors
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		errs.checkID(i, item.ID, seen)
+		errs.checkNonNegative(i, item.ID, "annual_demand", item.AnnualDemand)
+		errs.checkNonNegative(i, item.ID, "unit_cost", item.UnitCost)
+		errs.checkNonNegative(i, item.ID, "annual_value", item.AnnualValue)
+		if item.AnnualValue > 0 && (item.AnnualDemand > 0 || item.UnitCost > 0) {
+			errs.add(i, item.ID, "annual_value", "cannot be given together with annual_demand and unit_cost")
+		}
+	}
+	return errs
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"ms-optimization-go/internal/algorithms"
+	"ms-optimization-go/internal/notify"
+	"time"
+)
+
+// ChangeShortageAlert is the payload of the event sent when a drawer cannot make change
+type ChangeShortageAlert struct {
+	DrawerID     string  `json:"drawer_id,omitempty"` // empty when the contents came with the request
+	Currency     string  `json:"currency"`
+	ChangeAmount float64 `json:"change_amount"`
+	Shortfall    float64 `json:"shortfall"`
+
+	// Denominations the drawer holds too few of to give the optimal change
+	MissingDenominations []string `json:"missing_denominations"`
+	// Pieces to add so the optimal change could be given, in the /drawers/:id/replenish format
+	SuggestedReplenishment map[string]int `json:"suggested_replenishment"`
+
+	Message string `json:"message"`
+}
+
+// alertChangeShortage notifies the configured webhook that change could not be made from held,
+// suggesting the pieces that would have allowed the optimal change
+func (os *OptimizationService) alertChangeShortage(currency Currency, moneyAlgo *algorithms.MoneyChangeAlgorithm, drawerID string, changeAmount int, held map[int]int, result algorithms.ChangeResult) {
+	replenishment := make(map[int]int)
+	if ideal := moneyAlgo.CalculateChangeWithMode(changeAmount, algorithms.ChangeModeAuto); ideal.Success {
+		for coin, quantity := range ideal.Breakdown {
+			if missing := quantity - held[coin]; missing > 0 {
+				replenishment[coin] = missing
+			}
+		}
+	}
+
+	missing := make([]string, 0, len(replenishment))
+	for _, coin := range moneyAlgo.GetAvailableCoins() {
+		if replenishment[coin] > 0 {
+			missing = append(missing, currency.formatDenominationKey(coin))
+		}
+	}
+
+	os.notifier.Notify(notify.Event{
+		Type:       notify.EventChangeShortage,
+		OccurredAt: time.Now().UTC(),
+		Data: ChangeShortageAlert{
+			DrawerID:               drawerID,
+			Currency:               currency.Code,
+			ChangeAmount:           currency.fromMinorUnits(changeAmount),
+			Shortfall:              currency.fromMinorUnits(result.Shortfall),
+			MissingDenominations:   missing,
+			SuggestedReplenishment: currency.formatDrawerContents(replenishment),
+			Message:                result.Message,
+		},
+	})
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Limits for change analytics, which builds tables proportional to the amount
+const (
+	maxAnalysisAmount   = 200000 // minor units
+	defaultAlternatives = 5
+	maxAlternatives     = 20
+)
+
+// ChangeWaysRequest represents a request to analyze the ways of making an amount
+type ChangeWaysRequest struct {
+	Amount          float64   `json:"amount"`
+	Currency        string    `json:"currency,omitempty"`
+	Locale          string    `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+	Denominations   []float64 `json:"denominations,omitempty"`
+	DenominationSet string    `json:"denomination_set,omitempty"`
+	TopN            int       `json:"top_n,omitempty"` // number of alternative breakdowns, default 5
+}
+
+// AlternativeBreakdown represents one way of making an amount
+type AlternativeBreakdown struct {
+	Pieces    int            `json:"pieces"`
+	Breakdown map[string]int `json:"breakdown"`
+}
+
+// ChangeWaysResponse represents the number of ways and the best breakdowns for an amount
+type ChangeWaysResponse struct {
+	Success      bool                   `json:"success"`
+	Currency     string                 `json:"currency"`
+	Amount       float64                `json:"amount"`
+	Ways         *big.Int               `json:"ways"`
+	MinPieces    int                    `json:"min_pieces"`
+	Alternatives []AlternativeBreakdown `json:"alternatives"`
+	Message      string                 `json:"message"`
+}
+
+// CountChangeWays counts the distinct ways of making an amount and lists the top-N breakdowns
+func (os *OptimizationService) CountChangeWays(ctx context.Context, req ChangeWaysRequest) ChangeWaysResponse {
+	if err := validateChangeWaysRequest(req); err != nil {
+		return ChangeWaysResponse{Success: false, Currency: req.Currency, Message: err.Error()}
+	}
+	currency, moneyAlgo, err := os.resolveCurrency(req.Currency, req.DenominationSet, req.Denominations)
+	if err == nil {
+		currency, err = currency.withLocale(req.Locale)
+	}
+	if err != nil {
+		return ChangeWaysResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+
+	amount, err := currency.toMinorUnits(req.Amount)
+	if err != nil {
+		return ChangeWaysResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+	if amount > maxAnalysisAmount {
+		return ChangeWaysResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Amount:   req.Amount,
+			Message:  fmt.Sprintf("Amount exceeds the analysis limit of %s", currency.formatAmount(maxAnalysisAmount)),
+		}
+	}
+
+	topN := req.TopN
+	if topN <= 0 {
+		topN = defaultAlternatives
+	}
+	if topN > maxAlternatives {
+		topN = maxAlternatives
+	}
+
+	ways, err := moneyAlgo.CountWays(ctx, amount)
+	if err != nil {
+		return ChangeWaysResponse{Success: false, Currency: currency.Code, Amount: req.Amount, Message: "Calculation cancelled"}
+	}
+	breakdowns, err := moneyAlgo.TopBreakdowns(ctx, amount, nil, topN)
+	if err != nil {
+		return ChangeWaysResponse{Success: false, Currency: currency.Code, Amount: req.Amount, Message: "Calculation cancelled"}
+	}
+	alternatives := currency.formatAlternatives(breakdowns)
+
+	minPieces := 0
+	if len(alternatives) > 0 {
+		minPieces = alternatives[0].Pieces
+	}
+
+	return ChangeWaysResponse{
+		Success:      true,
+		Currency:     currency.Code,
+		Amount:       currency.fromMinorUnits(amount),
+		Ways:         ways,
+		MinPieces:    minPieces,
+		Alternatives: alternatives,
+		Message:      fmt.Sprintf("%s can be made in %s ways", currency.formatAmount(amount), ways.String()),
+	}
+}
+
+// validateChangeWaysRequest checks a change ways request before anything is counted
+func validateChangeWaysRequest(req ChangeWaysRequest) error {
+	if req.Amount < 0 || req.TopN < 0 {
+		return errors.New("amount and top_n must be non-negative")
+	}
+	return validateCurrencyCode(req.Currency)
+}
+
+// formatAlternatives converts algorithm breakdowns to the response format
+func (c Currency) formatAlternatives(breakdowns []algorithms.Breakdown) []AlternativeBreakdown {
+	alternatives := make([]AlternativeBreakdown, len(breakdowns))
+	for i, breakdown := range breakdowns {
+		alternatives[i] = AlternativeBreakdown{
+			Pieces:    breakdown.Pieces,
+			Breakdown: c.formatBreakdown(breakdown.Coins),
+		}
+	}
+	return alternatives
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Assignment constraint types
+const (
+	ConstraintMaxDistance       = "max_distance"       // the table is within max_distance of a point
+	ConstraintRequiredZone      = "required_zone"      // the table is in a zone
+	ConstraintRequiredAttribute = "required_attribute" // the table has an attribute, e.g. quiet
+	ConstraintCapacitySlack     = "capacity_slack"     // the group leaves at most max_empty_seats empty
+)
+
+// Assignment constraint modes
+const (
+	ConstraintHard = "hard"
+	ConstraintSoft = "soft"
+)
+
+// Limits of the constraints block of a request
+const (
+	maxAssignmentConstraints = 100
+	minConstraintPenalty     = 0.01
+	maxConstraintPenalty     = 1000000
+)
+
+// AssignmentConstraint restricts the tables groups may be seated at. A hard constraint
+// rules out the tables that break it; a soft one never leaves a group unseated and only
+// costs its penalty, the minutes of extra wait worth spending to keep it. Every solver reads
+// it the same way: where the seatings it compares differ in wait (waitlist, evening plan) the
+// penalty is added to the wait, and where they do not (stable matching, event seating) the
+// lowest total penalty comes before the solver's own objective. A group's required zones and
+// attributes are hard constraints naming it, its preferred ones soft constraints weighted by
+// their penalty. Groups that need wheelchair access are only ever seated at accessible
+// tables; a soft max_distance constraint from the entrance keeps them near it
+type AssignmentConstraint struct {
+	ID            string   `json:"id,omitempty"`
+	Type          string   `json:"type"`                // max_distance, required_zone, required_attribute or capacity_slack
+	Mode          string   `json:"mode,omitempty"`      // hard (default) or soft
+	Penalty       float64  `json:"penalty,omitempty"`   // minutes of wait worth spending to keep a soft constraint, default 1
+	Groups        []string `json:"groups,omitempty"`    // groups it applies to, default all
+	Zone          string   `json:"zone,omitempty"`      // for required_zone
+	Attribute     string   `json:"attribute,omitempty"` // for required_attribute
+	X             float64  `json:"x,omitempty"`         // for max_distance, the point tables are measured from
+	Y             float64  `json:"y,omitempty"`
+	MaxDistance   float64  `json:"max_distance,omitempty"`
+	MaxEmptySeats int      `json:"max_empty_seats,omitempty"` // for capacity_slack
+}
+
+// ConstraintViolation represents a soft constraint broken by a seating
+type ConstraintViolation struct {
+	Constraint int     `json:"constraint"` // index in the request's constraints
+	ID         string  `json:"id,omitempty"`
+	Type       string  `json:"type"`
+	Penalty    float64 `json:"penalty"`
+}
+
+// constraintTable is what the constraints look at in a table
+type constraintTable struct {
+	Capacity   int
+	Zone       string
+	Attributes []string
+	X, Y       float64
+	Accessible bool // wheelchair accessible
+}
+
+// assignmentConstraints is a validated constraints block for a list of groups and tables
+type assignmentConstraints struct {
+	list    []AssignmentConstraint
+	applies [][]bool // [constraint][group]
+	access  []bool   // [group] needs a wheelchair-accessible table, nil for none
+	tables  []constraintTable
+	near    [][]bool // [constraint][table] for max_distance constraints
+}
+
+// compileConstraints validates a constraints block against the groups it names, and finds
+// the tables near each max_distance point through a spatial index rather than by measuring
+// every table. access, nil for none, marks the groups that need a wheelchair-accessible table
+func compileConstraints(constraints []AssignmentConstraint, groupIDs []string, access []bool, tables []constraintTable) (*assignmentConstraints, error) {
+	if len(constraints) > maxAssignmentConstraints {
+		return nil, fmt.Errorf("at most %d constraints are allowed", maxAssignmentConstraints)
+	}
+	groupIndex := make(map[string]int, len(groupIDs))
+	for i, id := range groupIDs {
+		groupIndex[id] = i
+	}
+
+	compiled := &assignmentConstraints{
+		list:    make([]AssignmentConstraint, len(constraints)),
+		applies: make([][]bool, len(constraints)),
+		access:  access,
+		tables:  tables,
+		near:    make([][]bool, len(constraints)),
+	}
+	var index *algorithms.KDTree
+	for i, constraint := range constraints {
+		switch constraint.Type {
+		case ConstraintRequiredZone:
+			if constraint.Zone == "" {
+				return nil, fmt.Errorf("constraint %d: zone is required", i)
+			}
+		case ConstraintRequiredAttribute:
+			if constraint.Attribute == "" {
+				return nil, fmt.Errorf("constraint %d: attribute is required", i)
+			}
+		case ConstraintMaxDistance:
+			if !(constraint.MaxDistance > 0) || math.IsInf(constraint.MaxDistance, 0) || math.IsNaN(constraint.X) || math.IsNaN(constraint.Y) {
+				return nil, fmt.Errorf("constraint %d: max_distance must be a positive number", i)
+			}
+			if index == nil {
+				points := make([]algorithms.Point, len(tables))
+				for t, table := range tables {
+					points[t] = algorithms.Point{X: table.X, Y: table.Y}
+				}
+				index = algorithms.NewKDTree(points)
+			}
+			compiled.near[i] = make([]bool, len(tables))
+			for _, t := range index.Within(algorithms.Point{X: constraint.X, Y: constraint.Y}, constraint.MaxDistance) {
+				compiled.near[i][t] = true
+			}
+		case ConstraintCapacitySlack:
+			if constraint.MaxEmptySeats < 0 {
+				return nil, fmt.Errorf("constraint %d: max_empty_seats must not be negative", i)
+			}
+		default:
+			return nil, fmt.Errorf("constraint %d: invalid type %q, expected %s, %s, %s or %s", i, constraint.Type, ConstraintMaxDistance, ConstraintRequiredZone, ConstraintRequiredAttribute, ConstraintCapacitySlack)
+		}
+
+		switch constraint.Mode {
+		case "", ConstraintHard:
+			if constraint.Penalty != 0 {
+				return nil, fmt.Errorf("constraint %d: penalty only applies to soft constraints", i)
+			}
+			constraint.Mode = ConstraintHard
+		case ConstraintSoft:
+			if constraint.Penalty == 0 {
+				constraint.Penalty = 1
+			}
+			if !(constraint.Penalty >= minConstraintPenalty) || constraint.Penalty > maxConstraintPenalty {
+				return nil, fmt.Errorf("constraint %d: penalty must be between %g and %d", i, minConstraintPenalty, maxConstraintPenalty)
+			}
+		default:
+			return nil, fmt.Errorf("constraint %d: invalid mode %q, expected %s or %s", i, constraint.Mode, ConstraintHard, ConstraintSoft)
+		}
+
+		applies := make([]bool, len(groupIDs))
+		for g := range applies {
+			applies[g] = len(constraint.Groups) == 0
+		}
+		for _, id := range constraint.Groups {
+			g, ok := groupIndex[id]
+			if !ok {
+				return nil, fmt.Errorf("constraint %d: unknown group %q", i, id)
+			}
+			applies[g] = true
+		}
+		compiled.list[i], compiled.applies[i] = constraint, applies
+	}
+	return compiled, nil
+}
+
+// broken reports whether seating a group of the given size at table t breaks constraint i
+func (ac *assignmentConstraints) broken(i int, size int, t int) bool {
+	constraint := ac.list[i]
+	switch constraint.Type {
+	case ConstraintRequiredZone:
+		return ac.tables[t].Zone != constraint.Zone
+	case ConstraintRequiredAttribute:
+		for _, attribute := range ac.tables[t].Attributes {
+			if attribute == constraint.Attribute {
+				return false
+			}
+		}
+		return true
+	case ConstraintMaxDistance:
+		return !ac.near[i][t]
+	case ConstraintCapacitySlack:
+		return ac.tables[t].Capacity-size > constraint.MaxEmptySeats
+	}
+	return false
+}
+
+// penalties returns the penalty of seating each group at each table, +Inf where a hard
+// constraint or the group's need for wheelchair access rules the table out, or nil when
+// nothing does
+func (ac *assignmentConstraints) penalties(sizes []int) [][]float64 {
+	needsAccess := false
+	for _, access := range ac.access {
+		needsAccess = needsAccess || access
+	}
+	if len(ac.list) == 0 && !needsAccess {
+		return nil
+	}
+	penalty := make([][]float64, len(sizes))
+	algorithms.ParallelRows(len(sizes), len(sizes)*len(ac.tables)*len(ac.list), func(g int) {
+		size := sizes[g]
+		penalty[g] = make([]float64, len(ac.tables))
+		for t := range ac.tables {
+			if ac.access != nil && ac.access[g] && !ac.tables[t].Accessible {
+				penalty[g][t] = math.Inf(1)
+				continue
+			}
+			for i, constraint := range ac.list {
+				if !ac.applies[i][g] || !ac.broken(i, size, t) {
+					continue
+				}
+				if constraint.Mode == ConstraintHard {
+					penalty[g][t] = math.Inf(1)
+					break
+				}
+				penalty[g][t] += constraint.Penalty
+			}
+		}
+	})
+	return penalty
+}
+
+// violations lists the soft constraints broken by seating group g at table t
+func (ac *assignmentConstraints) violations(g, size, t int) []ConstraintViolation {
+	var violations []ConstraintViolation
+	for i, constraint := range ac.list {
+		if constraint.Mode == ConstraintSoft && ac.applies[i][g] && ac.broken(i, size, t) {
+			violations = append(violations, ConstraintViolation{Constraint: i, ID: constraint.ID, Type: constraint.Type, Penalty: constraint.Penalty})
+		}
+	}
+	return violations
+}
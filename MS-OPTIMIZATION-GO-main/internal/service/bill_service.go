@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Bill split methods
+const (
+	SplitEven         = "even"
+	SplitProportional = "proportional"
+	SplitItems        = "items"
+)
+
+// Limits applied to bill splitting requests
+const (
+	maxBillGuests = 50
+	maxBillItems  = 500
+)
+
+// SplitBillRequest represents a request to split an order among the guests at a table
+type SplitBillRequest struct {
+	Method string `json:"method,omitempty"` // even (default), proportional, items
+
+	// Order total; for the items method it is optional and checked against the items
+	Total      float64 `json:"total,omitempty"`
+	TotalCents *int64  `json:"total_cents,omitempty"` // exact total in minor units, overrides total
+
+	Currency        string    `json:"currency,omitempty"`
+	Locale          string    `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+	Denominations   []float64 `json:"denominations,omitempty"`
+	DenominationSet string    `json:"denomination_set,omitempty"`
+
+	// Guests at the table; guest_count creates anonymous guests instead
+	Guests     []BillGuest `json:"guests,omitempty"`
+	GuestCount int         `json:"guest_count,omitempty"`
+
+	// Order lines for the items method
+	Items []BillItemRequest `json:"items,omitempty"`
+
+	// Largest amount a guest may pay above or below the equal share so amounts are easy to
+	// pay in cash (even method); defaults to half the smallest bill
+	MaxAdjustment float64 `json:"max_adjustment,omitempty"`
+}
+
+// BillGuest describes a guest taking part in a bill split
+type BillGuest struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name,omitempty"`
+	Weight *float64 `json:"weight,omitempty"` // proportional method, default 1
+}
+
+// BillItemRequest describes an order line and the guests sharing it
+type BillItemRequest struct {
+	Name       string   `json:"name,omitempty"`
+	Price      float64  `json:"price"`
+	PriceCents *int64   `json:"price_cents,omitempty"` // exact unit price in minor units, overrides price
+	Quantity   int      `json:"quantity,omitempty"`    // default 1
+	Guests     []string `json:"guests,omitempty"`      // guest ids; empty means shared by everyone
+}
+
+// GuestShareResponse represents what one guest pays and how it deviates from an equal share
+type GuestShareResponse struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name,omitempty"`
+	Amount      float64 `json:"amount"`
+	AmountCents int     `json:"amount_cents"`
+	FairShare   float64 `json:"fair_share"`
+	Adjustment  float64 `json:"adjustment"`
+	Pieces      int     `json:"pieces,omitempty"`
+}
+
+// SplitBillResponse represents an order split among guests with its adjustment ledger
+type SplitBillResponse struct {
+	Success   bool                 `json:"success"`
+	Method    string               `json:"method"`
+	Currency  string               `json:"currency"`
+	Total     float64              `json:"total"`
+	Shares    []GuestShareResponse `json:"shares"`
+	Remainder float64              `json:"remainder,omitempty"`
+	Message   string               `json:"message"`
+}
+
+// SplitBill splits an order among guests evenly, proportionally or by assigned items
+func (os *OptimizationService) SplitBill(ctx context.Context, req SplitBillRequest) SplitBillResponse {
+	method := req.Method
+	if method == "" {
+		method = SplitEven
+	}
+	if err := validateSplitBillRequest(req); err != nil {
+		return SplitBillResponse{Success: false, Method: req.Method, Currency: req.Currency, Message: err.Error()}
+	}
+
+	currency, moneyAlgo, err := os.resolveCurrency(req.Currency, req.DenominationSet, req.Denominations)
+	if err != nil && method == SplitEven {
+		return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: err.Error()}
+	}
+	if currency, err = currency.withLocale(req.Locale); err != nil {
+		return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: err.Error()}
+	}
+
+	guests, err := billGuests(req)
+	if err != nil {
+		return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: err.Error()}
+	}
+
+	hasTotal := req.TotalCents != nil || req.Total != 0
+	if !hasTotal && method != SplitItems {
+		return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: "total is required"}
+	}
+
+	total := 0
+	if hasTotal {
+		if total, err = currency.amountToMinorUnits(req.Total, req.TotalCents); err != nil {
+			return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: fmt.Sprintf("invalid total: %v", err)}
+		}
+		if total < 0 {
+			return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: "total must be non-negative"}
+		}
+	}
+
+	var result algorithms.BillSplitResult
+	switch method {
+	case SplitEven:
+		maxAdjustment := currency.SmallestBill / 2
+		if req.MaxAdjustment != 0 {
+			if maxAdjustment, err = currency.toMinorUnits(req.MaxAdjustment); err != nil || maxAdjustment < 0 {
+				return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: "max_adjustment must be a non-negative amount"}
+			}
+		}
+		result = moneyAlgo.SplitEvenly(ctx, total, len(guests), maxAdjustment)
+	case SplitProportional:
+		weights := make([]float64, len(guests))
+		for i, guest := range guests {
+			weights[i] = 1
+			if guest.Weight != nil {
+				weights[i] = *guest.Weight
+			}
+			if weights[i] < 0 || math.IsNaN(weights[i]) || math.IsInf(weights[i], 0) {
+				return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: fmt.Sprintf("weight for %s must be a non-negative number", guest.ID)}
+			}
+		}
+		result = algorithms.SplitProportional(total, weights)
+	case SplitItems:
+		items, itemsTotal, err := currency.billItems(req.Items, guests)
+		if err != nil {
+			return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: err.Error()}
+		}
+		if hasTotal && total != itemsTotal {
+			return SplitBillResponse{
+				Success:  false,
+				Method:   method,
+				Currency: currency.Code,
+				Message: fmt.Sprintf("items add up to %s but the total is %s",
+					currency.formatAmount(itemsTotal), currency.formatAmount(total)),
+			}
+		}
+		total = itemsTotal
+		result = algorithms.SplitByItems(items, len(guests))
+	default:
+		return SplitBillResponse{Success: false, Method: method, Currency: currency.Code, Message: fmt.Sprintf("invalid split method %q", method)}
+	}
+
+	if !result.Success {
+		return SplitBillResponse{
+			Success:  false,
+			Method:   method,
+			Currency: currency.Code,
+			Total:    currency.fromMinorUnits(total),
+			Message:  result.Message,
+		}
+	}
+
+	shares := make([]GuestShareResponse, len(guests))
+	for i, guest := range guests {
+		fairShare := result.Amounts[i]
+		if result.FairShares != nil {
+			fairShare = result.FairShares[i]
+		}
+		shares[i] = GuestShareResponse{
+			ID:          guest.ID,
+			Name:        guest.Name,
+			Amount:      currency.fromMinorUnits(result.Amounts[i]),
+			AmountCents: result.Amounts[i],
+			FairShare:   currency.fromMinorUnits(fairShare),
+			Adjustment:  currency.fromMinorUnits(result.Amounts[i] - fairShare),
+		}
+		if result.Pieces != nil {
+			shares[i].Pieces = result.Pieces[i]
+		}
+	}
+
+	return SplitBillResponse{
+		Success:   true,
+		Method:    method,
+		Currency:  currency.Code,
+		Total:     currency.fromMinorUnits(total),
+		Shares:    shares,
+		Remainder: currency.fromMinorUnits(result.Remainder),
+		Message:   result.Message,
+	}
+}
+
+// validateSplitBillRequest checks a bill split request before anything is split
+func validateSplitBillRequest(req SplitBillRequest) error {
+	if req.Method != "" && req.Method != SplitEven && req.Method != SplitProportional && req.Method != SplitItems {
+		return fmt.Errorf("invalid split method %q, expected %s, %s or %s", req.Method, SplitEven, SplitProportional, SplitItems)
+	}
+	if req.Total < 0 || (req.TotalCents != nil && *req.TotalCents < 0) || req.GuestCount < 0 || req.MaxAdjustment < 0 {
+		return errors.New("total, guest_count and max_adjustment must be non-negative")
+	}
+	return validateCurrencyCode(req.Currency)
+}
+
+// billGuests validates the guests of a bill split, creating anonymous guests from guest_count
+func billGuests(req SplitBillRequest) ([]BillGuest, error) {
+	guests := req.Guests
+	if len(guests) == 0 {
+		if req.GuestCount <= 0 || req.GuestCount > maxBillGuests {
+			return nil, fmt.Errorf("between 1 and %d guests are required", maxBillGuests)
+		}
+		guests = make([]BillGuest, req.GuestCount)
+		for i := range guests {
+			guests[i] = BillGuest{ID: fmt.Sprintf("guest-%d", i+1)}
+		}
+		return guests, nil
+	}
+
+	if len(guests) > maxBillGuests {
+		return nil, fmt.Errorf("between 1 and %d guests are required", maxBillGuests)
+	}
+	seen := make(map[string]bool, len(guests))
+	for i, guest := range guests {
+		if guest.ID == "" {
+			return nil, fmt.Errorf("guest %d has no id", i)
+		}
+		if seen[guest.ID] {
+			return nil, fmt.Errorf("duplicate guest id %q", guest.ID)
+		}
+		seen[guest.ID] = true
+	}
+
+	return guests, nil
+}
+
+// billItems validates order lines and converts them to algorithm input, returning the order total
+func (c Currency) billItems(items []BillItemRequest, guests []BillGuest) ([]algorithms.BillItem, int, error) {
+	if len(items) == 0 || len(items) > maxBillItems {
+		return nil, 0, fmt.Errorf("between 1 and %d items are required", maxBillItems)
+	}
+
+	index := make(map[string]int, len(guests))
+	for i, guest := range guests {
+		index[guest.ID] = i
+	}
+
+	total := 0
+	result := make([]algorithms.BillItem, len(items))
+	for i, item := range items {
+		price, err := c.amountToMinorUnits(item.Price, item.PriceCents)
+		if err != nil {
+			return nil, 0, fmt.Errorf("item %d: %w", i, err)
+		}
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if price < 0 || quantity < 0 || quantity > maxDrawerCount {
+			return nil, 0, fmt.Errorf("item %d must have a non-negative price and quantity", i)
+		}
+
+		sharers := make([]int, 0, len(item.Guests))
+		shared := make(map[int]bool, len(item.Guests))
+		for _, id := range item.Guests {
+			g, ok := index[id]
+			if !ok {
+				return nil, 0, fmt.Errorf("item %d is assigned to unknown guest %q", i, id)
+			}
+			if shared[g] {
+				return nil, 0, fmt.Errorf("item %d lists guest %q twice", i, id)
+			}
+			shared[g] = true
+			sharers = append(sharers, g)
+		}
+
+		if quantity > 0 && price > (maxMinorUnits-total)/quantity {
+			return nil, 0, fmt.Errorf("order total exceeds the maximum of %d minor units", int64(maxMinorUnits))
+		}
+		amount := price * quantity
+		total += amount
+		result[i] = algorithms.BillItem{Amount: amount, Guests: sharers}
+	}
+
+	return result, total, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"ms-optimization-go/internal/algorithms"
+	"ms-optimization-go/internal/models"
+	"strings"
+	"time"
+)
+
+// Change history pagination
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 200
+)
+
+// ChangeHistoryRequest filters and paginates the change audit log
+type ChangeHistoryRequest struct {
+	TerminalID string `form:"terminal_id"`
+	DrawerID   string `form:"drawer_id"`
+	Currency   string `form:"currency"`
+	Success    *bool  `form:"success"`
+	From       string `form:"from"` // RFC 3339, inclusive
+	To         string `form:"to"`   // RFC 3339, exclusive
+	Page       int    `form:"page"` // 1-based, default 1
+	PageSize   int    `form:"page_size"`
+}
+
+// ChangeRecordResponse represents one logged change calculation
+type ChangeRecordResponse struct {
+	ID           int64          `json:"id"`
+	TerminalID   string         `json:"terminal_id,omitempty"`
+	DrawerID     string         `json:"drawer_id,omitempty"`
+	Currency     string         `json:"currency"`
+	AmountPaid   float64        `json:"amount_paid"`
+	TotalCost    float64        `json:"total_cost"`
+	ChangeAmount float64        `json:"change_amount"`
+	Breakdown    map[string]int `json:"breakdown"`
+	DrawerBefore map[string]int `json:"drawer_before,omitempty"` // denomination (e.g. "0.25") -> quantity
+	Algorithm    string         `json:"algorithm,omitempty"`
+	Success      bool           `json:"success"`
+	Message      string         `json:"message"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// ChangeHistoryResponse represents a page of the change audit log, newest first
+type ChangeHistoryResponse struct {
+	Success  bool                   `json:"success"`
+	Records  []ChangeRecordResponse `json:"records"`
+	Total    int                    `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+	Message  string                 `json:"message"`
+
+	StoreFailed bool `json:"-"`
+}
+
+// GetChangeHistory returns the logged change calculations matching the request filters
+func (os *OptimizationService) GetChangeHistory(req ChangeHistoryRequest) ChangeHistoryResponse {
+	page, pageSize := req.Page, req.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if page < 1 || pageSize < 1 || pageSize > maxHistoryPageSize {
+		return ChangeHistoryResponse{Success: false, Message: fmt.Sprintf("page must be positive and page_size between 1 and %d", maxHistoryPageSize)}
+	}
+
+	filter := models.ChangeRecordFilter{
+		TerminalID: req.TerminalID,
+		DrawerID:   req.DrawerID,
+		Currency:   strings.ToUpper(req.Currency),
+		Success:    req.Success,
+		Limit:      pageSize,
+		Offset:     (page - 1) * pageSize,
+	}
+	var err error
+	if req.From != "" {
+		if filter.From, err = time.Parse(time.RFC3339, req.From); err != nil {
+			return ChangeHistoryResponse{Success: false, Message: "from must be an RFC 3339 timestamp"}
+		}
+	}
+	if req.To != "" {
+		if filter.To, err = time.Parse(time.RFC3339, req.To); err != nil {
+			return ChangeHistoryResponse{Success: false, Message: "to must be an RFC 3339 timestamp"}
+		}
+	}
+
+	records, total, err := os.changeLogRepo.ListChangeRecords(filter)
+	if err != nil {
+		return ChangeHistoryResponse{Success: false, Message: fmt.Sprintf("failed to read change history: %v", err), StoreFailed: true}
+	}
+
+	responses := make([]ChangeRecordResponse, len(records))
+	for i, record := range records {
+		responses[i] = os.newChangeRecordResponse(record)
+	}
+
+	return ChangeHistoryResponse{
+		Success:  true,
+		Records:  responses,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		Message:  fmt.Sprintf("%d of %d change calculations", len(responses), total),
+	}
+}
+
+// recordChange adds a change calculation to the audit log. A failure to log is reported
+// but does not fail the calculation, since the cash has already been handled
+func (os *OptimizationService) recordChange(req CalculateChangeRequest, currency Currency, amountPaid, totalCost, changeAmount int, drawerBefore map[int]int, algorithm string, result algorithms.ChangeResult) {
+	breakdown := result.Breakdown
+	if breakdown == nil {
+		breakdown = make(map[int]int)
+	}
+
+	record := &models.ChangeRecord{
+		TerminalID:   req.TerminalID,
+		DrawerID:     req.DrawerID,
+		Currency:     currency.Code,
+		AmountPaid:   amountPaid,
+		TotalCost:    totalCost,
+		ChangeAmount: changeAmount,
+		Breakdown:    breakdown,
+		DrawerBefore: drawerBefore,
+		Algorithm:    algorithm,
+		Success:      result.Success,
+		Message:      result.Message,
+	}
+	if err := os.changeLogRepo.AddChangeRecord(record); err != nil {
+		log.Printf("failed to record change calculation: %v", err)
+	}
+}
+
+// newChangeRecordResponse formats a logged change calculation in its currency
+func (os *OptimizationService) newChangeRecordResponse(record *models.ChangeRecord) ChangeRecordResponse {
+	currency, _ := os.lookupCurrency(record.Currency)
+
+	var drawerBefore map[string]int
+	if record.DrawerBefore != nil {
+		drawerBefore = currency.formatDrawerContents(record.DrawerBefore)
+	}
+
+	return ChangeRecordResponse{
+		ID:           record.ID,
+		TerminalID:   record.TerminalID,
+		DrawerID:     record.DrawerID,
+		Currency:     record.Currency,
+		AmountPaid:   currency.fromMinorUnits(record.AmountPaid),
+		TotalCost:    currency.fromMinorUnits(record.TotalCost),
+		ChangeAmount: currency.fromMinorUnits(record.ChangeAmount),
+		Breakdown:    currency.formatBreakdown(record.Breakdown),
+		DrawerBefore: drawerBefore,
+		Algorithm:    record.Algorithm,
+		Success:      record.Success,
+		Message:      record.Message,
+		CreatedAt:    record.CreatedAt,
+	}
+}
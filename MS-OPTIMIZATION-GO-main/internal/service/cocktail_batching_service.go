@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Cocktail batching limits
+const (
+	maxBatchCocktails   = 20
+	maxCocktailParts    = 10
+	maxBatchIngredients = 100
+	maxCocktailServing  = 1000
+	maxCocktailServings = 100000
+)
+
+// CocktailBatchRequest represents the cocktail mixes to pre-batch and the ingredient stock to
+// make them from
+type CocktailBatchRequest struct {
+	Cocktails   []CocktailRecipe  `json:"cocktails"`
+	Ingredients []IngredientStock `json:"ingredients"`
+}
+
+// CocktailRecipe describes a cocktail's mix as parts of each ingredient
+type CocktailRecipe struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name,omitempty"`
+	ServingMl   float64      `json:"serving_ml"` // mix poured per drink
+	Ingredients []RecipePart `json:"ingredients"`
+	Weight      float64      `json:"weight,omitempty"`       // worth of a drink against the others, default 1
+	MinServings float64      `json:"min_servings,omitempty"` // drinks that must be batched
+	MaxServings float64      `json:"max_servings,omitempty"` // drinks worth batching, e.g. forecast demand, 0 for no limit
+}
+
+// RecipePart describes an ingredient's share of a mix. A 2:1:1 recipe has parts 2, 1 and 1
+type RecipePart struct {
+	Ingredient string  `json:"ingredient"`
+	Parts      float64 `json:"parts"`
+	Tolerance  float64 `json:"tolerance,omitempty"` // the share may vary by this fraction of itself, e.g. 0.1
+}
+
+// IngredientStock describes how much of an ingredient is on hand
+type IngredientStock struct {
+	ID      string  `json:"id"`
+	StockMl float64 `json:"stock_ml"`
+}
+
+// CocktailBatch represents the mix to prepare for one cocktail
+type CocktailBatch struct {
+	CocktailID    string              `json:"cocktail_id"`
+	Name          string              `json:"name,omitempty"`
+	Servings      float64             `json:"servings"`
+	WholeServings int                 `json:"whole_servings"`
+	VolumeMl      float64             `json:"volume_ml"`
+	Ingredients   []CocktailBatchPart `json:"ingredients"`
+}
+
+// CocktailBatchPart represents one ingredient of a batch
+type CocktailBatchPart struct {
+	Ingredient string  `json:"ingredient"`
+	VolumeMl   float64 `json:"volume_ml"`
+	Share      float64 `json:"share"`
+}
+
+// IngredientUsage represents how much of an ingredient the batches use
+type IngredientUsage struct {
+	Ingredient  string  `json:"ingredient"`
+	StockMl     float64 `json:"stock_ml"`
+	UsedMl      float64 `json:"used_ml"`
+	RemainingMl float64 `json:"remaining_ml"`
+	ValuePerMl  float64 `json:"value_per_ml"` // weighted drinks one more ml would add, 0 unless it runs out
+}
+
+// CocktailBatchResponse represents the batch plan
+type CocktailBatchResponse struct {
+	Success       bool              `json:"success"`
+	Batches       []CocktailBatch   `json:"batches"`
+	Ingredients   []IngredientUsage `json:"ingredients"`
+	TotalServings float64           `json:"total_servings"`
+	Message       string            `json:"message"`
+}
+
+// PlanCocktailBatches decides how much of each cocktail mix to pre-batch from the stock on
+// hand to serve the most drinks, weighted by each cocktail's worth. It is a linear program
+// over the volume of each mix and the millilitres of its parts with a tolerance: the stock
+// bounds what all mixes use, and each ingredient keeps its share of its mix, within its
+// tolerance
+func (os *OptimizationService) PlanCocktailBatches(ctx context.Context, req CocktailBatchRequest) CocktailBatchResponse {
+	if len(req.Cocktails) == 0 || len(req.Cocktails) > maxBatchCocktails {
+		return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("between 1 and %d cocktails are required", maxBatchCocktails)}
+	}
+	if len(req.Ingredients) == 0 || len(req.Ingredients) > maxBatchIngredients {
+		return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("between 1 and %d ingredients are required", maxBatchIngredients)}
+	}
+
+	ingredientIndex := make(map[string]int, len(req.Ingredients))
+	for i, ingredient := range req.Ingredients {
+		if ingredient.ID == "" {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("ingredient %d has no id", i)}
+		}
+		if _, ok := ingredientIndex[ingredient.ID]; ok {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("duplicate ingredient id %q", ingredient.ID)}
+		}
+		ingredientIndex[ingredient.ID] = i
+		if !(ingredient.StockMl >= 0) || math.IsInf(ingredient.StockMl, 0) {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("stock_ml of ingredient %s must be a non-negative number", ingredient.ID)}
+		}
+	}
+
+	seen := make(map[string]bool, len(req.Cocktails))
+	for k, cocktail := range req.Cocktails {
+		if cocktail.ID == "" {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("cocktail %d has no id", k)}
+		}
+		if seen[cocktail.ID] {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("duplicate cocktail id %q", cocktail.ID)}
+		}
+		seen[cocktail.ID] = true
+		if !(cocktail.ServingMl > 0) || cocktail.ServingMl > maxCocktailServing {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("serving_ml of cocktail %s must be above 0 and at most %d", cocktail.ID, maxCocktailServing)}
+		}
+		if !(cocktail.Weight >= 0) || math.IsInf(cocktail.Weight, 0) {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("weight of cocktail %s must be a non-negative number", cocktail.ID)}
+		}
+		if !(cocktail.MinServings >= 0) || !(cocktail.MaxServings >= 0) || cocktail.MaxServings > maxCocktailServings || cocktail.MinServings > maxCocktailServings {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("min_servings and max_servings of cocktail %s must be between 0 and %d", cocktail.ID, maxCocktailServings)}
+		}
+		if cocktail.MaxServings > 0 && cocktail.MinServings > cocktail.MaxServings {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("min_servings of cocktail %s is above its max_servings", cocktail.ID)}
+		}
+		if len(cocktail.Ingredients) == 0 || len(cocktail.Ingredients) > maxCocktailParts {
+			return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("cocktail %s needs between 1 and %d ingredients", cocktail.ID, maxCocktailParts)}
+		}
+		inRecipe := make(map[string]bool, len(cocktail.Ingredients))
+		for _, part := range cocktail.Ingredients {
+			if _, ok := ingredientIndex[part.Ingredient]; !ok {
+				return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("cocktail %s uses unknown ingredient %q", cocktail.ID, part.Ingredient)}
+			}
+			if inRecipe[part.Ingredient] {
+				return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("cocktail %s lists ingredient %s twice", cocktail.ID, part.Ingredient)}
+			}
+			inRecipe[part.Ingredient] = true
+			if !(part.Parts > 0) || math.IsInf(part.Parts, 0) {
+				return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("parts of %s in cocktail %s must be a positive number", part.Ingredient, cocktail.ID)}
+			}
+			if !(part.Tolerance >= 0 && part.Tolerance < 1) {
+				return CocktailBatchResponse{Success: false, Message: fmt.Sprintf("tolerance of %s in cocktail %s must be at least 0 and below 1", part.Ingredient, cocktail.ID)}
+			}
+		}
+	}
+
+	// Variable k is the volume of cocktail k's batch. A part with a tolerance gets a variable of
+	// its own, parts[k][p], for its ml; the rest are fixed at their share of the volume, which
+	// keeps exact recipes from adding rows
+	n := len(req.Cocktails)
+	parts := make([][]int, len(req.Cocktails))
+	shares := make([][]float64, len(req.Cocktails))
+	for k, cocktail := range req.Cocktails {
+		totalParts := 0.0
+		for _, part := range cocktail.Ingredients {
+			totalParts += part.Parts
+		}
+		parts[k] = make([]int, len(cocktail.Ingredients))
+		shares[k] = make([]float64, len(cocktail.Ingredients))
+		for p, part := range cocktail.Ingredients {
+			shares[k][p] = part.Parts / totalParts
+			parts[k][p] = -1
+			if part.Tolerance > 0 && len(cocktail.Ingredients) > 1 {
+				parts[k][p] = n
+				n++
+			}
+		}
+	}
+	partML := func(x []float64, k, p int) float64 {
+		if j := parts[k][p]; j >= 0 {
+			return x[j]
+		}
+		return shares[k][p] * x[k]
+	}
+
+	problem := algorithms.LPProblem{
+		Objective: make([]float64, n),
+		Maximize:  true,
+		Lower:     make([]float64, n),
+		Upper:     make([]float64, n),
+	}
+	for j := range problem.Upper {
+		problem.Upper[j] = math.Inf(1)
+	}
+	row := func() []float64 { return make([]float64, n) }
+	// addPart adds the ml of cocktail k's part p to a row's coefficients
+	addPart := func(coefficients []float64, k, p int, scale float64) {
+		if j := parts[k][p]; j >= 0 {
+			coefficients[j] += scale
+		} else {
+			coefficients[k] += scale * shares[k][p]
+		}
+	}
+
+	// Stock rows come first, so their duals line up with the ingredients
+	stock := make([][]float64, len(req.Ingredients))
+	for i := range stock {
+		stock[i] = row()
+	}
+	for k, cocktail := range req.Cocktails {
+		for p, part := range cocktail.Ingredients {
+			addPart(stock[ingredientIndex[part.Ingredient]], k, p, 1)
+		}
+	}
+	for i, ingredient := range req.Ingredients {
+		problem.Constraints = append(problem.Constraints, algorithms.LPConstraint{Coefficients: stock[i], Sense: algorithms.LPLessEqual, RHS: ingredient.StockMl})
+	}
+
+	for k, cocktail := range req.Cocktails {
+		weight := cocktail.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		problem.Objective[k] = weight / cocktail.ServingMl
+		problem.Lower[k] = cocktail.MinServings * cocktail.ServingMl
+		if cocktail.MaxServings > 0 {
+			problem.Upper[k] = cocktail.MaxServings * cocktail.ServingMl
+		}
+
+		// A part with a tolerance stays within share · (1 ± tolerance) of the volume, and all
+		// parts add up to the volume
+		closure := row()
+		closure[k] = -1
+		tolerant := false
+		for p, part := range cocktail.Ingredients {
+			addPart(closure, k, p, 1)
+			j := parts[k][p]
+			if j < 0 {
+				continue
+			}
+			tolerant = true
+			low := row()
+			low[k], low[j] = shares[k][p]*(1-part.Tolerance), -1
+			problem.Constraints = append(problem.Constraints, algorithms.LPConstraint{Coefficients: low, Sense: algorithms.LPLessEqual})
+			if high := shares[k][p] * (1 + part.Tolerance); high < 1 {
+				coefficients := row()
+				coefficients[k], coefficients[j] = -high, 1
+				problem.Constraints = append(problem.Constraints, algorithms.LPConstraint{Coefficients: coefficients, Sense: algorithms.LPLessEqual})
+			}
+		}
+		if tolerant {
+			problem.Constraints = append(problem.Constraints, algorithms.LPConstraint{Coefficients: closure, Sense: algorithms.LPEqual})
+		}
+	}
+
+	solution := algorithms.SolveLP(ctx, problem)
+	if solution.Status == algorithms.LPInfeasible {
+		return CocktailBatchResponse{Success: false, Message: "the stock cannot cover every cocktail's min_servings"}
+	}
+	if !solution.Success {
+		return CocktailBatchResponse{Success: false, Message: solution.Message}
+	}
+
+	used := make([]float64, len(req.Ingredients))
+	batches := make([]CocktailBatch, len(req.Cocktails))
+	total := 0.0
+	for k, cocktail := range req.Cocktails {
+		volume := solution.X[k]
+		batchParts := make([]CocktailBatchPart, len(cocktail.Ingredients))
+		for p, part := range cocktail.Ingredients {
+			ml := partML(solution.X, k, p)
+			used[ingredientIndex[part.Ingredient]] += ml
+			batchParts[p] = CocktailBatchPart{Ingredient: part.Ingredient, VolumeMl: ml}
+			if volume > 0 {
+				batchParts[p].Share = ml / volume
+			}
+		}
+		servings := volume / cocktail.ServingMl
+		batches[k] = CocktailBatch{
+			CocktailID:    cocktail.ID,
+			Name:          cocktail.Name,
+			Servings:      servings,
+			WholeServings: int(math.Floor(servings + 1e-9)),
+			VolumeMl:      volume,
+			Ingredients:   batchParts,
+		}
+		total += servings
+	}
+	usage := make([]IngredientUsage, len(req.Ingredients))
+	for i, ingredient := range req.Ingredients {
+		usage[i] = IngredientUsage{
+			Ingredient:  ingredient.ID,
+			StockMl:     ingredient.StockMl,
+			UsedMl:      used[i],
+			RemainingMl: math.Max(0, ingredient.StockMl-used[i]),
+			ValuePerMl:  solution.Duals[i],
+		}
+	}
+
+	return CocktailBatchResponse{
+		Success:       true,
+		Batches:       batches,
+		Ingredients:   usage,
+		TotalServings: total,
+		Message:       fmt.Sprintf("Batches for %.1f servings over %d cocktails", total, len(batches)),
+	}
+}
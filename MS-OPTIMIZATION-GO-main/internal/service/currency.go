@@ -0,0 +1,297 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"strconv"
+	"strings"
+)
+
+// maxMinorUnits bounds every amount accepted by the API (10^15 minor units, e.g. ten trillion
+// dollars) so amounts stay exact when returned as JSON numbers, which are float64 (2^53)
+const maxMinorUnits = 1000000000000000
+
+// Minor units are held in int, which must be 64 bits wide to fit maxMinorUnits
+var _ int = maxMinorUnits
+
+// Currency describes how a currency is counted and which cash denominations circulate
+type Currency struct {
+	Code          string
+	Symbol        string
+	Decimals      int         // minor-unit digits: 2 for USD and EUR, 0 for COP
+	Denominations []int       // in minor units
+	SmallestBill  int         // lowest denomination issued as a bill, in minor units
+	Locale        string      // how amounts are written, e.g. "es-CO" for $100.000
+	RollSizes     map[int]int // standard coins per roll; bills are bundled by billBundleSize
+}
+
+// supportedCurrencies holds the built-in denomination sets (bills and coins, in minor units),
+// which seed the runtime-managed sets of OptimizationService
+var supportedCurrencies = map[string]Currency{
+	"USD": {
+		Code:          "USD",
+		Symbol:        "$",
+		Decimals:      2,
+		Denominations: []int{5000, 2000, 1000, 500, 200, 100, 50, 25, 10, 5, 1}, // $50 ... $0.01
+		SmallestBill:  100,
+		Locale:        "en-US",
+		RollSizes:     map[int]int{50: 20, 25: 40, 10: 50, 5: 40, 1: 50},
+	},
+	"EUR": {
+		Code:          "EUR",
+		Symbol:        "€",
+		Decimals:      2,
+		Denominations: []int{50000, 20000, 10000, 5000, 2000, 1000, 500, 200, 100, 50, 20, 10, 5, 2, 1}, // €500 ... €0.01
+		SmallestBill:  500,
+		Locale:        "es-ES",
+		RollSizes:     map[int]int{200: 25, 100: 25, 50: 40, 20: 40, 10: 40, 5: 50, 2: 50, 1: 50},
+	},
+	"COP": {
+		Code:          "COP",
+		Symbol:        "$",
+		Decimals:      0,
+		Denominations: []int{100000, 50000, 20000, 10000, 5000, 2000, 1000, 500, 200, 100, 50}, // $100.000 ... $50
+		SmallestBill:  2000,
+		Locale:        "es-CO",
+	},
+}
+
+// newMoneyAlgorithm creates a change algorithm for the given denominations of this currency
+func (c Currency) newMoneyAlgorithm(coins []int) *algorithms.MoneyChangeAlgorithm {
+	moneyAlgo := algorithms.NewMoneyChangeAlgorithm(coins)
+	moneyAlgo.SetSmallestBill(c.SmallestBill)
+	return moneyAlgo
+}
+
+// scale returns the number of minor units in one major unit (100 for cents)
+func (c Currency) scale() float64 {
+	return math.Pow10(c.Decimals)
+}
+
+// toMinorUnits converts an amount in major units (e.g. dollars) to minor units (e.g. cents).
+// The conversion works on the shortest decimal form of the float, so 10.10 is exactly 1010,
+// and amounts more precise than the currency allows are rejected instead of truncated
+func (c Currency) toMinorUnits(amount float64) (int, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("amount %v is not a finite number", amount)
+	}
+	return c.parseMinorUnits(strconv.FormatFloat(amount, 'f', -1, 64))
+}
+
+// amountToMinorUnits returns minor when the request supplied an integer minor-unit amount,
+// otherwise it converts the major-unit amount
+func (c Currency) amountToMinorUnits(amount float64, minor *int64) (int, error) {
+	if minor == nil {
+		return c.toMinorUnits(amount)
+	}
+	if *minor < -maxMinorUnits || *minor > maxMinorUnits {
+		return 0, fmt.Errorf("amount %d minor units exceeds the maximum of %d", *minor, int64(maxMinorUnits))
+	}
+	return int(*minor), nil
+}
+
+// parseMinorUnits converts a plain decimal string (e.g. "10.10" or "-0.5") to minor units
+// without going through floating point
+func (c Currency) parseMinorUnits(value string) (int, error) {
+	digits := strings.TrimSpace(value)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(strings.TrimPrefix(digits, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > c.Decimals {
+		return 0, fmt.Errorf("amount %s has more than %d decimal places for %s", value, c.Decimals, c.Code)
+	}
+	fraction += strings.Repeat("0", c.Decimals-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || units > maxMinorUnits {
+		return 0, fmt.Errorf("amount %s exceeds the maximum of %d minor units", value, int64(maxMinorUnits))
+	}
+	if negative {
+		units = -units
+	}
+
+	return int(units), nil
+}
+
+// isDigits reports whether s consists only of ASCII digits (the empty string included)
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fromMinorUnits converts minor units back to major units
+func (c Currency) fromMinorUnits(units int) float64 {
+	return float64(units) / c.scale()
+}
+
+// denominationToMinorUnits converts a positive denomination to a whole number of minor units
+func (c Currency) denominationToMinorUnits(value float64) (int, error) {
+	if value <= 0 {
+		return 0, fmt.Errorf("denomination %v must be positive", value)
+	}
+	return c.denominationKeyToMinorUnits(strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// denominationKeyToMinorUnits converts a denomination key (e.g. "0.25") to minor units
+func (c Currency) denominationKeyToMinorUnits(key string) (int, error) {
+	if _, err := strconv.ParseFloat(key, 64); err != nil {
+		return 0, fmt.Errorf("invalid denomination %q", key)
+	}
+
+	units, err := c.parseMinorUnits(key)
+	if err != nil {
+		return 0, fmt.Errorf("denomination %s must be a whole number of %s minor units", key, c.Code)
+	}
+	if units <= 0 {
+		return 0, fmt.Errorf("denomination %s must be positive", key)
+	}
+
+	return units, nil
+}
+
+// parseDenominations validates request denominations and converts them to minor units
+func (c Currency) parseDenominations(values []float64) ([]int, error) {
+	if len(values) > maxDenominations {
+		return nil, fmt.Errorf("at most %d denominations are allowed", maxDenominations)
+	}
+
+	seen := make(map[int]bool)
+	coins := make([]int, 0, len(values))
+	for _, value := range values {
+		units, err := c.denominationToMinorUnits(value)
+		if err != nil {
+			return nil, err
+		}
+		if seen[units] {
+			return nil, fmt.Errorf("duplicate denomination %v", value)
+		}
+
+		seen[units] = true
+		coins = append(coins, units)
+	}
+
+	return coins, nil
+}
+
+// parseAvailable converts drawer contents keyed by denomination into minor units -> quantity,
+// rejecting denominations outside the active set
+func (c Currency) parseAvailable(available map[string]int, coins []int) (map[int]int, error) {
+	result, err := c.parseDrawerContents(available)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[int]bool, len(coins))
+	for _, coin := range coins {
+		active[coin] = true
+	}
+	for units := range result {
+		if !active[units] {
+			return nil, fmt.Errorf("drawer denomination %s is not in the active denomination set", c.formatDenominationKey(units))
+		}
+	}
+
+	return result, nil
+}
+
+// parseWeights converts denomination-keyed balancing weights into minor units -> weight
+func (c Currency) parseWeights(weights map[string]float64, coins []int) (map[int]float64, error) {
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("weights are required for the balance_drawer strategy")
+	}
+
+	active := make(map[int]bool, len(coins))
+	for _, coin := range coins {
+		active[coin] = true
+	}
+
+	result := make(map[int]float64, len(weights))
+	for key, weight := range weights {
+		units, err := c.denominationKeyToMinorUnits(key)
+		if err != nil {
+			return nil, err
+		}
+		if !active[units] {
+			return nil, fmt.Errorf("weight denomination %s is not in the active denomination set", key)
+		}
+		if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weight for %s must be a positive number", key)
+		}
+		result[units] = weight
+	}
+
+	return result, nil
+}
+
+// parseDrawerContents converts drawer contents keyed by denomination (e.g. "0.25") into minor units -> quantity
+func (c Currency) parseDrawerContents(contents map[string]int) (map[int]int, error) {
+	result := make(map[int]int, len(contents))
+	for key, count := range contents {
+		units, err := c.denominationKeyToMinorUnits(key)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 || count > maxDrawerCount {
+			return nil, fmt.Errorf("drawer quantity for %s must be between 0 and %d", key, maxDrawerCount)
+		}
+		result[units] += count
+	}
+
+	return result, nil
+}
+
+// formatDrawerContents converts minor units -> quantity back to the denomination-keyed request format
+func (c Currency) formatDrawerContents(counts map[int]int) map[string]int {
+	formatted := make(map[string]int, len(counts))
+	for units, quantity := range counts {
+		formatted[c.formatDenominationKey(units)] = quantity
+	}
+	return formatted
+}
+
+// formatDenominationKey formats minor units as a plain denomination key, e.g. 25 -> "0.25", 500 -> "5"
+func (c Currency) formatDenominationKey(units int) string {
+	return strconv.FormatFloat(c.fromMinorUnits(units), 'f', -1, 64)
+}
+
+// formatAmount formats minor units with the currency symbol in the currency's locale,
+// e.g. 25 -> "$0.25" in en-US or 123450 -> "1.234,50 €" in es-ES
+func (c Currency) formatAmount(units int) string {
+	number := c.formatNumber(units)
+	if locales[c.Locale].symbolAfter {
+		return number + " " + strings.TrimSpace(c.Symbol)
+	}
+	if units < 0 {
+		return "-" + c.Symbol + number[1:]
+	}
+	return c.Symbol + number
+}
+
+// formatCoins formats coin values from minor units to currency format
+func (c Currency) formatCoins(coins []int) []string {
+	formatted := make([]string, len(coins))
+	for i, coin := range coins {
+		formatted[i] = c.formatAmount(coin)
+	}
+	return formatted
+}
+
+// formatBreakdown converts a minor units -> quantity breakdown to currency-formatted keys
+func (c Currency) formatBreakdown(breakdown map[int]int) map[string]int {
+	formatted := make(map[string]int, len(breakdown))
+	for units, quantity := range breakdown {
+		formatted[c.formatAmount(units)] = quantity
+	}
+	return formatted
+}
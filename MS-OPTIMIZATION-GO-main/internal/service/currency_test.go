@@ -0,0 +1,161 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDenominationToMinorUnits(t *testing.T) {
+	usd, cop := supportedCurrencies["USD"], supportedCurrencies["COP"]
+	tests := []struct {
+		currency Currency
+		value    float64
+		want     int
+		ok       bool
+	}{
+		{usd, 0.25, 25, true},
+		{usd, 100, 10000, true},
+		{usd, 0.001, 0, false},
+		{usd, 0, 0, false},
+		{usd, -1, 0, false},
+		{cop, 50, 50, true},
+		{cop, 100000, 100000, true},
+		{cop, 0.5, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.currency.denominationToMinorUnits(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s %v: got %d, %v; want %d, ok %v", tt.currency.Code, tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDrawerContentsRoundTrip(t *testing.T) {
+	tests := []struct {
+		code     string
+		contents map[string]int
+		want     map[int]int
+	}{
+		{"USD", map[string]int{"0.25": 4, "1": 2, "20": 1}, map[int]int{25: 4, 100: 2, 2000: 1}},
+		{"EUR", map[string]int{"0.02": 3, "500": 1}, map[int]int{2: 3, 50000: 1}},
+		{"COP", map[string]int{"50": 6, "100000": 2}, map[int]int{50: 6, 100000: 2}},
+	}
+	for _, tt := range tests {
+		currency := supportedCurrencies[tt.code]
+		got, err := currency.parseDrawerContents(tt.contents)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parsed %v, %v; want %v", tt.code, got, err, tt.want)
+			continue
+		}
+		if back := currency.formatDrawerContents(got); !reflect.DeepEqual(back, tt.contents) {
+			t.Errorf("%s: formatted back as %v, want %v", tt.code, back, tt.contents)
+		}
+	}
+
+	if _, err := supportedCurrencies["COP"].parseDrawerContents(map[string]int{"0.5": 1}); err == nil {
+		t.Error("COP drawer with a fractional denomination accepted")
+	}
+}
+
+func TestParseMinorUnits(t *testing.T) {
+	usd, cop := supportedCurrencies["USD"], supportedCurrencies["COP"]
+	tests := []struct {
+		currency Currency
+		value    string
+		want     int
+		ok       bool
+	}{
+		{usd, "10.10", 1010, true},
+		{usd, "-0.5", -50, true},
+		{usd, "+3", 300, true},
+		{usd, ".25", 25, true},
+		{usd, "1.230", 123, true},
+		{usd, "1.234", 0, false},
+		{usd, "1e3", 0, false},
+		{usd, "", 0, false},
+		{cop, "1500", 1500, true},
+		{cop, "0.5", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.currency.parseMinorUnits(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s %q: got %d, %v; want %d, ok %v", tt.currency.Code, tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAmountToMinorUnits(t *testing.T) {
+	usd := supportedCurrencies["USD"]
+	minor := func(units int64) *int64 { return &units }
+	tests := []struct {
+		name   string
+		amount float64
+		minor  *int64
+		want   int
+		ok     bool
+	}{
+		{"major units", 10.1, nil, 1010, true},
+		{"more precise than the currency", 10.001, nil, 0, false},
+		{"not finite", math.Inf(1), nil, 0, false},
+		{"minor units", 0, minor(1010), 1010, true},
+		{"minor units override the amount", 99, minor(-250), -250, true},
+	}
+	for _, tt := range tests {
+		got, err := usd.amountToMinorUnits(tt.amount, tt.minor)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s: got %d, %v; want %d, ok %v", tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMaxMinorUnits(t *testing.T) {
+	usd, cop := supportedCurrencies["USD"], supportedCurrencies["COP"]
+	tests := []struct {
+		currency Currency
+		value    string
+		want     int
+		ok       bool
+	}{
+		{usd, "10000000000000", maxMinorUnits, true},
+		{usd, "10000000000000.01", 0, false},
+		{usd, "-10000000000000", -maxMinorUnits, true},
+		{usd, "99999999999999999999", 0, false},
+		{cop, "1000000000000000", maxMinorUnits, true},
+		{cop, "1000000000000001", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := tt.currency.parseMinorUnits(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s %q: got %d, %v; want %d, ok %v", tt.currency.Code, tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+
+	minor := func(units int64) *int64 { return &units }
+	for _, units := range []int64{maxMinorUnits + 1, -maxMinorUnits - 1} {
+		if _, err := usd.amountToMinorUnits(0, minor(units)); err == nil {
+			t.Errorf("%d minor units accepted", units)
+		}
+	}
+	if got, err := usd.amountToMinorUnits(0, minor(maxMinorUnits)); err != nil || got != maxMinorUnits {
+		t.Errorf("maximum minor units: got %d, %v", got, err)
+	}
+	if _, err := usd.amountToMinorUnits(1e13+1, nil); err == nil {
+		t.Error("major amount over the maximum accepted")
+	}
+}
+
+func TestChangeAtMaxMinorUnits(t *testing.T) {
+	// The largest amount the API accepts, in every currency, by greedy and by dp alike
+	for code, currency := range supportedCurrencies {
+		algorithm := currency.newMoneyAlgorithm(currency.Denominations)
+		greedy := algorithm.CalculateChange(maxMinorUnits)
+		dp := algorithm.CalculateChangeDP(maxMinorUnits)
+		if !greedy.Success || !dp.Success {
+			t.Fatalf("%s: greedy %q, dp %q", code, greedy.Message, dp.Message)
+		}
+		if greedy.TotalCoins != dp.TotalCoins {
+			t.Errorf("%s: greedy gives %d pieces, dp %d", code, greedy.TotalCoins, dp.TotalCoins)
+		}
+	}
+}
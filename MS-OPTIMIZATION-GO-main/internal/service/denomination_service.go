@@ -0,0 +1,328 @@
+package service
+
+import (
+	"fmt"
+	"ms-optimization-go/internal/algorithms"
+	"sort"
+	"strings"
+)
+
+// maxSetNameLength limits the length of denomination set names
+const maxSetNameLength = 64
+
+// denominationSet is a named denomination set together with its change algorithm. The set
+// named after a currency code is that currency's default
+type denominationSet struct {
+	currency  Currency // Denominations in minor units, sorted in descending order
+	moneyAlgo *algorithms.MoneyChangeAlgorithm
+	builtIn   bool
+}
+
+// DenominationSetRequest represents a request to create or replace a denomination set
+type DenominationSetRequest struct {
+	Name          string    `json:"name,omitempty"`     // required on create; the URL names the set on update
+	Currency      string    `json:"currency,omitempty"` // defaults to the name when it is a currency code
+	Symbol        string    `json:"symbol,omitempty"`
+	Decimals      *int      `json:"decimals,omitempty"` // defaults to the currency's, or 2
+	Denominations []float64 `json:"denominations"`      // positive and unique, in major units, in any order
+	SmallestBill  float64   `json:"smallest_bill,omitempty"`
+	Locale        string    `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+}
+
+// DenominationSetInfo describes a denomination set
+type DenominationSetInfo struct {
+	Name          string   `json:"name"`
+	Currency      string   `json:"currency"`
+	Symbol        string   `json:"symbol"`
+	Decimals      int      `json:"decimals"`
+	Denominations []string `json:"denominations"`
+	SmallestBill  float64  `json:"smallest_bill"`
+	Locale        string   `json:"locale"`
+	Canonical     bool     `json:"canonical"`
+	BuiltIn       bool     `json:"built_in"`
+}
+
+// DenominationSetResponse represents the result of a denomination set operation
+type DenominationSetResponse struct {
+	Success bool                 `json:"success"`
+	Set     *DenominationSetInfo `json:"set,omitempty"`
+	Message string               `json:"message"`
+
+	NotFound bool `json:"-"`
+	Conflict bool `json:"-"`
+}
+
+// DenominationSetsResponse lists the configured denomination sets
+type DenominationSetsResponse struct {
+	Success bool                  `json:"success"`
+	Sets    []DenominationSetInfo `json:"sets"`
+	Message string                `json:"message"`
+}
+
+// ListDenominationSets returns every denomination set ordered by name
+func (os *OptimizationService) ListDenominationSets() DenominationSetsResponse {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+
+	names := make([]string, 0, len(os.denominationSets))
+	for name := range os.denominationSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sets := make([]DenominationSetInfo, len(names))
+	for i, name := range names {
+		sets[i] = os.denominationSets[name].info(name)
+	}
+
+	return DenominationSetsResponse{
+		Success: true,
+		Sets:    sets,
+		Message: fmt.Sprintf("%d denomination sets configured", len(sets)),
+	}
+}
+
+// GetDenominationSet returns a single denomination set
+func (os *OptimizationService) GetDenominationSet(name string) DenominationSetResponse {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+
+	set, ok := os.denominationSets[name]
+	if !ok {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Denomination set %s not found", name), NotFound: true}
+	}
+
+	info := set.info(name)
+	return DenominationSetResponse{Success: true, Set: &info, Message: "Denomination set retrieved successfully"}
+}
+
+// CreateDenominationSet adds a new denomination set
+func (os *OptimizationService) CreateDenominationSet(req DenominationSetRequest) DenominationSetResponse {
+	set, err := newDenominationSet(req.Name, req)
+	if err != nil {
+		return DenominationSetResponse{Success: false, Message: err.Error()}
+	}
+
+	os.mu.Lock()
+	defer os.mu.Unlock()
+
+	if _, exists := os.denominationSets[req.Name]; exists {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Denomination set %s already exists", req.Name), Conflict: true}
+	}
+	os.denominationSets[req.Name] = set
+
+	info := set.info(req.Name)
+	return DenominationSetResponse{Success: true, Set: &info, Message: "Denomination set created successfully"}
+}
+
+// UpdateDenominationSet replaces an existing denomination set. Requests already resolved
+// against the old set finish with it; later requests use the new one
+func (os *OptimizationService) UpdateDenominationSet(name string, req DenominationSetRequest) DenominationSetResponse {
+	if req.Name != "" && req.Name != name {
+		return DenominationSetResponse{Success: false, Message: "Denomination sets cannot be renamed"}
+	}
+
+	set, err := newDenominationSet(name, req)
+	if err != nil {
+		return DenominationSetResponse{Success: false, Message: err.Error()}
+	}
+
+	os.mu.Lock()
+	defer os.mu.Unlock()
+
+	current, exists := os.denominationSets[name]
+	if !exists {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Denomination set %s not found", name), NotFound: true}
+	}
+	if current.builtIn && set.currency.Code != current.currency.Code {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Denomination set %s must stay in %s", name, current.currency.Code)}
+	}
+	// Stored drawers and change records hold minor units, so changing how many there are in a
+	// major unit would change every amount already stored in the currency
+	if set.currency.Decimals != current.currency.Decimals {
+		if current.builtIn {
+			return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Decimals of built-in denomination set %s cannot change", name)}
+		}
+		inUse, err := os.drawerRepo.CurrencyInUse(current.currency.Code)
+		if err != nil {
+			return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Failed to check drawers: %v", err)}
+		}
+		if inUse {
+			return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Decimals of denomination set %s cannot change while drawers hold %s", name, current.currency.Code), Conflict: true}
+		}
+	}
+	set.builtIn = current.builtIn
+	os.denominationSets[name] = set
+
+	info := set.info(name)
+	return DenominationSetResponse{Success: true, Set: &info, Message: "Denomination set updated successfully"}
+}
+
+// DeleteDenominationSet removes a denomination set; built-in currency sets can only be replaced
+func (os *OptimizationService) DeleteDenominationSet(name string) DenominationSetResponse {
+	os.mu.Lock()
+	defer os.mu.Unlock()
+
+	set, exists := os.denominationSets[name]
+	if !exists {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Denomination set %s not found", name), NotFound: true}
+	}
+	if set.builtIn {
+		return DenominationSetResponse{Success: false, Message: fmt.Sprintf("Built-in denomination set %s cannot be deleted", name)}
+	}
+	delete(os.denominationSets, name)
+
+	return DenominationSetResponse{Success: true, Message: fmt.Sprintf("Denomination set %s deleted", name)}
+}
+
+// lookupCurrency returns the currency for code from its default denomination set. Unknown
+// codes get a 2-decimal currency without denominations and ok set to false
+func (os *OptimizationService) lookupCurrency(code string) (currency Currency, ok bool) {
+	code = strings.ToUpper(code)
+	if code == "" {
+		code = defaultCurrency
+	}
+
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+
+	if set, ok := os.denominationSets[code]; ok && set.currency.Code == code {
+		return set.currency, true
+	}
+	return Currency{Code: code, Symbol: code + " ", Decimals: 2, SmallestBill: 100, Locale: defaultLocale}, false
+}
+
+// lookupDenominationSet returns a denomination set by name
+func (os *OptimizationService) lookupDenominationSet(name string) (denominationSet, bool) {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+
+	set, ok := os.denominationSets[name]
+	return set, ok
+}
+
+// currencyCodes returns the codes of the currencies with a default denomination set
+func (os *OptimizationService) currencyCodes() []string {
+	os.mu.RLock()
+	defer os.mu.RUnlock()
+
+	codes := make([]string, 0, len(os.denominationSets))
+	for name, set := range os.denominationSets {
+		if name == set.currency.Code {
+			codes = append(codes, name)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// unsupportedCurrency describes a currency without a default denomination set
+func (os *OptimizationService) unsupportedCurrency(code string) string {
+	return fmt.Sprintf("unsupported currency %s, use one of: %s", code, strings.Join(os.currencyCodes(), ", "))
+}
+
+// newDenominationSet validates a denomination set request
+func newDenominationSet(name string, req DenominationSetRequest) (denominationSet, error) {
+	if err := validateSetName(name); err != nil {
+		return denominationSet{}, err
+	}
+
+	code := strings.ToUpper(req.Currency)
+	if code == "" {
+		code = strings.ToUpper(name)
+	}
+	if !IsCurrencyCode(code) {
+		return denominationSet{}, fmt.Errorf("currency is required unless the set is named after a currency code")
+	}
+
+	// Start from the built-in metadata for the currency, if any
+	currency, known := supportedCurrencies[code]
+	if !known {
+		currency = Currency{Code: code, Symbol: code + " ", Decimals: 2, Locale: defaultLocale}
+	}
+	if req.Symbol != "" {
+		currency.Symbol = req.Symbol
+	}
+	if req.Decimals != nil {
+		if *req.Decimals < 0 || *req.Decimals > 4 {
+			return denominationSet{}, fmt.Errorf("decimals must be between 0 and 4")
+		}
+		currency.Decimals = *req.Decimals
+	}
+
+	currency, err := currency.withLocale(req.Locale)
+	if err != nil {
+		return denominationSet{}, err
+	}
+
+	if len(req.Denominations) == 0 {
+		return denominationSet{}, fmt.Errorf("at least one denomination is required")
+	}
+	coins, err := currency.parseDenominations(req.Denominations)
+	if err != nil {
+		return denominationSet{}, err
+	}
+	// Denominations may come in any order; they are kept largest first
+	sort.Sort(sort.Reverse(sort.IntSlice(coins)))
+	currency.Denominations = coins
+
+	if req.SmallestBill != 0 {
+		if currency.SmallestBill, err = currency.denominationToMinorUnits(req.SmallestBill); err != nil {
+			return denominationSet{}, fmt.Errorf("invalid smallest_bill: %w", err)
+		}
+	}
+
+	return denominationSet{
+		currency:  currency,
+		moneyAlgo: currency.newMoneyAlgorithm(coins),
+	}, nil
+}
+
+// validateSetName checks that a set name is a short slug such as "USD" or "usd-no-pennies"
+func validateSetName(name string) error {
+	if name == "" || len(name) > maxSetNameLength {
+		return fmt.Errorf("set name must be between 1 and %d characters", maxSetNameLength)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return fmt.Errorf("set name may only contain letters, digits, '-' and '_'")
+		}
+	}
+	return nil
+}
+
+// IsCurrencyCode reports whether code looks like a 3-letter ISO 4217 currency code, in either case
+func IsCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCurrencyCode rejects a currency that is given but does not look like a currency code
+func validateCurrencyCode(code string) error {
+	if code != "" && !IsCurrencyCode(code) {
+		return fmt.Errorf("invalid currency code %q, expected a 3-letter code such as USD or COP", code)
+	}
+	return nil
+}
+
+// info describes the set for API responses
+func (set denominationSet) info(name string) DenominationSetInfo {
+	return DenominationSetInfo{
+		Name:          name,
+		Currency:      set.currency.Code,
+		Symbol:        set.currency.Symbol,
+		Decimals:      set.currency.Decimals,
+		Denominations: set.currency.formatCoins(set.currency.Denominations),
+		SmallestBill:  set.currency.fromMinorUnits(set.currency.SmallestBill),
+		Locale:        set.currency.Locale,
+		Canonical:     set.moneyAlgo.IsCanonical(),
+		BuiltIn:       set.builtIn,
+	}
+}
@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ms-optimization-go/internal/algorithms"
+	"ms-optimization-go/internal/models"
+	"ms-optimization-go/internal/repository"
+	"time"
+)
+
+// maxDispenseAttempts bounds retries when another request changes the drawer mid-calculation
+const maxDispenseAttempts = 3
+
+// LoadDrawerRequest represents a request to set the full contents of a drawer
+type LoadDrawerRequest struct {
+	Currency string         `json:"currency"`
+	Contents map[string]int `json:"contents"` // denomination (e.g. "0.25") -> quantity
+}
+
+// ReplenishDrawerRequest represents a request to add pieces to a drawer
+type ReplenishDrawerRequest struct {
+	Contents map[string]int `json:"contents"` // denomination (e.g. "0.25") -> quantity to add
+}
+
+// DrawerResponse represents the current state of a drawer
+type DrawerResponse struct {
+	Success     bool           `json:"success"`
+	DrawerID    string         `json:"drawer_id"`
+	Currency    string         `json:"currency,omitempty"`
+	Contents    map[string]int `json:"contents,omitempty"`
+	TotalValue  float64        `json:"total_value"`
+	TotalPieces int            `json:"total_pieces"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	Message     string         `json:"message"`
+	NotFound    bool           `json:"-"`
+	Invalid     bool           `json:"-"`
+}
+
+// GetDrawer returns the current contents of a drawer
+func (os *OptimizationService) GetDrawer(id string) DrawerResponse {
+	drawer, err := os.drawerRepo.GetDrawer(id)
+	if err != nil {
+		return drawerErrorResponse(id, err)
+	}
+
+	return os.newDrawerResponse(drawer, "Drawer retrieved successfully")
+}
+
+// LoadDrawer replaces the contents of a drawer, creating it if needed
+func (os *OptimizationService) LoadDrawer(id string, req LoadDrawerRequest) DrawerResponse {
+	currency, ok := os.lookupCurrency(req.Currency)
+	if !ok {
+		return DrawerResponse{Success: false, DrawerID: id, Message: os.unsupportedCurrency(currency.Code), Invalid: true}
+	}
+	counts, err := currency.parseDrawerContents(req.Contents)
+	if err != nil {
+		return DrawerResponse{Success: false, DrawerID: id, Message: err.Error()}
+	}
+
+	drawer := &models.Drawer{
+		ID:       id,
+		Currency: currency.Code,
+		Counts:   counts,
+	}
+	if err := os.drawerRepo.SaveDrawer(drawer); err != nil {
+		return drawerErrorResponse(id, err)
+	}
+
+	return os.newDrawerResponse(drawer, "Drawer loaded successfully")
+}
+
+// ReplenishDrawer adds pieces to an existing drawer
+func (os *OptimizationService) ReplenishDrawer(id string, req ReplenishDrawerRequest) DrawerResponse {
+	// The stored currency decides how the denomination keys are read
+	drawer, err := os.drawerRepo.GetDrawer(id)
+	if err != nil {
+		return drawerErrorResponse(id, err)
+	}
+
+	currency, _ := os.lookupCurrency(drawer.Currency)
+	counts, err := currency.parseDrawerContents(req.Contents)
+	if err != nil {
+		return DrawerResponse{Success: false, DrawerID: id, Message: err.Error()}
+	}
+
+	drawer, err = os.drawerRepo.AddToDrawer(id, counts)
+	if err != nil {
+		return drawerErrorResponse(id, err)
+	}
+
+	return os.newDrawerResponse(drawer, "Drawer replenished successfully")
+}
+
+// dispenseFromDrawer runs solve against the stored drawer contents and, when the change
+// can be made, removes the dispensed pieces. Concurrent dispenses are retried with fresh counts
+func (os *OptimizationService) dispenseFromDrawer(id string, currency Currency, solve changeSolver) (algorithms.ChangeResult, *models.Drawer, error) {
+	for attempt := 0; attempt < maxDispenseAttempts; attempt++ {
+		drawer, err := os.drawerRepo.GetDrawer(id)
+		if err != nil {
+			return algorithms.ChangeResult{}, nil, fmt.Errorf("failed to get drawer %s: %w", id, err)
+		}
+		if drawer.Currency != currency.Code {
+			return algorithms.ChangeResult{}, nil, fmt.Errorf("drawer %s holds %s, not %s", id, drawer.Currency, currency.Code)
+		}
+
+		result := solve(drawer.Counts)
+		if !result.Success || len(result.Breakdown) == 0 {
+			return result, drawer, nil
+		}
+
+		updated, err := os.drawerRepo.Dispense(id, result.Breakdown)
+		if errors.Is(err, repository.ErrInsufficientDrawer) {
+			continue
+		}
+		if err != nil {
+			return algorithms.ChangeResult{}, nil, fmt.Errorf("failed to dispense from drawer %s: %w", id, err)
+		}
+		return result, updated, nil
+	}
+
+	return algorithms.ChangeResult{}, nil, fmt.Errorf("drawer %s changed while dispensing, please retry", id)
+}
+
+// newDrawerResponse builds a response with totals for the given drawer
+func (os *OptimizationService) newDrawerResponse(drawer *models.Drawer, message string) DrawerResponse {
+	currency, _ := os.lookupCurrency(drawer.Currency)
+
+	totalValue := drawerValue(drawer.Counts)
+	totalPieces := countPieces(drawer.Counts)
+	updatedAt := drawer.UpdatedAt
+
+	return DrawerResponse{
+		Success:     true,
+		DrawerID:    drawer.ID,
+		Currency:    drawer.Currency,
+		Contents:    currency.formatDrawerContents(drawer.Counts),
+		TotalValue:  currency.fromMinorUnits(totalValue),
+		TotalPieces: totalPieces,
+		UpdatedAt:   &updatedAt,
+		Message:     message,
+	}
+}
+
+// drawerErrorResponse maps repository errors to a failed response
+func drawerErrorResponse(id string, err error) DrawerResponse {
+	if errors.Is(err, repository.ErrDrawerNotFound) {
+		return DrawerResponse{
+			Success:  false,
+			DrawerID: id,
+			Message:  fmt.Sprintf("Drawer %s not found", id),
+			NotFound: true,
+		}
+	}
+
+	return DrawerResponse{
+		Success:  false,
+		DrawerID: id,
+		Message:  fmt.Sprintf("Drawer operation failed: %v", err),
+	}
+}
+
+// ReconcileDrawerRequest represents an end-of-shift drawer count to reconcile
+type ReconcileDrawerRequest struct {
+	Currency string `json:"currency,omitempty"`
+	Locale   string `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+	// Expected contents; when omitted the stored contents of DrawerID are used. A stored
+	// drawer must hold the request's currency
+	DrawerID string         `json:"drawer_id,omitempty"`
+	Expected map[string]int `json:"expected,omitempty"`
+	Counted  map[string]int `json:"counted"`
+	// Float configuration to reset to: an exact composition or just an amount
+	FloatContents    map[string]int `json:"float_contents,omitempty"`
+	FloatAmount      float64        `json:"float_amount,omitempty"`
+	FloatAmountCents *int64         `json:"float_amount_cents,omitempty"` // exact float in minor units, overrides float_amount
+	// Pack the deposit in standard coin rolls and bill bundles for the bank, optionally
+	// overriding roll sizes (denomination, e.g. "0.25" -> pieces per roll; 0 keeps it loose)
+	Rolls     bool           `json:"rolls,omitempty"`
+	RollSizes map[string]int `json:"roll_sizes,omitempty"`
+}
+
+// ReconcileDrawerResponse represents the discrepancies and the plan to reset the float
+type ReconcileDrawerResponse struct {
+	Success       bool                          `json:"success"`
+	Currency      string                        `json:"currency"`
+	ExpectedTotal float64                       `json:"expected_total"`
+	CountedTotal  float64                       `json:"counted_total"`
+	OverShort     float64                       `json:"over_short"`    // counted - expected
+	Discrepancies map[string]int                `json:"discrepancies"` // denomination -> counted - expected pieces
+	Keep          map[string]int                `json:"keep,omitempty"`
+	Remove        map[string]int                `json:"remove,omitempty"`
+	Add           map[string]int                `json:"add,omitempty"`
+	PiecesHandled int                           `json:"pieces_handled"`
+	Deposit       float64                       `json:"deposit"` // value removed - value added; negative means a withdrawal from the safe
+	DepositRolls  map[string]RolledDenomination `json:"deposit_rolls,omitempty"`
+	Message       string                        `json:"message"`
+}
+
+// ReconcileDrawer compares the counted drawer with the expected one and plans the
+// deposits and top-ups that reset it to its float with minimum handling
+func (os *OptimizationService) ReconcileDrawer(req ReconcileDrawerRequest) ReconcileDrawerResponse {
+	currencyCode := req.Currency
+	expectedContents := req.Expected
+	var expected map[int]int
+
+	var drawer *models.Drawer
+	if req.DrawerID != "" {
+		var err error
+		if drawer, err = os.drawerRepo.GetDrawer(req.DrawerID); err != nil {
+			return ReconcileDrawerResponse{Success: false, Currency: currencyCode, Message: drawerErrorResponse(req.DrawerID, err).Message}
+		}
+		if currencyCode == "" {
+			currencyCode = drawer.Currency
+		}
+		if expectedContents == nil {
+			expected = drawer.Counts
+		}
+	}
+
+	currency, moneyAlgo, err := os.resolveCurrency(currencyCode, "", nil)
+	if err == nil {
+		currency, err = currency.withLocale(req.Locale)
+	}
+	if err != nil {
+		return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+	if drawer != nil && drawer.Currency != currency.Code {
+		return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("drawer %s holds %s, not %s", req.DrawerID, drawer.Currency, currency.Code)}
+	}
+
+	if expected == nil {
+		if expected, err = currency.parseDrawerContents(expectedContents); err != nil {
+			return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+		}
+	}
+	counted, err := currency.parseDrawerContents(req.Counted)
+	if err != nil {
+		return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+
+	var plan algorithms.FloatResetPlan
+	if req.FloatContents != nil {
+		target, err := currency.parseDrawerContents(req.FloatContents)
+		if err != nil {
+			return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+		}
+		plan = algorithms.PlanFloatComposition(counted, target)
+	} else {
+		floatAmount, err := currency.amountToMinorUnits(req.FloatAmount, req.FloatAmountCents)
+		if err != nil {
+			return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+		}
+		plan = moneyAlgo.PlanFloatReset(counted, floatAmount)
+	}
+
+	var depositRolls map[string]RolledDenomination
+	if req.Rolls && plan.Success {
+		denominations := make([]int, 0, len(counted))
+		for units := range counted {
+			denominations = append(denominations, units)
+		}
+		sizes, err := currency.rollSizes(denominations, req.RollSizes)
+		if err != nil {
+			return ReconcileDrawerResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+		}
+		depositRolls = currency.formatRolls(plan.Remove, sizes)
+	}
+
+	discrepancies := make(map[string]int)
+	for units := range mergeKeys(expected, counted) {
+		if diff := counted[units] - expected[units]; diff != 0 {
+			discrepancies[currency.formatDenominationKey(units)] = diff
+		}
+	}
+
+	expectedTotal := drawerValue(expected)
+	countedTotal := drawerValue(counted)
+
+	if !plan.Success {
+		return ReconcileDrawerResponse{
+			Success:       false,
+			Currency:      currency.Code,
+			ExpectedTotal: currency.fromMinorUnits(expectedTotal),
+			CountedTotal:  currency.fromMinorUnits(countedTotal),
+			OverShort:     currency.fromMinorUnits(countedTotal - expectedTotal),
+			Discrepancies: discrepancies,
+			Message:       plan.Message,
+		}
+	}
+
+	return ReconcileDrawerResponse{
+		Success:       true,
+		Currency:      currency.Code,
+		ExpectedTotal: currency.fromMinorUnits(expectedTotal),
+		CountedTotal:  currency.fromMinorUnits(countedTotal),
+		OverShort:     currency.fromMinorUnits(countedTotal - expectedTotal),
+		Discrepancies: discrepancies,
+		Keep:          currency.formatDrawerContents(plan.Keep),
+		Remove:        currency.formatDrawerContents(plan.Remove),
+		Add:           currency.formatDrawerContents(plan.Add),
+		PiecesHandled: plan.Handled,
+		Deposit:       currency.fromMinorUnits(drawerValue(plan.Remove) - drawerValue(plan.Add)),
+		DepositRolls:  depositRolls,
+		Message: fmt.Sprintf("Drawer over/short %s; reset to float handling %d pieces",
+			currency.formatAmount(countedTotal-expectedTotal), plan.Handled),
+	}
+}
+
+// drawerValue returns the total value of a denomination -> quantity map in minor units
+func drawerValue(counts map[int]int) int {
+	total := 0
+	for units, quantity := range counts {
+		total += units * quantity
+	}
+	return total
+}
+
+// mergeKeys returns the union of the denominations of two drawer maps
+func mergeKeys(a, b map[int]int) map[int]bool {
+	keys := make(map[int]bool, len(a)+len(b))
+	for units := range a {
+		keys[units] = true
+	}
+	for units := range b {
+		keys[units] = true
+	}
+	return keys
+}
+
+// Multi-drawer limits: the drawers considered in one request, and the change amount in minor
+// units times the number of denominations, which sizes the DP tables
+const (
+	maxMultiDrawers     = 10
+	maxMultiDrawerCells = 3000000
+)
+
+// MultiDrawerChangeRequest represents a change calculation across several drawers or terminals
+type MultiDrawerChangeRequest struct {
+	AmountPaid      float64 `json:"amount_paid"`
+	TotalCost       float64 `json:"total_cost"`
+	AmountPaidCents *int64  `json:"amount_paid_cents,omitempty"`
+	TotalCostCents  *int64  `json:"total_cost_cents,omitempty"`
+	Mode            string  `json:"mode,omitempty"` // auto (default), greedy, dp
+
+	Currency        string    `json:"currency,omitempty"`
+	Locale          string    `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+	Denominations   []float64 `json:"denominations,omitempty"`
+	DenominationSet string    `json:"denomination_set,omitempty"`
+
+	// Drawers in order of preference, e.g. the main till before the terrace till
+	Drawers []DrawerAvailability `json:"drawers"`
+}
+
+// DrawerAvailability lists the pieces one drawer holds
+type DrawerAvailability struct {
+	ID        string         `json:"id"`
+	Available map[string]int `json:"available"` // denomination (e.g. "0.25") -> quantity on hand
+}
+
+// DrawerTransfer describes pieces to move from one drawer to the dispensing drawer
+type DrawerTransfer struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Pieces map[string]int `json:"pieces"`
+}
+
+// MultiDrawerChangeResponse represents the drawer chosen to dispense change and any transfers
+type MultiDrawerChangeResponse struct {
+	Success          bool                      `json:"success"`
+	ChangeAmount     float64                   `json:"change_amount"`
+	Currency         string                    `json:"currency"`
+	DispenseFrom     string                    `json:"dispense_from,omitempty"`
+	TotalCoins       int                       `json:"total_coins"`
+	Breakdown        map[string]int            `json:"breakdown"`
+	DrawerBreakdowns map[string]map[string]int `json:"drawer_breakdowns,omitempty"`
+	Transfers        []DrawerTransfer          `json:"transfers,omitempty"`
+	TransferPieces   int                       `json:"transfer_pieces"`
+	Algorithm        string                    `json:"algorithm_used,omitempty"`
+
+	InsufficientChange bool    `json:"insufficient_change"`
+	Shortfall          float64 `json:"shortfall,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// CalculateMultiDrawerChange picks the drawer to dispense change from, topping it up from
+// the other drawers with as few transferred pieces as possible when it cannot cover the change
+func (os *OptimizationService) CalculateMultiDrawerChange(ctx context.Context, req MultiDrawerChangeRequest) MultiDrawerChangeResponse {
+	if err := validateMultiDrawerChangeRequest(req); err != nil {
+		return MultiDrawerChangeResponse{Success: false, Currency: req.Currency, Message: err.Error()}
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = algorithms.ChangeModeAuto
+	}
+
+	currency, moneyAlgo, err := os.resolveCurrency(req.Currency, req.DenominationSet, req.Denominations)
+	if err == nil {
+		currency, err = currency.withLocale(req.Locale)
+	}
+	if err != nil {
+		return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+
+	amountPaid, err := currency.amountToMinorUnits(req.AmountPaid, req.AmountPaidCents)
+	if err != nil {
+		return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("invalid amount paid: %v", err)}
+	}
+	totalCost, err := currency.amountToMinorUnits(req.TotalCost, req.TotalCostCents)
+	if err != nil {
+		return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("invalid total cost: %v", err)}
+	}
+
+	changeAmount := amountPaid - totalCost
+	if changeAmount < 0 {
+		return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: "Insufficient payment amount"}
+	}
+	if changeAmount > maxAnalysisAmount {
+		return MultiDrawerChangeResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  fmt.Sprintf("Change exceeds the multi-drawer limit of %s", currency.formatAmount(maxAnalysisAmount)),
+		}
+	}
+
+	if denominations := len(moneyAlgo.GetAvailableCoins()); changeAmount*denominations > maxMultiDrawerCells {
+		return MultiDrawerChangeResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  fmt.Sprintf("Change of %s with %d denominations exceeds the multi-drawer limit", currency.formatAmount(changeAmount), denominations),
+		}
+	}
+
+	if len(req.Drawers) == 0 || len(req.Drawers) > maxMultiDrawers {
+		return MultiDrawerChangeResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  fmt.Sprintf("between 1 and %d drawers are required", maxMultiDrawers),
+		}
+	}
+	sources := make([]algorithms.DrawerSource, len(req.Drawers))
+	seen := make(map[string]bool, len(req.Drawers))
+	for i, drawer := range req.Drawers {
+		if drawer.ID == "" || seen[drawer.ID] {
+			return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("drawer %d needs a unique id", i)}
+		}
+		seen[drawer.ID] = true
+
+		available, err := currency.parseAvailable(drawer.Available, moneyAlgo.GetAvailableCoins())
+		if err != nil {
+			return MultiDrawerChangeResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("drawer %s: %v", drawer.ID, err)}
+		}
+		sources[i] = algorithms.DrawerSource{ID: drawer.ID, Available: available}
+	}
+
+	result := moneyAlgo.CalculateChangeMultiDrawer(ctx, changeAmount, sources, mode)
+
+	var transfers []DrawerTransfer
+	drawerBreakdowns := make(map[string]map[string]int, len(result.ByDrawer))
+	for _, source := range sources {
+		pieces, ok := result.ByDrawer[source.ID]
+		if !ok {
+			continue
+		}
+		drawerBreakdowns[source.ID] = currency.formatBreakdown(pieces)
+		if source.ID != result.Primary && result.Success {
+			transfers = append(transfers, DrawerTransfer{
+				From:   source.ID,
+				To:     result.Primary,
+				Pieces: currency.formatBreakdown(pieces),
+			})
+		}
+	}
+
+	dispenseFrom := result.Primary
+	message := result.Message
+	if result.Insufficient {
+		dispenseFrom = ""
+		message = fmt.Sprintf("Insufficient change across drawers. Shortfall: %s", currency.formatAmount(result.Shortfall))
+	}
+
+	return MultiDrawerChangeResponse{
+		Success:          result.Success,
+		ChangeAmount:     currency.fromMinorUnits(changeAmount),
+		Currency:         currency.Code,
+		DispenseFrom:     dispenseFrom,
+		TotalCoins:       result.TotalCoins,
+		Breakdown:        currency.formatBreakdown(result.Breakdown),
+		DrawerBreakdowns: drawerBreakdowns,
+		Transfers:        transfers,
+		TransferPieces:   result.Transfers,
+		Algorithm:        result.Algorithm,
+
+		InsufficientChange: result.Insufficient,
+		Shortfall:          currency.fromMinorUnits(result.Shortfall),
+
+		Message: message,
+	}
+}
+
+// validateMultiDrawerChangeRequest checks a multi-drawer change request before any drawer
+// is looked at
+func validateMultiDrawerChangeRequest(req MultiDrawerChangeRequest) error {
+	if req.AmountPaid < 0 || req.TotalCost < 0 || (req.AmountPaidCents != nil && *req.AmountPaidCents < 0) ||
+		(req.TotalCostCents != nil && *req.TotalCostCents < 0) {
+		return errors.New("amounts must be non-negative")
+	}
+	if len(req.Drawers) == 0 {
+		return errors.New("at least one drawer is required")
+	}
+	if err := validateChangeMode(req.Mode); err != nil {
+		return err
+	}
+	return validateCurrencyCode(req.Currency)
+}
+
+// validateChangeMode rejects a change mode that is given but unknown
+func validateChangeMode(mode string) error {
+	if mode != "" && mode != algorithms.ChangeModeAuto && mode != algorithms.ChangeModeGreedy && mode != algorithms.ChangeModeDP {
+		return fmt.Errorf("invalid change mode %q, expected %s, %s or %s", mode, algorithms.ChangeModeAuto, algorithms.ChangeModeGreedy, algorithms.ChangeModeDP)
+	}
+	return nil
+}
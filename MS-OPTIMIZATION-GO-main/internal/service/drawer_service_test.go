@@ -0,0 +1,43 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReconcileDrawerRejectsAnotherCurrency(t *testing.T) {
+	os := newTestService()
+	if loaded := os.LoadDrawer("front", LoadDrawerRequest{Currency: "USD", Contents: map[string]int{"1": 10, "5": 2}}); !loaded.Success {
+		t.Fatalf("LoadDrawer: %s", loaded.Message)
+	}
+
+	counted := map[string]int{"1": 10, "5": 2}
+	if got := os.ReconcileDrawer(ReconcileDrawerRequest{Currency: "EUR", DrawerID: "front", Counted: counted}); got.Success {
+		t.Fatalf("EUR reconcile of a USD drawer succeeded: %+v", got)
+	}
+	if got := os.ReconcileDrawer(ReconcileDrawerRequest{Currency: "EUR", DrawerID: "front", Expected: counted, Counted: counted}); got.Success {
+		t.Fatalf("EUR reconcile of a USD drawer with expected contents succeeded: %+v", got)
+	}
+	if got := os.ReconcileDrawer(ReconcileDrawerRequest{Currency: "USD", DrawerID: "front", Counted: counted, FloatAmount: 10}); !got.Success {
+		t.Fatalf("USD reconcile failed: %s", got.Message)
+	}
+}
+
+func TestReconcileDrawerFloatAmountCents(t *testing.T) {
+	os := newTestService()
+	counted := map[string]int{"0.1": 3, "1": 10, "5": 2}
+
+	cents := int64(1030)
+	exact := os.ReconcileDrawer(ReconcileDrawerRequest{Currency: "USD", Counted: counted, FloatAmount: 99, FloatAmountCents: &cents})
+	if !exact.Success {
+		t.Fatalf("float_amount_cents reconcile failed: %s", exact.Message)
+	}
+	major := os.ReconcileDrawer(ReconcileDrawerRequest{Currency: "USD", Counted: counted, FloatAmount: 10.30})
+	if !major.Success {
+		t.Fatalf("float_amount reconcile failed: %s", major.Message)
+	}
+	if exact.Deposit != major.Deposit || !reflect.DeepEqual(exact.Keep, major.Keep) {
+		t.Errorf("float_amount_cents 1030 keeps %v and deposits %v; float_amount 10.30 keeps %v and deposits %v",
+			exact.Keep, exact.Deposit, major.Keep, major.Deposit)
+	}
+}
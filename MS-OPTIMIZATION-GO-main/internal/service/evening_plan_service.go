@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"sort"
+	"time"
+)
+
+// Evening plan limits and defaults
+const (
+	maxEveningGroups          = 1000
+	defaultSlotMinutes        = 15
+	defaultWalkInWaitMinutes  = 30
+	maxEveningSlotMinutes     = 60
+	minEveningSlotMinutes     = 5
+	maxEveningForecastPerSlot = 50
+)
+
+// EveningPlanRequest represents a day's reservations and forecast walk-ins to schedule
+// together, on the given tables or a stored floor plan's version for the day of open_at
+type EveningPlanRequest struct {
+	Tables           []EveningTable        `json:"tables,omitempty"`
+	FloorPlanID      string                `json:"floor_plan_id,omitempty"`
+	FloorPlanVersion int                   `json:"floor_plan_version,omitempty"`
+	OpenAt           time.Time             `json:"open_at"`
+	CloseAt          time.Time             `json:"close_at"`               // last seating, at most a day after open_at
+	SlotMinutes      int                   `json:"slot_minutes,omitempty"` // default 15
+	TurnMinutes      float64               `json:"turn_minutes,omitempty"` // default 90
+	Reservations     []EveningReservation  `json:"reservations"`
+	WalkIns          []EveningWalkInDemand `json:"walk_ins,omitempty"`
+
+	// How long a forecast walk-in waits for a table before leaving, default 30
+	WalkInWaitMinutes *float64 `json:"walk_in_wait_minutes,omitempty"`
+
+	// Hard and soft constraints on the tables groups may take, walk-ins by their generated
+	// IDs. A soft constraint's penalty is the minutes of extra wait worth spending to keep it;
+	// reservations are seated at their slot, so for them it only chooses between tables
+	Constraints []AssignmentConstraint `json:"constraints,omitempty"`
+}
+
+// EveningTable describes a table of the evening
+type EveningTable struct {
+	ID         string   `json:"id"`
+	Capacity   int      `json:"capacity"`
+	Zone       string   `json:"zone,omitempty"`       // for constraints, taken from the floor plan when floor_plan_id is given
+	Attributes []string `json:"attributes,omitempty"` // for constraints, as zone
+	X          float64  `json:"x,omitempty"`
+	Y          float64  `json:"y,omitempty"`
+
+	WheelchairAccessible bool `json:"wheelchair_accessible,omitempty"` // as zone
+}
+
+// EveningReservation describes a booking, seated at its time slot or not at all
+type EveningReservation struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name,omitempty"`
+	Size          int       `json:"size"`
+	Time          time.Time `json:"time"`
+	DiningMinutes float64   `json:"dining_minutes,omitempty"` // default turn_minutes
+
+	// Needs a wheelchair-accessible table and is never seated at another
+	WheelchairAccess bool `json:"wheelchair_access,omitempty"`
+}
+
+// EveningWalkInDemand describes forecast walk-ins: count groups of a size arriving at a time
+type EveningWalkInDemand struct {
+	ID            string    `json:"id,omitempty"` // prefix of the generated group IDs
+	Time          time.Time `json:"time"`
+	Size          int       `json:"size"`
+	Count         int       `json:"count,omitempty"` // default 1
+	DiningMinutes float64   `json:"dining_minutes,omitempty"`
+}
+
+// EveningBooking represents one group placed at a table
+type EveningBooking struct {
+	GroupID     string    `json:"group_id"`
+	Name        string    `json:"name,omitempty"`
+	Type        string    `json:"type"` // reservation or walk_in
+	Size        int       `json:"size"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	WaitMinutes float64   `json:"wait_minutes,omitempty"` // walk-ins, from arrival to their slot
+
+	Violations []ConstraintViolation `json:"violations,omitempty"` // soft constraints the booking breaks
+}
+
+// EveningTableSchedule represents one table's evening, slot by slot
+type EveningTableSchedule struct {
+	ID       string           `json:"id"`
+	Capacity int              `json:"capacity"`
+	Slots    []string         `json:"slots"` // group seated in each slot of the response's slots, empty when free
+	Bookings []EveningBooking `json:"bookings"`
+}
+
+// EveningPlanResponse represents the table-by-slot schedule of an evening, tables by ID
+type EveningPlanResponse struct {
+	Success              bool                   `json:"success"`
+	Slots                []time.Time            `json:"slots"`
+	Tables               []EveningTableSchedule `json:"tables"`
+	UnplacedReservations []string               `json:"unplaced_reservations,omitempty"`
+	UnplacedWalkIns      []string               `json:"unplaced_walk_ins,omitempty"`
+	Covers               int                    `json:"covers"`
+	ReservationCovers    int                    `json:"reservation_covers"`
+	WalkInCovers         int                    `json:"walk_in_covers"`
+	SeatUtilization      float64                `json:"seat_utilization"`  // seated seat slots over all seat slots
+	Penalty              float64                `json:"penalty,omitempty"` // total penalty of the soft constraints broken
+	FloorPlanVersion     int                    `json:"floor_plan_version,omitempty"`
+	Message              string                 `json:"message"`
+	NotFound             bool                   `json:"-"`
+}
+
+// PlanEvening builds a schedule of the whole evening, placing the reservations and then as
+// many of the forecast walk-ins as fit around them, to seat as many covers as possible
+func (os *OptimizationService) PlanEvening(ctx context.Context, req EveningPlanRequest) EveningPlanResponse {
+	slotMinutes := req.SlotMinutes
+	if slotMinutes == 0 {
+		slotMinutes = defaultSlotMinutes
+	}
+	if slotMinutes < minEveningSlotMinutes || slotMinutes > maxEveningSlotMinutes {
+		return EveningPlanResponse{Success: false, Message: fmt.Sprintf("slot_minutes must be between %d and %d", minEveningSlotMinutes, maxEveningSlotMinutes)}
+	}
+	if req.OpenAt.IsZero() || !req.CloseAt.After(req.OpenAt) || req.CloseAt.Sub(req.OpenAt) > 24*time.Hour {
+		return EveningPlanResponse{Success: false, Message: "close_at must be after open_at and within a day of it"}
+	}
+	turnMinutes := req.TurnMinutes
+	if turnMinutes == 0 {
+		turnMinutes = defaultTurnMinutes
+	}
+	if !validTurnMinutes(turnMinutes) {
+		return EveningPlanResponse{Success: false, Message: "turn_minutes must be between 1 and 1440"}
+	}
+	waitMinutes := float64(defaultWalkInWaitMinutes)
+	if req.WalkInWaitMinutes != nil {
+		waitMinutes = *req.WalkInWaitMinutes
+		if !(waitMinutes >= 0) || waitMinutes > 24*60 {
+			return EveningPlanResponse{Success: false, Message: "walk_in_wait_minutes must be between 0 and 1440"}
+		}
+	}
+
+	tables := req.Tables
+	floorPlanVersion := 0
+	if req.FloorPlanID != "" {
+		if len(tables) > 0 {
+			return EveningPlanResponse{Success: false, Message: "give tables or floor_plan_id, not both"}
+		}
+		query := FloorPlanQuery{Version: req.FloorPlanVersion}
+		if query.Version == 0 {
+			query.Day = req.OpenAt.Weekday().String()
+		}
+		plan, err := os.resolveFloorPlan(req.FloorPlanID, query)
+		if err != nil {
+			response := floorPlanErrorResponse(req.FloorPlanID, err)
+			return EveningPlanResponse{Success: false, Message: response.Message, NotFound: response.NotFound}
+		}
+		for _, table := range plan.Tables {
+			tables = append(tables, EveningTable{
+				ID:                   table.ID,
+				Capacity:             table.Capacity,
+				Zone:                 table.Zone,
+				Attributes:           table.Attributes,
+				X:                    table.X,
+				Y:                    table.Y,
+				WheelchairAccessible: table.WheelchairAccessible,
+			})
+		}
+		floorPlanVersion = plan.Version
+	} else if req.FloorPlanVersion != 0 {
+		return EveningPlanResponse{Success: false, Message: "floor_plan_version requires floor_plan_id"}
+	}
+	if len(tables) == 0 || len(tables) > maxWaitlistTables {
+		return EveningPlanResponse{Success: false, Message: fmt.Sprintf("between 1 and %d tables are required", maxWaitlistTables)}
+	}
+	// Ties between tables go to the lower ID, as in the waitlist
+	tables = append([]EveningTable(nil), tables...)
+	sort.SliceStable(tables, func(a, b int) bool { return tables[a].ID < tables[b].ID })
+	capacities := make([]int, len(tables))
+	seen := make(map[string]bool, len(tables))
+	for i, table := range tables {
+		if table.ID == "" {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("table %d has no id", i)}
+		}
+		if seen[table.ID] {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("duplicate table id %q", table.ID)}
+		}
+		seen[table.ID] = true
+		if table.Capacity < 1 || table.Capacity > maxTableCapacity {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("capacity of table %s must be between 1 and %d", table.ID, maxTableCapacity)}
+		}
+		capacities[i] = table.Capacity
+	}
+
+	slot := time.Duration(slotMinutes) * time.Minute
+	slots := int(math.Ceil(float64(req.CloseAt.Sub(req.OpenAt)) / float64(slot)))
+	// slotOf returns the slot a time falls in, or an error if it is outside the evening
+	slotOf := func(at time.Time) (int, error) {
+		if at.Before(req.OpenAt) || !at.Before(req.CloseAt) {
+			return 0, errors.New("must be from open_at until before close_at")
+		}
+		return int(at.Sub(req.OpenAt) / slot), nil
+	}
+	length := func(dining float64) (int, error) {
+		if dining == 0 {
+			dining = turnMinutes
+		}
+		if !validTurnMinutes(dining) {
+			return 0, errors.New("dining_minutes must be between 1 and 1440")
+		}
+		return int(math.Ceil(dining / float64(slotMinutes))), nil
+	}
+
+	type eveningGroup struct {
+		id, name, kind string
+		arrival        time.Time
+		access         bool
+	}
+	var groups []algorithms.EveningGroup
+	var info []eveningGroup
+	// Reservations are planned in ID order, so ties between them fall the same way whatever
+	// order the request lists them in; order[k] is the request position of the k-th by ID
+	order := make([]int, len(req.Reservations))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return req.Reservations[order[a]].ID < req.Reservations[order[b]].ID })
+	seen = make(map[string]bool, len(req.Reservations))
+	for _, i := range order {
+		reservation := req.Reservations[i]
+		if reservation.ID == "" {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("reservation %d has no id", i)}
+		}
+		if seen[reservation.ID] {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("duplicate reservation id %q", reservation.ID)}
+		}
+		seen[reservation.ID] = true
+		if reservation.Size < 1 || reservation.Size > maxTableCapacity {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("size of reservation %s must be between 1 and %d", reservation.ID, maxTableCapacity)}
+		}
+		start, err := slotOf(reservation.Time)
+		if err != nil {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("time of reservation %s %v", reservation.ID, err)}
+		}
+		stay, err := length(reservation.DiningMinutes)
+		if err != nil {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("reservation %s: %v", reservation.ID, err)}
+		}
+		groups = append(groups, algorithms.EveningGroup{Size: reservation.Size, Start: start, Latest: start, Length: stay, Reserved: true})
+		info = append(info, eveningGroup{id: reservation.ID, name: reservation.Name, kind: GroupTypeReservation, arrival: reservation.Time, access: reservation.WheelchairAccess})
+	}
+
+	waitSlots := int(waitMinutes / float64(slotMinutes))
+	// Walk-in demands likewise go in ID order; those without an ID keep their request order
+	order = make([]int, len(req.WalkIns))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return req.WalkIns[order[a]].ID < req.WalkIns[order[b]].ID })
+	for _, i := range order {
+		demand := req.WalkIns[i]
+		if demand.Size < 1 || demand.Size > maxTableCapacity {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("size of walk-in %d must be between 1 and %d", i, maxTableCapacity)}
+		}
+		count := demand.Count
+		if count == 0 {
+			count = 1
+		}
+		if count < 0 || count > maxEveningForecastPerSlot {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("count of walk-in %d must be between 1 and %d", i, maxEveningForecastPerSlot)}
+		}
+		start, err := slotOf(demand.Time)
+		if err != nil {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("time of walk-in %d %v", i, err)}
+		}
+		stay, err := length(demand.DiningMinutes)
+		if err != nil {
+			return EveningPlanResponse{Success: false, Message: fmt.Sprintf("walk-in %d: %v", i, err)}
+		}
+		prefix := demand.ID
+		if prefix == "" {
+			prefix = fmt.Sprintf("walk-in-%d", i+1)
+		}
+		for n := 0; n < count; n++ {
+			groups = append(groups, algorithms.EveningGroup{Size: demand.Size, Start: start, Latest: start + waitSlots, Length: stay})
+			info = append(info, eveningGroup{id: fmt.Sprintf("%s-%d", prefix, n+1), kind: GroupTypeWalkIn, arrival: demand.Time})
+		}
+	}
+	if len(groups) == 0 || len(groups) > maxEveningGroups {
+		return EveningPlanResponse{Success: false, Message: fmt.Sprintf("between 1 and %d reservations and forecast walk-ins are required", maxEveningGroups)}
+	}
+
+	groupIDs, sizes, access := make([]string, len(groups)), make([]int, len(groups)), make([]bool, len(groups))
+	for g, group := range groups {
+		groupIDs[g], sizes[g], access[g] = info[g].id, group.Size, info[g].access
+	}
+	constraintTables := make([]constraintTable, len(tables))
+	for t, table := range tables {
+		constraintTables[t] = constraintTable{Capacity: table.Capacity, Zone: table.Zone, Attributes: table.Attributes, X: table.X, Y: table.Y, Accessible: table.WheelchairAccessible}
+	}
+	constraints, err := compileConstraints(req.Constraints, groupIDs, access, constraintTables)
+	if err != nil {
+		return EveningPlanResponse{Success: false, Message: err.Error()}
+	}
+	// The planner counts delays in slots, so penalties in minutes are converted
+	if penalty := constraints.penalties(sizes); penalty != nil {
+		for g := range groups {
+			for t := range penalty[g] {
+				penalty[g][t] /= float64(slotMinutes)
+			}
+			groups[g].Penalty = penalty[g]
+		}
+	}
+
+	plan := algorithms.PlanEvening(ctx, capacities, slots, groups)
+	if !plan.Success {
+		return EveningPlanResponse{Success: false, Message: plan.Message}
+	}
+
+	response := EveningPlanResponse{
+		Success:          true,
+		Slots:            make([]time.Time, slots),
+		Tables:           make([]EveningTableSchedule, len(tables)),
+		Covers:           plan.Covers,
+		FloorPlanVersion: floorPlanVersion,
+		Message:          plan.Message,
+	}
+	for s := range response.Slots {
+		response.Slots[s] = req.OpenAt.Add(time.Duration(s) * slot)
+	}
+	seatSlots, usedSeatSlots := 0, 0
+	for t, table := range tables {
+		schedule := EveningTableSchedule{ID: table.ID, Capacity: table.Capacity, Slots: make([]string, slots), Bookings: []EveningBooking{}}
+		for s, g := range plan.Grid[t] {
+			if g >= 0 {
+				schedule.Slots[s] = info[g].id
+				usedSeatSlots += groups[g].Size
+			}
+		}
+		seatSlots += table.Capacity * slots
+		response.Tables[t] = schedule
+	}
+	response.SeatUtilization = float64(usedSeatSlots) / float64(seatSlots)
+
+	for _, placement := range plan.Placements {
+		g := info[placement.Group]
+		start := req.OpenAt.Add(time.Duration(placement.Start) * slot)
+		booking := EveningBooking{
+			GroupID: g.id,
+			Name:    g.name,
+			Type:    g.kind,
+			Size:    groups[placement.Group].Size,
+			Start:   start,
+			End:     start.Add(time.Duration(groups[placement.Group].Length) * slot),
+
+			Violations: constraints.violations(placement.Group, groups[placement.Group].Size, placement.Table),
+		}
+		for _, violation := range booking.Violations {
+			response.Penalty += violation.Penalty
+		}
+		if g.kind == GroupTypeWalkIn {
+			booking.WaitMinutes = math.Max(0, start.Sub(g.arrival).Minutes())
+			response.WalkInCovers += booking.Size
+		} else {
+			response.ReservationCovers += booking.Size
+		}
+		response.Tables[placement.Table].Bookings = append(response.Tables[placement.Table].Bookings, booking)
+	}
+	for t := range response.Tables {
+		bookings := response.Tables[t].Bookings
+		sort.Slice(bookings, func(a, b int) bool { return bookings[a].Start.Before(bookings[b].Start) })
+	}
+	for _, g := range plan.Unplaced {
+		if info[g].kind == GroupTypeWalkIn {
+			response.UnplacedWalkIns = append(response.UnplacedWalkIns, info[g].id)
+		} else {
+			response.UnplacedReservations = append(response.UnplacedReservations, info[g].id)
+		}
+	}
+	return response
+}
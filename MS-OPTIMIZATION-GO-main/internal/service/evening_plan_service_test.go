@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanEveningIgnoresRequestOrder(t *testing.T) {
+	open := time.Date(2026, 3, 6, 18, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return open.Add(time.Duration(minutes) * time.Minute) }
+
+	tables := []EveningTable{{ID: "a", Capacity: 2}, {ID: "b", Capacity: 2}, {ID: "c", Capacity: 4}, {ID: "d", Capacity: 4}}
+	reservations := []EveningReservation{
+		{ID: "r1", Size: 2, Time: at(0)},
+		{ID: "r2", Size: 2, Time: at(0)},
+		{ID: "r3", Size: 2, Time: at(0)},
+		{ID: "r4", Size: 4, Time: at(60)},
+		{ID: "r5", Size: 3, Time: at(60)},
+		{ID: "r6", Size: 4, Time: at(90)},
+	}
+	walkIns := []EveningWalkInDemand{
+		{ID: "bar", Size: 2, Time: at(30), Count: 2},
+		{ID: "patio", Size: 4, Time: at(30)},
+	}
+	req := EveningPlanRequest{OpenAt: open, CloseAt: at(240), Tables: tables, Reservations: reservations, WalkIns: walkIns}
+
+	os := newTestService()
+	want := os.PlanEvening(context.Background(), req)
+	if !want.Success {
+		t.Fatalf("PlanEvening: %s", want.Message)
+	}
+
+	reordered := req
+	reordered.Tables = []EveningTable{tables[3], tables[1], tables[0], tables[2]}
+	reordered.Reservations = []EveningReservation{reservations[5], reservations[2], reservations[4], reservations[0], reservations[3], reservations[1]}
+	reordered.WalkIns = []EveningWalkInDemand{walkIns[1], walkIns[0]}
+	if got := os.PlanEvening(context.Background(), reordered); !reflect.DeepEqual(got, want) {
+		t.Errorf("reordered request planned\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"sort"
+)
+
+// Event seating limits
+const (
+	maxEventGuests     = 500
+	maxEventTables     = 100
+	maxEventGuestPairs = 5000
+	maxAffinityWeight  = 100
+)
+
+// EventSeatingRequest represents a private event's guest list and tables
+type EventSeatingRequest struct {
+	Guests     []EventGuest    `json:"guests"`
+	Tables     []EventTable    `json:"tables"`
+	Affinities []EventAffinity `json:"affinities,omitempty"` // guests who should share a table
+	Avoid      []EventAvoid    `json:"avoid,omitempty"`      // guests who must not share a table
+
+	// Hard and soft constraints on the tables guests may take, each guest being a group of
+	// one. No wait is traded for soft ones, so the lowest total penalty comes before the
+	// affinities, and keeping avoid pairs apart before both
+	Constraints []AssignmentConstraint `json:"constraints,omitempty"`
+}
+
+// EventGuest describes one guest
+type EventGuest struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+
+	// Needs a wheelchair-accessible table and is never seated at another
+	WheelchairAccess bool `json:"wheelchair_access,omitempty"`
+}
+
+// EventTable describes one table and its seat count
+type EventTable struct {
+	ID         string   `json:"id"`
+	Seats      int      `json:"seats"`
+	Zone       string   `json:"zone,omitempty"`       // for constraints
+	Attributes []string `json:"attributes,omitempty"` // for constraints
+	X          float64  `json:"x,omitempty"`
+	Y          float64  `json:"y,omitempty"`
+
+	WheelchairAccessible bool `json:"wheelchair_accessible,omitempty"`
+}
+
+// EventAffinity describes two guests who would like to sit together. Weight defaults to 1;
+// a negative weight prefers them apart without forbidding it
+type EventAffinity struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// EventAvoid describes two guests who must not share a table
+type EventAvoid struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// EventSeat represents one guest's seat. Seats are numbered around the table from 1, so
+// the last seat is next to the first
+type EventSeat struct {
+	Seat       int                   `json:"seat"`
+	GuestID    string                `json:"guest_id"`
+	Name       string                `json:"name,omitempty"`
+	Violations []ConstraintViolation `json:"violations,omitempty"` // soft constraints the seat breaks
+}
+
+// EventTableChart represents the guests seated at one table
+type EventTableChart struct {
+	ID     string      `json:"id"`
+	Seats  int         `json:"seats"`
+	Guests []EventSeat `json:"guests"`
+	Score  float64     `json:"score"` // total weight of the affinity pairs at this table
+}
+
+// EventSeatingResponse represents the seating chart of an event
+type EventSeatingResponse struct {
+	Success    bool              `json:"success"`
+	Tables     []EventTableChart `json:"tables"`
+	Score      float64           `json:"score"`                // total weight of the affinity pairs sharing a table
+	Violations []EventAvoid      `json:"violations,omitempty"` // avoid pairs the seats forced together
+	Penalty    float64           `json:"penalty,omitempty"`    // total penalty of the soft constraints broken
+	Message    string            `json:"message"`
+}
+
+// PlanEventSeating builds a seat-level chart for an event, seating guests with affinities
+// together and keeping avoid pairs at different tables. Guests and tables go to the planner
+// ordered by ID, so ties fall the same way whatever order the request lists them in
+func (os *OptimizationService) PlanEventSeating(req EventSeatingRequest) EventSeatingResponse {
+	if len(req.Guests) == 0 || len(req.Guests) > maxEventGuests {
+		return EventSeatingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d guests are required", maxEventGuests)}
+	}
+	if len(req.Tables) == 0 || len(req.Tables) > maxEventTables {
+		return EventSeatingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d tables are required", maxEventTables)}
+	}
+	if len(req.Affinities)+len(req.Avoid) > maxEventGuestPairs {
+		return EventSeatingResponse{Success: false, Message: fmt.Sprintf("at most %d affinity and avoid pairs are allowed", maxEventGuestPairs)}
+	}
+
+	guestIndex := make(map[string]int, len(req.Guests))
+	for i, guest := range req.Guests {
+		if guest.ID == "" {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("guest %d has no id", i)}
+		}
+		if _, ok := guestIndex[guest.ID]; ok {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("duplicate guest id %q", guest.ID)}
+		}
+		guestIndex[guest.ID] = i
+	}
+	guests := append([]EventGuest(nil), req.Guests...)
+	sort.Slice(guests, func(a, b int) bool { return guests[a].ID < guests[b].ID })
+	for i, guest := range guests {
+		guestIndex[guest.ID] = i
+	}
+
+	// order[k] is the request position of the k-th table by ID
+	order := make([]int, len(req.Tables))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return req.Tables[order[a]].ID < req.Tables[order[b]].ID })
+	seats := make([]int, len(req.Tables))
+	seen := make(map[string]bool, len(req.Tables))
+	for i, table := range req.Tables {
+		if table.ID == "" {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("table %d has no id", i)}
+		}
+		if seen[table.ID] {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("duplicate table id %q", table.ID)}
+		}
+		seen[table.ID] = true
+		if table.Seats < 1 || table.Seats > maxTableCapacity {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("seats of table %s must be between 1 and %d", table.ID, maxTableCapacity)}
+		}
+	}
+	guestIDs, sizes, access := make([]string, len(guests)), make([]int, len(guests)), make([]bool, len(guests))
+	for g, guest := range guests {
+		guestIDs[g], sizes[g], access[g] = guest.ID, 1, guest.WheelchairAccess
+	}
+	constraintTables := make([]constraintTable, len(order))
+	for k, i := range order {
+		table := req.Tables[i]
+		seats[k] = table.Seats
+		constraintTables[k] = constraintTable{Capacity: table.Seats, Zone: table.Zone, Attributes: table.Attributes, X: table.X, Y: table.Y, Accessible: table.WheelchairAccessible}
+	}
+	constraints, err := compileConstraints(req.Constraints, guestIDs, access, constraintTables)
+	if err != nil {
+		return EventSeatingResponse{Success: false, Message: err.Error()}
+	}
+
+	pair := func(a, b string) (algorithms.GuestPair, error) {
+		i, ok := guestIndex[a]
+		if !ok {
+			return algorithms.GuestPair{}, fmt.Errorf("unknown guest %q", a)
+		}
+		j, ok := guestIndex[b]
+		if !ok {
+			return algorithms.GuestPair{}, fmt.Errorf("unknown guest %q", b)
+		}
+		if i == j {
+			return algorithms.GuestPair{}, fmt.Errorf("guest %s is paired with itself", a)
+		}
+		return algorithms.GuestPair{A: i, B: j}, nil
+	}
+
+	affinities := make([]algorithms.GuestPair, len(req.Affinities))
+	for i, affinity := range req.Affinities {
+		p, err := pair(affinity.A, affinity.B)
+		if err != nil {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("affinity %d: %v", i, err)}
+		}
+		p.Weight = affinity.Weight
+		if p.Weight == 0 {
+			p.Weight = 1
+		}
+		if math.IsNaN(p.Weight) || math.Abs(p.Weight) > maxAffinityWeight {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("affinity %d: weight must be between -%d and %d", i, maxAffinityWeight, maxAffinityWeight)}
+		}
+		affinities[i] = p
+	}
+	avoid := make([]algorithms.GuestPair, len(req.Avoid))
+	for i, pairing := range req.Avoid {
+		p, err := pair(pairing.A, pairing.B)
+		if err != nil {
+			return EventSeatingResponse{Success: false, Message: fmt.Sprintf("avoid %d: %v", i, err)}
+		}
+		avoid[i] = p
+	}
+
+	plan := algorithms.PlanEventSeating(len(guests), seats, affinities, avoid, constraints.penalties(sizes))
+	if !plan.Success {
+		return EventSeatingResponse{Success: false, Message: plan.Message}
+	}
+
+	tableOf := make([]int, len(guests))
+	tables := make([]EventTableChart, len(req.Tables))
+	penalty := 0.0
+	for k, chart := range plan.Tables {
+		t := order[k]
+		tables[t] = EventTableChart{ID: req.Tables[t].ID, Seats: req.Tables[t].Seats, Guests: make([]EventSeat, len(chart))}
+		for seat, g := range chart {
+			tableOf[g] = t
+			guest := guests[g]
+			violations := constraints.violations(g, 1, k)
+			for _, violation := range violations {
+				penalty += violation.Penalty
+			}
+			tables[t].Guests[seat] = EventSeat{Seat: seat + 1, GuestID: guest.ID, Name: guest.Name, Violations: violations}
+		}
+	}
+	for _, p := range affinities {
+		if tableOf[p.A] == tableOf[p.B] {
+			tables[tableOf[p.A]].Score += p.Weight
+		}
+	}
+
+	violations := make([]EventAvoid, len(plan.Violations))
+	for i, p := range plan.Violations {
+		violations[i] = EventAvoid{A: guests[p.A].ID, B: guests[p.B].ID}
+	}
+
+	return EventSeatingResponse{
+		Success:    true,
+		Tables:     tables,
+		Score:      plan.Score,
+		Violations: violations,
+		Penalty:    penalty,
+		Message:    plan.Message,
+	}
+}
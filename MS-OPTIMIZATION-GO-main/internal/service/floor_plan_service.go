@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/models"
+	"ms-optimization-go/internal/repository"
+	"strings"
+	"time"
+)
+
+// maxFloorPlanNameLength bounds the display name of a floor plan
+const maxFloorPlanNameLength = 200
+
+// SaveFloorPlanRequest represents a new version of a floor plan
+type SaveFloorPlanRequest struct {
+	Name   string                  `json:"name,omitempty"`
+	Days   []string                `json:"days,omitempty"` // weekdays (e.g. "friday") this layout is used on, empty for every day
+	Tables []models.FloorPlanTable `json:"tables"`
+}
+
+// FloorPlanQuery selects a version of a floor plan: an explicit version, the latest one
+// used on a weekday, or the latest one overall when both are empty
+type FloorPlanQuery struct {
+	Version int    `form:"version"`
+	Day     string `form:"day"`
+}
+
+// FloorPlanResponse represents one version of a floor plan
+type FloorPlanResponse struct {
+	Success   bool              `json:"success"`
+	FloorPlan *models.FloorPlan `json:"floor_plan,omitempty"`
+	Message   string            `json:"message"`
+	NotFound  bool              `json:"-"`
+}
+
+// FloorPlanVersion summarizes one version of a floor plan
+type FloorPlanVersion struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name,omitempty"`
+	Days      []string  `json:"days,omitempty"`
+	Tables    int       `json:"tables"`
+	Seats     int       `json:"seats"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FloorPlanVersionsResponse represents the version history of a floor plan
+type FloorPlanVersionsResponse struct {
+	Success     bool               `json:"success"`
+	FloorPlanID string             `json:"floor_plan_id"`
+	Versions    []FloorPlanVersion `json:"versions"`
+	Message     string             `json:"message"`
+	NotFound    bool               `json:"-"`
+}
+
+// SaveFloorPlan stores a layout as the next version of the floor plan, creating it if needed
+func (os *OptimizationService) SaveFloorPlan(id string, req SaveFloorPlanRequest) FloorPlanResponse {
+	if id == "" || len(id) > 100 {
+		return FloorPlanResponse{Success: false, Message: "floor plan id must be between 1 and 100 characters"}
+	}
+	if len(req.Name) > maxFloorPlanNameLength {
+		return FloorPlanResponse{Success: false, Message: fmt.Sprintf("name must be at most %d characters", maxFloorPlanNameLength)}
+	}
+
+	days, err := normalizeWeekdays(req.Days)
+	if err != nil {
+		return FloorPlanResponse{Success: false, Message: err.Error()}
+	}
+	if err := validateFloorPlanTables(req.Tables); err != nil {
+		return FloorPlanResponse{Success: false, Message: err.Error()}
+	}
+
+	plan := &models.FloorPlan{
+		ID:     id,
+		Name:   req.Name,
+		Days:   days,
+		Tables: req.Tables,
+	}
+	if err := os.floorPlanRepo.AddFloorPlanVersion(plan); err != nil {
+		return FloorPlanResponse{Success: false, Message: fmt.Sprintf("Floor plan operation failed: %v", err)}
+	}
+
+	return FloorPlanResponse{
+		Success:   true,
+		FloorPlan: plan,
+		Message:   fmt.Sprintf("Floor plan %s saved as version %d", id, plan.Version),
+	}
+}
+
+// GetFloorPlan returns the version of a floor plan selected by the query
+func (os *OptimizationService) GetFloorPlan(id string, query FloorPlanQuery) FloorPlanResponse {
+	plan, err := os.resolveFloorPlan(id, query)
+	if err != nil {
+		return floorPlanErrorResponse(id, err)
+	}
+
+	return FloorPlanResponse{
+		Success:   true,
+		FloorPlan: plan,
+		Message:   fmt.Sprintf("Floor plan %s version %d", id, plan.Version),
+	}
+}
+
+// ListFloorPlanVersions returns the version history of a floor plan, oldest first
+func (os *OptimizationService) ListFloorPlanVersions(id string) FloorPlanVersionsResponse {
+	plans, err := os.floorPlanRepo.ListFloorPlanVersions(id)
+	if err != nil {
+		response := floorPlanErrorResponse(id, err)
+		return FloorPlanVersionsResponse{Success: false, FloorPlanID: id, Message: response.Message, NotFound: response.NotFound}
+	}
+
+	versions := make([]FloorPlanVersion, len(plans))
+	for i, plan := range plans {
+		seats := 0
+		for _, table := range plan.Tables {
+			seats += table.Capacity
+		}
+		versions[i] = FloorPlanVersion{
+			Version:   plan.Version,
+			Name:      plan.Name,
+			Days:      plan.Days,
+			Tables:    len(plan.Tables),
+			Seats:     seats,
+			CreatedAt: plan.CreatedAt,
+		}
+	}
+
+	return FloorPlanVersionsResponse{
+		Success:     true,
+		FloorPlanID: id,
+		Versions:    versions,
+		Message:     fmt.Sprintf("%d versions of floor plan %s", len(versions), id),
+	}
+}
+
+// resolveFloorPlan loads the version of a floor plan selected by the query
+func (os *OptimizationService) resolveFloorPlan(id string, query FloorPlanQuery) (*models.FloorPlan, error) {
+	if query.Version != 0 && query.Day != "" {
+		return nil, errors.New("give version or day, not both")
+	}
+	if query.Version < 0 {
+		return nil, errors.New("version must be positive")
+	}
+
+	plans, err := os.floorPlanRepo.ListFloorPlanVersions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Version != 0 {
+		if query.Version > len(plans) {
+			return nil, repository.ErrFloorPlanNotFound
+		}
+		return plans[query.Version-1], nil
+	}
+	if query.Day == "" {
+		return plans[len(plans)-1], nil
+	}
+
+	day, ok := parseWeekday(query.Day)
+	if !ok {
+		return nil, fmt.Errorf("invalid day %q, expected a weekday such as friday", query.Day)
+	}
+	for i := len(plans) - 1; i >= 0; i-- {
+		if plans[i].UsedOn(day) {
+			return plans[i], nil
+		}
+	}
+	return nil, repository.ErrFloorPlanNotFound
+}
+
+// validateFloorPlanTables checks table ids, capacities and adjacency references
+func validateFloorPlanTables(tables []models.FloorPlanTable) error {
+	if len(tables) == 0 || len(tables) > maxWaitlistTables {
+		return fmt.Errorf("between 1 and %d tables are required", maxWaitlistTables)
+	}
+
+	ids := make(map[string]bool, len(tables))
+	for i, table := range tables {
+		if table.ID == "" {
+			return fmt.Errorf("table %d has no id", i)
+		}
+		if ids[table.ID] {
+			return fmt.Errorf("duplicate table id %q", table.ID)
+		}
+		ids[table.ID] = true
+		if table.Capacity < 1 || table.Capacity > maxTableCapacity {
+			return fmt.Errorf("capacity of table %s must be between 1 and %d", table.ID, maxTableCapacity)
+		}
+		if math.IsInf(table.X, 0) || math.IsInf(table.Y, 0) {
+			return fmt.Errorf("coordinates of table %s must be finite", table.ID)
+		}
+		for _, attribute := range table.Attributes {
+			if attribute == "" {
+				return fmt.Errorf("table %s has an empty attribute", table.ID)
+			}
+		}
+	}
+
+	for _, table := range tables {
+		for _, adjacent := range table.Adjacent {
+			if adjacent == table.ID || !ids[adjacent] {
+				return fmt.Errorf("table %s lists unknown adjacent table %q", table.ID, adjacent)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeWeekdays lowercases weekday names and rejects unknown or repeated days
+func normalizeWeekdays(days []string) ([]string, error) {
+	normalized := make([]string, 0, len(days))
+	seen := make(map[time.Weekday]bool, len(days))
+	for _, name := range days {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q, expected a weekday such as friday", name)
+		}
+		if seen[day] {
+			return nil, fmt.Errorf("day %s is listed twice", strings.ToLower(day.String()))
+		}
+		seen[day] = true
+		normalized = append(normalized, strings.ToLower(day.String()))
+	}
+	return normalized, nil
+}
+
+// parseWeekday parses an English weekday name, ignoring case
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// floorPlanErrorResponse maps floor plan lookup errors to a failed response
+func floorPlanErrorResponse(id string, err error) FloorPlanResponse {
+	if errors.Is(err, repository.ErrFloorPlanNotFound) {
+		return FloorPlanResponse{
+			Success:  false,
+			Message:  fmt.Sprintf("Floor plan %s not found", id),
+			NotFound: true,
+		}
+	}
+
+	return FloorPlanResponse{Success: false, Message: err.Error()}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Happy hour limits and defaults
+const (
+	maxHappyHourLevels     = 20
+	maxHappyHourWindows    = 4
+	maxHappyHourUplift     = 10
+	defaultHappyHourMin    = 1
+	defaultHappyHourMax    = 3
+	defaultHappyHourWindow = 1
+)
+
+// HappyHourRequest represents a day's average sales by hour and the discount levels to
+// consider, each with the rise in drinks sold it is expected to bring. Hours come in opening
+// order, and a window cannot span a missing hour
+type HappyHourRequest struct {
+	Hours          []HourlySales       `json:"hours"`
+	DiscountLevels []HappyHourDiscount `json:"discount_levels"`
+	MinHours       int                 `json:"min_hours,omitempty"`   // shortest window, default 1
+	MaxHours       int                 `json:"max_hours,omitempty"`   // longest window, default 3
+	MaxWindows     int                 `json:"max_windows,omitempty"` // windows in the day, default 1
+}
+
+// HourlySales describes an hour's historical sales
+type HourlySales struct {
+	Hour    int     `json:"hour"`    // 0 to 23
+	Revenue float64 `json:"revenue"` // at full price
+	Margin  float64 `json:"margin"`  // share of revenue left after the cost of goods, 0 to 1
+}
+
+// HappyHourDiscount describes a discount level and its assumed effect on demand
+type HappyHourDiscount struct {
+	Discount float64 `json:"discount"` // share taken off the price, e.g. 0.2
+	Uplift   float64 `json:"uplift"`   // relative rise in units sold, e.g. 0.5 for half as many again
+}
+
+// HappyHourWindow represents a proposed happy hour
+type HappyHourWindow struct {
+	StartHour        int     `json:"start_hour"`
+	EndHour          int     `json:"end_hour"` // exclusive
+	Hours            int     `json:"hours"`
+	Discount         float64 `json:"discount"`
+	Uplift           float64 `json:"uplift"`
+	BaselineRevenue  float64 `json:"baseline_revenue"`
+	ProjectedRevenue float64 `json:"projected_revenue"`
+	BaselineProfit   float64 `json:"baseline_profit"`
+	ProjectedProfit  float64 `json:"projected_profit"`
+	ProfitGain       float64 `json:"profit_gain"`
+}
+
+// HappyHourResponse represents the proposed happy hours and the day's projected profit
+type HappyHourResponse struct {
+	Success          bool              `json:"success"`
+	Windows          []HappyHourWindow `json:"windows"`
+	BaselineProfit   float64           `json:"baseline_profit"`
+	ProjectedProfit  float64           `json:"projected_profit"`
+	ProjectedRevenue float64           `json:"projected_revenue"`
+	ProfitGain       float64           `json:"profit_gain"`
+	Message          string            `json:"message"`
+}
+
+// PlanHappyHour proposes happy-hour windows and discounts that raise the day's projected
+// profit the most. A discount d with uplift u turns an hour's profit from revenue · margin
+// into revenue · (1+u) · (margin-d): more drinks, each earning d less of its price
+func (os *OptimizationService) PlanHappyHour(req HappyHourRequest) HappyHourResponse {
+	if len(req.Hours) == 0 || len(req.Hours) > 24 {
+		return HappyHourResponse{Success: false, Message: "between 1 and 24 hours are required"}
+	}
+	if len(req.DiscountLevels) == 0 || len(req.DiscountLevels) > maxHappyHourLevels {
+		return HappyHourResponse{Success: false, Message: fmt.Sprintf("between 1 and %d discount levels are required", maxHappyHourLevels)}
+	}
+	minHours, maxHours, maxWindows := req.MinHours, req.MaxHours, req.MaxWindows
+	if minHours == 0 {
+		minHours = defaultHappyHourMin
+	}
+	if maxHours == 0 {
+		maxHours = defaultHappyHourMax
+	}
+	if maxWindows == 0 {
+		maxWindows = defaultHappyHourWindow
+	}
+	if minHours < 1 || maxHours < minHours || maxHours > 24 {
+		return HappyHourResponse{Success: false, Message: "min_hours and max_hours must satisfy 1 <= min_hours <= max_hours <= 24"}
+	}
+	if maxWindows < 1 || maxWindows > maxHappyHourWindows {
+		return HappyHourResponse{Success: false, Message: fmt.Sprintf("max_windows must be between 1 and %d", maxHappyHourWindows)}
+	}
+	for i, level := range req.DiscountLevels {
+		if !(level.Discount > 0 && level.Discount < 1) {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("discount of level %d must be above 0 and below 1", i)}
+		}
+		if !(level.Uplift >= -1) || level.Uplift > maxHappyHourUplift {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("uplift of level %d must be between -1 and %d", i, maxHappyHourUplift)}
+		}
+	}
+
+	seen := make(map[int]bool, len(req.Hours))
+	follows := make([]bool, len(req.Hours))
+	gain := make([][]float64, len(req.Hours))
+	baseline := 0.0
+	revenue := 0.0
+	for h, hour := range req.Hours {
+		if hour.Hour < 0 || hour.Hour > 23 {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("hour %d must be between 0 and 23", hour.Hour)}
+		}
+		if seen[hour.Hour] {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("duplicate hour %d", hour.Hour)}
+		}
+		seen[hour.Hour] = true
+		if !(hour.Revenue >= 0) || math.IsInf(hour.Revenue, 0) {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("revenue of hour %d must be a non-negative number", hour.Hour)}
+		}
+		if !(hour.Margin >= 0 && hour.Margin <= 1) {
+			return HappyHourResponse{Success: false, Message: fmt.Sprintf("margin of hour %d must be between 0 and 1", hour.Hour)}
+		}
+		follows[h] = h > 0 && hour.Hour == (req.Hours[h-1].Hour+1)%24
+		baseline += hour.Revenue * hour.Margin
+		revenue += hour.Revenue
+		gain[h] = make([]float64, len(req.DiscountLevels))
+		for k, level := range req.DiscountLevels {
+			gain[h][k] = happyHourProfit(hour, level) - hour.Revenue*hour.Margin
+		}
+	}
+
+	plan := algorithms.PlanPromotionWindows(gain, follows, minHours, maxHours, maxWindows)
+	if !plan.Success {
+		return HappyHourResponse{Success: false, Message: plan.Message}
+	}
+
+	windows := make([]HappyHourWindow, 0, len(plan.Windows))
+	for _, w := range plan.Windows {
+		level := req.DiscountLevels[w.Option]
+		window := HappyHourWindow{
+			StartHour: req.Hours[w.Start].Hour,
+			EndHour:   (req.Hours[w.End-1].Hour + 1) % 24,
+			Hours:     w.End - w.Start,
+			Discount:  level.Discount,
+			Uplift:    level.Uplift,
+		}
+		for _, hour := range req.Hours[w.Start:w.End] {
+			window.BaselineRevenue += hour.Revenue
+			window.ProjectedRevenue += hour.Revenue * (1 + level.Uplift) * (1 - level.Discount)
+			window.BaselineProfit += hour.Revenue * hour.Margin
+			window.ProjectedProfit += happyHourProfit(hour, level)
+		}
+		window.ProfitGain = window.ProjectedProfit - window.BaselineProfit
+		revenue += window.ProjectedRevenue - window.BaselineRevenue
+		windows = append(windows, window)
+	}
+
+	message := fmt.Sprintf("%d happy hours raise projected profit by %.2f", len(windows), plan.Gain)
+	if len(windows) == 0 {
+		message = "No happy hour raises projected profit at these discount levels"
+	}
+	return HappyHourResponse{
+		Success:          true,
+		Windows:          windows,
+		BaselineProfit:   baseline,
+		ProjectedProfit:  baseline + plan.Gain,
+		ProjectedRevenue: revenue,
+		ProfitGain:       plan.Gain,
+		Message:          message,
+	}
+}
+
+// happyHourProfit returns an hour's projected profit at a discount level
+func happyHourProfit(hour HourlySales, level HappyHourDiscount) float64 {
+	return hour.Revenue * (1 + level.Uplift) * (hour.Margin - level.Discount)
+}
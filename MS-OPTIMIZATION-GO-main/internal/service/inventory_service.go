@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Limits applied to inventory planning requests
+const (
+	maxInventoryProducts = 500
+	defaultDaysPerYear   = 365
+)
+
+// OrderPolicyRequest represents a request to plan when and how much to order per product
+type OrderPolicyRequest struct {
+	Products    []OrderPolicyProduct `json:"products"`
+	DaysPerYear float64              `json:"days_per_year,omitempty"` // selling days per year, default 365
+}
+
+// OrderPolicyProduct describes the demand and costs of one product
+type OrderPolicyProduct struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name,omitempty"`
+	AnnualDemand float64 `json:"annual_demand"` // units per year
+	OrderingCost float64 `json:"ordering_cost"` // fixed cost per order (delivery, paperwork)
+	HoldingCost  float64 `json:"holding_cost"`  // cost of storing one unit for a year
+	LeadTimeDays float64 `json:"lead_time_days,omitempty"`
+	PackSize     int     `json:"pack_size,omitempty"`    // units per case, default 1
+	SafetyStock  float64 `json:"safety_stock,omitempty"` // units kept for demand surprises
+}
+
+// OrderPolicyResult represents the order plan for one product
+type OrderPolicyResult struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name,omitempty"`
+	EOQ               float64 `json:"eoq"`
+	OrderQuantity     int     `json:"order_quantity"` // EOQ rounded to whole packs
+	OrdersPerYear     float64 `json:"orders_per_year"`
+	OrderIntervalDays float64 `json:"order_interval_days"`
+	ReorderPoint      float64 `json:"reorder_point"`
+	AnnualOrderCost   float64 `json:"annual_order_cost"`
+	AnnualHoldingCost float64 `json:"annual_holding_cost"`
+	TotalAnnualCost   float64 `json:"total_annual_cost"`
+}
+
+// OrderPolicyResponse represents the order plans for a set of products
+type OrderPolicyResponse struct {
+	Success         bool                `json:"success"`
+	Products        []OrderPolicyResult `json:"products"`
+	TotalAnnualCost float64             `json:"total_annual_cost"`
+	Errors          []ItemError         `json:"errors,omitempty"` // one entry per invalid product field
+	Message         string              `json:"message"`
+
+	Invalid bool `json:"-"`
+}
+
+// CalculateOrderPolicies computes the economic order quantity, order frequency and reorder
+// point of each product
+func (os *OptimizationService) CalculateOrderPolicies(req OrderPolicyRequest) OrderPolicyResponse {
+	if len(req.Products) == 0 || len(req.Products) > maxInventoryProducts {
+		return OrderPolicyResponse{Success: false, Message: fmt.Sprintf("between 1 and %d products are required", maxInventoryProducts)}
+	}
+
+	daysPerYear := req.DaysPerYear
+	if daysPerYear == 0 {
+		daysPerYear = defaultDaysPerYear
+	}
+	if daysPerYear < 1 || daysPerYear > 366 || math.IsNaN(daysPerYear) {
+		return OrderPolicyResponse{Success: false, Message: "days_per_year must be between 1 and 366"}
+	}
+
+	if errs := validateOrderPolicyProducts(req.Products); len(errs) > 0 {
+		return OrderPolicyResponse{Success: false, Errors: errs, Message: invalidItemsMessage(errs), Invalid: true}
+	}
+
+	results := make([]OrderPolicyResult, len(req.Products))
+	total := 0.0
+	for i, product := range req.Products {
+		policy := algorithms.EconomicOrderQuantity(algorithms.OrderPolicyInput{
+			AnnualDemand: product.AnnualDemand,
+			OrderingCost: product.OrderingCost,
+			HoldingCost:  product.HoldingCost,
+			LeadTimeDays: product.LeadTimeDays,
+			DaysPerYear:  daysPerYear,
+			PackSize:     product.PackSize,
+			SafetyStock:  product.SafetyStock,
+		})
+		if !policy.Success {
+			return OrderPolicyResponse{Success: false, Message: fmt.Sprintf("product %s: %s", product.ID, policy.Message)}
+		}
+
+		results[i] = OrderPolicyResult{
+			ID:                product.ID,
+			Name:              product.Name,
+			EOQ:               policy.EOQ,
+			OrderQuantity:     policy.OrderQuantity,
+			OrdersPerYear:     policy.OrdersPerYear,
+			OrderIntervalDays: policy.OrderIntervalDays,
+			ReorderPoint:      policy.ReorderPoint,
+			AnnualOrderCost:   policy.AnnualOrderCost,
+			AnnualHoldingCost: policy.AnnualHoldingCost,
+			TotalAnnualCost:   policy.TotalAnnualCost,
+		}
+		total += policy.TotalAnnualCost
+	}
+
+	return OrderPolicyResponse{
+		Success:         true,
+		Products:        results,
+		TotalAnnualCost: total,
+		Message:         fmt.Sprintf("Order plans calculated for %d products", len(results)),
+	}
+}
+
+// validateOrderPolicyProducts returns every invalid field of the products
+func validateOrderPolicyProducts(products []OrderPolicyProduct) []ItemError {
+	var errs itemErrors
+	seen := make(map[string]bool, len(products))
+	for i, product := range products {
+		errs.checkID(i, product.ID, seen)
+		errs.checkPositive(i, product.ID, "annual_demand", product.AnnualDemand)
+		errs.checkPositive(i, product.ID, "ordering_cost", product.OrderingCost)
+		errs.checkPositive(i, product.ID, "holding_cost", product.HoldingCost)
+		errs.checkNonNegative(i, product.ID, "lead_time_days", product.LeadTimeDays)
+		errs.checkNonNegative(i, product.ID, "safety_stock", product.SafetyStock)
+		if product.PackSize < 0 || product.PackSize > maxDrawerCount {
+			errs.add(i, product.ID, "pack_size", fmt.Sprintf("must be between 1 and %d", maxDrawerCount))
+		}
+	}
+	return errs
+}
+
+// Safety stock defaults
+const (
+	defaultServiceLevel     = 0.95
+	defaultReviewPeriodDays = 7
+)
+
+// SafetyStockRequest represents a request for safety stock and par levels per product
+type SafetyStockRequest struct {
+	Products         []SafetyStockProduct `json:"products"`
+	ServiceLevel     float64              `json:"service_level,omitempty"`      // e.g. 0.95, default 0.95
+	ReviewPeriodDays float64              `json:"review_period_days,omitempty"` // days between orders, default 7
+}
+
+// SafetyStockProduct describes the demand and lead time of one product. Demand
+// variability is given as a standard deviation or a variance
+type SafetyStockProduct struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name,omitempty"`
+	DemandMean     float64  `json:"demand_mean"` // units per day
+	DemandStdDev   float64  `json:"demand_std_dev,omitempty"`
+	DemandVariance float64  `json:"demand_variance,omitempty"`
+	LeadTimeDays   float64  `json:"lead_time_days"`
+	LeadTimeStdDev float64  `json:"lead_time_std_dev,omitempty"`
+	ServiceLevel   *float64 `json:"service_level,omitempty"` // overrides the request's
+}
+
+// SafetyStockResult represents the safety stock and par level of one product
+type SafetyStockResult struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name,omitempty"`
+	ServiceLevel float64 `json:"service_level"`
+	Z            float64 `json:"z"`
+	SafetyStock  float64 `json:"safety_stock"`
+	ReorderPoint float64 `json:"reorder_point"`
+	ParLevel     int     `json:"par_level"` // whole units to stock up to at each review
+}
+
+// SafetyStockResponse represents the safety stock of a set of products
+type SafetyStockResponse struct {
+	Success  bool                `json:"success"`
+	Products []SafetyStockResult `json:"products"`
+	Errors   []ItemError         `json:"errors,omitempty"` // one entry per invalid product field
+	Message  string              `json:"message"`
+
+	Invalid bool `json:"-"`
+}
+
+// CalculateSafetyStock computes the safety stock, reorder point and par level of each product
+// for a target service level
+func (os *OptimizationService) CalculateSafetyStock(req SafetyStockRequest) SafetyStockResponse {
+	if len(req.Products) == 0 || len(req.Products) > maxInventoryProducts {
+		return SafetyStockResponse{Success: false, Message: fmt.Sprintf("between 1 and %d products are required", maxInventoryProducts)}
+	}
+
+	serviceLevel := req.ServiceLevel
+	if serviceLevel == 0 {
+		serviceLevel = defaultServiceLevel
+	}
+	reviewPeriod := req.ReviewPeriodDays
+	if reviewPeriod == 0 {
+		reviewPeriod = defaultReviewPeriodDays
+	}
+	if reviewPeriod < 0 || reviewPeriod > 366 || math.IsNaN(reviewPeriod) {
+		return SafetyStockResponse{Success: false, Message: "review_period_days must be between 0 and 366"}
+	}
+
+	if !(serviceLevel > 0 && serviceLevel < 1) {
+		return SafetyStockResponse{Success: false, Message: "service_level must be between 0 and 1"}
+	}
+	if errs := validateSafetyStockProducts(req.Products); len(errs) > 0 {
+		return SafetyStockResponse{Success: false, Errors: errs, Message: invalidItemsMessage(errs), Invalid: true}
+	}
+
+	results := make([]SafetyStockResult, len(req.Products))
+	for i, product := range req.Products {
+		stdDev := product.DemandStdDev
+		if product.DemandVariance > 0 {
+			stdDev = math.Sqrt(product.DemandVariance)
+		}
+
+		level := serviceLevel
+		if product.ServiceLevel != nil {
+			level = *product.ServiceLevel
+		}
+
+		result := algorithms.SafetyStock(algorithms.SafetyStockInput{
+			DemandMean:       product.DemandMean,
+			DemandStdDev:     stdDev,
+			LeadTimeDays:     product.LeadTimeDays,
+			LeadTimeStdDev:   product.LeadTimeStdDev,
+			ReviewPeriodDays: reviewPeriod,
+			ServiceLevel:     level,
+		})
+		if !result.Success {
+			return SafetyStockResponse{Success: false, Message: fmt.Sprintf("product %s: %s", product.ID, result.Message)}
+		}
+		if result.ParLevel > math.MaxInt32 {
+			return SafetyStockResponse{Success: false, Message: fmt.Sprintf("par level of %s is too large", product.ID)}
+		}
+
+		results[i] = SafetyStockResult{
+			ID:           product.ID,
+			Name:         product.Name,
+			ServiceLevel: level,
+			Z:            result.Z,
+			SafetyStock:  result.SafetyStock,
+			ReorderPoint: result.ReorderPoint,
+			ParLevel:     int(math.Ceil(result.ParLevel)),
+		}
+	}
+
+	return SafetyStockResponse{
+		Success:  true,
+		Products: results,
+		Message:  fmt.Sprintf("Safety stock calculated for %d products", len(results)),
+	}
+}
+
+// validateSafetyStockProducts returns every invalid field of the products
+func validateSafetyStockProducts(products []SafetyStockProduct) []ItemError {
+	var errs itemErrors
+	seen := make(map[string]bool, len(products))
+	for i, product := range products {
+		errs.checkID(i, product.ID, seen)
+		errs.checkNonNegative(i, product.ID, "demand_mean", product.DemandMean)
+		errs.checkNonNegative(i, product.ID, "demand_std_dev", product.DemandStdDev)
+		errs.checkNonNegative(i, product.ID, "demand_variance", product.DemandVariance)
+		errs.checkNonNegative(i, product.ID, "lead_time_days", product.LeadTimeDays)
+		errs.checkNonNegative(i, product.ID, "lead_time_std_dev", product.LeadTimeStdDev)
+		if product.DemandStdDev > 0 && product.DemandVariance > 0 {
+			errs.add(i, product.ID, "demand_variance", "cannot be given together with demand_std_dev")
+		}
+		if level := product.ServiceLevel; level != nil && !(*level > 0 && *level < 1) {
+			errs.add(i, product.ID, "service_level", "must be between 0 and 1")
+		}
+	}
+	return errs
+}
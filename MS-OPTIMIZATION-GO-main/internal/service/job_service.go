@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ms-optimization-go/internal/jobs"
+)
+
+// Background job types: the calculations worth running asynchronously
+const (
+	JobChangeWays      = "change_ways"
+	JobMultiDrawer     = "multi_drawer"
+	JobBillSplit       = "bill_split"
+	JobSuggestPayment  = "suggest_payment"
+	JobOrderPolicies   = "eoq"
+	JobSafetyStock     = "safety_stock"
+	JobLinearProgram   = "linear_program"
+	JobCocktailBatches = "cocktail_batching"
+	JobVehicleRoutes   = "vehicle_routing"
+	JobEveningPlan     = "evening_plan"
+	JobWaitlist        = "waitlist"
+)
+
+// maxQueuedJobs limits the jobs waiting for a worker
+const maxQueuedJobs = 100
+
+// SubmitJobRequest represents a calculation to run in the background. Request holds the
+// same body the calculation's synchronous endpoint takes
+type SubmitJobRequest struct {
+	Type    string          `json:"type"`
+	Request json.RawMessage `json:"request"`
+}
+
+// JobResponse represents the state of a background job
+type JobResponse struct {
+	Success bool      `json:"success"`
+	Job     *jobs.Job `json:"job,omitempty"`
+	Message string    `json:"message"`
+
+	NotFound  bool `json:"-"`
+	Conflict  bool `json:"-"`
+	QueueFull bool `json:"-"`
+}
+
+// JobTypes returns the job types that can be submitted
+func JobTypes() []string {
+	return []string{JobChangeWays, JobMultiDrawer, JobBillSplit, JobSuggestPayment, JobOrderPolicies, JobSafetyStock,
+		JobLinearProgram, JobCocktailBatches, JobVehicleRoutes, JobEveningPlan, JobWaitlist}
+}
+
+// SubmitJob validates a job request and queues it. Requests the calculation would reject
+// before starting are rejected here when that needs no stored data; otherwise the job fails
+// when the calculation does, with the calculation's response kept as the result
+func (os *OptimizationService) SubmitJob(req SubmitJobRequest) JobResponse {
+	if len(req.Request) == 0 {
+		return JobResponse{Success: false, Message: "request is required"}
+	}
+
+	var fn jobs.Func
+	var err error
+	switch req.Type {
+	case JobChangeWays:
+		var r ChangeWaysRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = validateChangeWaysRequest(r)
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.CountChangeWays(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobMultiDrawer:
+		var r MultiDrawerChangeRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = validateMultiDrawerChangeRequest(r)
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.CalculateMultiDrawerChange(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobBillSplit:
+		var r SplitBillRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = validateSplitBillRequest(r)
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.SplitBill(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobSuggestPayment:
+		var r SuggestPaymentRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = validateSuggestPaymentRequest(r)
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.SuggestPayment(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobOrderPolicies:
+		var r OrderPolicyRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = inventoryJobError(len(r.Products), validateOrderPolicyProducts(r.Products))
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.CalculateOrderPolicies(r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobSafetyStock:
+		var r SafetyStockRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = inventoryJobError(len(r.Products), validateSafetyStockProducts(r.Products))
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.CalculateSafetyStock(r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobLinearProgram:
+		var r LPRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.SolveLinearProgram(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobCocktailBatches:
+		var r CocktailBatchRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.PlanCocktailBatches(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobVehicleRoutes:
+		var r VehicleRoutingRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.OptimizeVehicleRoutes(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobEveningPlan:
+		var r EveningPlanRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.PlanEvening(ctx, r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	case JobWaitlist:
+		var r WaitlistRequest
+		if err = json.Unmarshal(req.Request, &r); err == nil {
+			err = validateWaitlistRequest(r)
+			fn = func(ctx context.Context) (interface{}, error) {
+				result := os.PlanWaitlist(r)
+				return result, jobError(result.Success, result.Message)
+			}
+		}
+	default:
+		return JobResponse{Success: false, Message: fmt.Sprintf("invalid job type %q", req.Type)}
+	}
+	if err != nil {
+		return JobResponse{Success: false, Message: fmt.Sprintf("invalid %s request: %v", req.Type, err)}
+	}
+
+	job, err := os.jobManager.Submit(req.Type, fn)
+	if err != nil {
+		return JobResponse{Success: false, Message: "Too many jobs are waiting, please retry later", QueueFull: true}
+	}
+	return JobResponse{Success: true, Job: &job, Message: "Job queued"}
+}
+
+// GetJob returns the state of a background job, with its result once finished
+func (os *OptimizationService) GetJob(id string) JobResponse {
+	job, err := os.jobManager.Get(id)
+	if err != nil {
+		return JobResponse{Success: false, Message: fmt.Sprintf("Job %s not found", id), NotFound: true}
+	}
+	return JobResponse{Success: true, Job: &job, Message: fmt.Sprintf("Job status: %s", job.Status)}
+}
+
+// CancelJob cancels a queued or running background job
+func (os *OptimizationService) CancelJob(id string) JobResponse {
+	job, err := os.jobManager.Cancel(id)
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return JobResponse{Success: false, Message: fmt.Sprintf("Job %s not found", id), NotFound: true}
+	case errors.Is(err, jobs.ErrJobFinished):
+		return JobResponse{Success: false, Job: &job, Message: fmt.Sprintf("Job %s already %s", id, job.Status), Conflict: true}
+	}
+	return JobResponse{Success: true, Job: &job, Message: "Job cancelled"}
+}
+
+// inventoryJobError rejects an inventory job with no products, too many or invalid ones
+func inventoryJobError(products int, errs []ItemError) error {
+	if products == 0 || products > maxInventoryProducts {
+		return fmt.Errorf("between 1 and %d products are required", maxInventoryProducts)
+	}
+	if len(errs) > 0 {
+		return errors.New(invalidItemsMessage(errs))
+	}
+	return nil
+}
+
+// jobError turns a failed calculation into a job error
+func jobError(success bool, message string) error {
+	if success {
+		return nil
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"encoding/json"
+	"ms-optimization-go/internal/notify"
+	"ms-optimization-go/internal/repository"
+	"testing"
+)
+
+func newTestService() *OptimizationService {
+	return NewOptimizationService(repository.NewMemoryDrawerRepository(), repository.NewMemoryChangeLogRepository(),
+		repository.NewMemoryFloorPlanRepository(), repository.NewMemoryOccupancyRepository(), notify.NewNopNotifier(), notify.NewNopNotifier())
+}
+
+func TestSubmitJobValidatesRequests(t *testing.T) {
+	tests := []struct {
+		jobType string
+		request string
+		ok      bool
+	}{
+		{JobMultiDrawer, `{"amount_paid":-1,"total_cost":-3,"drawers":[{"id":"a","available":{"1":5}}]}`, false},
+		{JobMultiDrawer, `{"amount_paid":5,"total_cost":3,"mode":"bogus","drawers":[{"id":"a","available":{"1":5}}]}`, false},
+		{JobMultiDrawer, `{"amount_paid":5,"total_cost":3,"drawers":[]}`, false},
+		{JobMultiDrawer, `{"amount_paid":5,"total_cost":3,"mode":"dp","drawers":[{"id":"a","available":{"1":5}}]}`, true},
+		{JobChangeWays, `{"amount":-1}`, false},
+		{JobChangeWays, `{"amount":1,"top_n":-1}`, false},
+		{JobChangeWays, `{"amount":1,"currency":"US"}`, false},
+		{JobChangeWays, `{"amount":1}`, true},
+		{JobBillSplit, `{"method":"halves","total":10,"guest_count":2}`, false},
+		{JobBillSplit, `{"total":-10,"guest_count":2}`, false},
+		{JobBillSplit, `{"total":10,"guest_count":2}`, true},
+		{JobSuggestPayment, `{"total":0,"wallet":{"1":1}}`, false},
+		{JobSuggestPayment, `{"total":1,"wallet":{"1":1}}`, true},
+		{JobOrderPolicies, `{"products":[]}`, false},
+		{JobSafetyStock, `{"products":[]}`, false},
+		{JobWaitlist, `{"groups":[]}`, false},
+		{JobWaitlist, `{"groups":[{"id":"g","size":2}],"method":"anneal","steps":5,"time_budget_ms":10}`, false},
+		{JobWaitlist, `{"groups":[{"id":"g","size":2}],"seed":3}`, false},
+		{JobWaitlist, `{"groups":[{"id":"g","size":2}],"tables":[{"id":"t","capacity":2}],"method":"anneal","steps":50,"seed":3}`, true},
+	}
+	os := newTestService()
+	for _, tt := range tests {
+		result := os.SubmitJob(SubmitJobRequest{Type: tt.jobType, Request: json.RawMessage(tt.request)})
+		if result.Success != tt.ok {
+			t.Errorf("%s %s: success %v, want %v (%s)", tt.jobType, tt.request, result.Success, tt.ok, result.Message)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultLocale formats amounts of currencies without a locale of their own
+const defaultLocale = "en-US"
+
+// numberFormat describes how a locale writes money amounts
+type numberFormat struct {
+	decimal     string // separator before the minor units
+	group       string // separator between groups of thousands
+	symbolAfter bool   // "1.234,50 €" instead of "€1,234.50"
+}
+
+// locales holds the supported number formats by locale tag
+var locales = map[string]numberFormat{
+	"en-US": {decimal: ".", group: ","},
+	"en-GB": {decimal: ".", group: ","},
+	"es-CO": {decimal: ",", group: "."},
+	"es-MX": {decimal: ".", group: ","},
+	"es-ES": {decimal: ",", group: ".", symbolAfter: true},
+	"de-DE": {decimal: ",", group: ".", symbolAfter: true},
+	"fr-FR": {decimal: ",", group: " ", symbolAfter: true},
+	"pt-BR": {decimal: ",", group: "."},
+}
+
+// withLocale returns the currency formatting amounts for locale (e.g. "es-CO"); an empty
+// locale keeps the current one
+func (c Currency) withLocale(locale string) (Currency, error) {
+	if locale == "" {
+		return c, nil
+	}
+
+	tag, ok := normalizeLocale(locale)
+	if !ok {
+		return c, fmt.Errorf("unsupported locale %s, use one of: %s", locale, strings.Join(localeTags(), ", "))
+	}
+	c.Locale = tag
+	return c, nil
+}
+
+// normalizeLocale maps spellings such as "es_co" to a supported tag such as "es-CO"
+func normalizeLocale(locale string) (string, bool) {
+	parts := strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)
+	tag := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		tag += "-" + strings.ToUpper(parts[1])
+	}
+	_, ok := locales[tag]
+	return tag, ok
+}
+
+// localeTags returns the supported locale tags in order
+func localeTags() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// formatNumber writes minor units as a grouped decimal number, e.g. 123450 -> "1.234,50" in es-CO
+func (c Currency) formatNumber(units int) string {
+	format, ok := locales[c.Locale]
+	if !ok {
+		format = locales[defaultLocale]
+	}
+
+	sign := ""
+	if units < 0 {
+		sign, units = "-", -units
+	}
+	scale := int(c.scale())
+	whole := fmt.Sprint(units / scale)
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(format.group)
+		}
+		b.WriteRune(digit)
+	}
+	if c.Decimals > 0 {
+		b.WriteString(format.decimal)
+		fmt.Fprintf(&b, "%0*d", c.Decimals, units%scale)
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Linear programming limits
+const (
+	maxLPVariables   = 200
+	maxLPConstraints = 200
+)
+
+// LPRequest represents a linear program: optimize objective · x subject to the constraints
+// and each variable's bounds. Variables are non-negative unless bounds say otherwise
+type LPRequest struct {
+	Objective   []float64          `json:"objective"`
+	Maximize    bool               `json:"maximize,omitempty"` // minimizes by default
+	Constraints []LinearConstraint `json:"constraints"`
+	LowerBounds []*float64         `json:"lower_bounds,omitempty"` // per variable, null for none; omitted for all 0
+	UpperBounds []*float64         `json:"upper_bounds,omitempty"` // per variable, null for none; omitted for none
+}
+
+// LinearConstraint describes one row of the constraint matrix, coefficients · x sense rhs
+type LinearConstraint struct {
+	Coefficients []float64 `json:"coefficients"`
+	Sense        string    `json:"sense"` // <=, >= or =
+	RHS          float64   `json:"rhs"`
+}
+
+// LPResponse represents the solution of a linear program. Duals give, per constraint, the
+// change in the optimal objective per unit increase of its rhs
+type LPResponse struct {
+	Success    bool      `json:"success"`
+	Status     string    `json:"status,omitempty"` // optimal, infeasible, unbounded, iteration_limit or cancelled
+	Solution   []float64 `json:"solution,omitempty"`
+	Objective  float64   `json:"objective"`
+	Duals      []float64 `json:"duals,omitempty"`
+	Iterations int       `json:"iterations"`
+	Message    string    `json:"message"`
+}
+
+// SolveLinearProgram solves a general linear program with the simplex method, for callers
+// whose problem has no dedicated endpoint
+func (os *OptimizationService) SolveLinearProgram(ctx context.Context, req LPRequest) LPResponse {
+	n := len(req.Objective)
+	if n == 0 || n > maxLPVariables {
+		return LPResponse{Success: false, Message: fmt.Sprintf("between 1 and %d objective coefficients are required", maxLPVariables)}
+	}
+	if len(req.Constraints) > maxLPConstraints {
+		return LPResponse{Success: false, Message: fmt.Sprintf("at most %d constraints are allowed", maxLPConstraints)}
+	}
+	if req.LowerBounds != nil && len(req.LowerBounds) != n {
+		return LPResponse{Success: false, Message: fmt.Sprintf("lower_bounds must have one entry per variable, got %d for %d", len(req.LowerBounds), n)}
+	}
+	if req.UpperBounds != nil && len(req.UpperBounds) != n {
+		return LPResponse{Success: false, Message: fmt.Sprintf("upper_bounds must have one entry per variable, got %d for %d", len(req.UpperBounds), n)}
+	}
+
+	problem := algorithms.LPProblem{
+		Objective:   req.Objective,
+		Maximize:    req.Maximize,
+		Constraints: make([]algorithms.LPConstraint, len(req.Constraints)),
+	}
+	for i, c := range req.Constraints {
+		if len(c.Coefficients) != n {
+			return LPResponse{Success: false, Message: fmt.Sprintf("constraint %d has %d coefficients, expected %d", i, len(c.Coefficients), n)}
+		}
+		sense := algorithms.LPSense(c.Sense)
+		if sense != algorithms.LPLessEqual && sense != algorithms.LPGreaterEqual && sense != algorithms.LPEqual {
+			return LPResponse{Success: false, Message: fmt.Sprintf("invalid sense %q of constraint %d, expected <=, >= or =", c.Sense, i)}
+		}
+		problem.Constraints[i] = algorithms.LPConstraint{Coefficients: c.Coefficients, Sense: sense, RHS: c.RHS}
+	}
+	if req.LowerBounds != nil {
+		problem.Lower = make([]float64, n)
+		for j, bound := range req.LowerBounds {
+			problem.Lower[j] = math.Inf(-1)
+			if bound != nil {
+				problem.Lower[j] = *bound
+			}
+		}
+	}
+	if req.UpperBounds != nil {
+		problem.Upper = make([]float64, n)
+		for j, bound := range req.UpperBounds {
+			problem.Upper[j] = math.Inf(1)
+			if bound != nil {
+				problem.Upper[j] = *bound
+			}
+		}
+	}
+
+	solution := algorithms.SolveLP(ctx, problem)
+	return LPResponse{
+		Success:    solution.Success,
+		Status:     string(solution.Status),
+		Solution:   solution.X,
+		Objective:  solution.Objective,
+		Duals:      solution.Duals,
+		Iterations: solution.Iterations,
+		Message:    solution.Message,
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/models"
+	"ms-optimization-go/internal/repository"
+	"time"
+)
+
+// errTableNotInPlan is returned when a table is not in the floor plan's current version
+var errTableNotInPlan = errors.New("table not in floor plan")
+
+// SeatTableRequest represents a group sitting down at a table
+type SeatTableRequest struct {
+	GroupID           string     `json:"group_id,omitempty"`
+	Size              int        `json:"size"`
+	DiningMinutes     float64    `json:"dining_minutes,omitempty"`     // expected stay, default 90
+	ExpectedDeparture *time.Time `json:"expected_departure,omitempty"` // instead of dining_minutes
+	Version           int64      `json:"version,omitempty"`            // the table version last read; the seat fails if it changed
+}
+
+// ReleaseTableRequest represents a group leaving a table
+type ReleaseTableRequest struct {
+	Version int64 `json:"version,omitempty"` // the table version last read; the release fails if it changed
+}
+
+// TableStatus represents the live state of one table
+type TableStatus struct {
+	TableID           string     `json:"table_id"`
+	Capacity          int        `json:"capacity"`
+	Zone              string     `json:"zone,omitempty"`
+	Occupied          bool       `json:"occupied"`
+	GroupID           string     `json:"group_id,omitempty"`
+	Size              int        `json:"size,omitempty"`
+	SeatedAt          *time.Time `json:"seated_at,omitempty"`
+	ExpectedDeparture *time.Time `json:"expected_departure,omitempty"`
+	Version           int64      `json:"version"`
+}
+
+// TableOccupancyResponse represents the state of a table after a seat or release
+type TableOccupancyResponse struct {
+	Success     bool         `json:"success"`
+	FloorPlanID string       `json:"floor_plan_id"`
+	Table       *TableStatus `json:"table,omitempty"`
+	Message     string       `json:"message"`
+	NotFound    bool         `json:"-"`
+	Conflict    bool         `json:"-"`
+	Invalid     bool         `json:"-"`
+}
+
+// FloorOccupancyResponse represents the live state of every table of a floor plan
+type FloorOccupancyResponse struct {
+	Success          bool          `json:"success"`
+	FloorPlanID      string        `json:"floor_plan_id"`
+	FloorPlanVersion int           `json:"floor_plan_version,omitempty"`
+	Tables           []TableStatus `json:"tables"`
+	OccupiedTables   int           `json:"occupied_tables"`
+	SeatedCovers     int           `json:"seated_covers"`
+	Message          string        `json:"message"`
+	NotFound         bool          `json:"-"`
+}
+
+// GetFloorOccupancy returns which tables of today's version of a floor plan are occupied
+func (os *OptimizationService) GetFloorOccupancy(id string) FloorOccupancyResponse {
+	plan, err := os.resolveFloorPlan(id, FloorPlanQuery{Day: time.Now().Weekday().String()})
+	if err != nil {
+		response := floorPlanErrorResponse(id, err)
+		return FloorOccupancyResponse{Success: false, FloorPlanID: id, Message: response.Message, NotFound: response.NotFound}
+	}
+	occupancy, err := os.occupancyByTable(id)
+	if err != nil {
+		return FloorOccupancyResponse{Success: false, FloorPlanID: id, Message: fmt.Sprintf("Occupancy operation failed: %v", err)}
+	}
+
+	tables := make([]TableStatus, len(plan.Tables))
+	occupied, covers := 0, 0
+	for i, table := range plan.Tables {
+		tables[i] = newTableStatus(table, occupancy[table.ID])
+		if tables[i].Occupied {
+			occupied++
+			covers += tables[i].Size
+		}
+	}
+
+	return FloorOccupancyResponse{
+		Success:          true,
+		FloorPlanID:      id,
+		FloorPlanVersion: plan.Version,
+		Tables:           tables,
+		OccupiedTables:   occupied,
+		SeatedCovers:     covers,
+		Message:          fmt.Sprintf("%d of %d tables occupied", occupied, len(tables)),
+	}
+}
+
+// SeatTable marks a table of today's floor plan occupied by a group
+func (os *OptimizationService) SeatTable(id, tableID string, req SeatTableRequest) TableOccupancyResponse {
+	table, err := os.floorPlanTable(id, tableID)
+	if err != nil {
+		return occupancyErrorResponse(id, tableID, err)
+	}
+	if req.Size < 1 || req.Size > table.Capacity {
+		return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: fmt.Sprintf("size must be between 1 and the table's %d seats", table.Capacity)}
+	}
+	if req.Version < 0 {
+		return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: "version must not be negative"}
+	}
+	if len(req.GroupID) > maxStoredIDLength {
+		return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: fmt.Sprintf("group_id must be at most %d characters", maxStoredIDLength), Invalid: true}
+	}
+
+	now := time.Now()
+	departure := now.Add(minutes(defaultTurnMinutes))
+	switch {
+	case req.ExpectedDeparture != nil && req.DiningMinutes != 0:
+		return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: "give dining_minutes or expected_departure, not both"}
+	case req.ExpectedDeparture != nil:
+		if !req.ExpectedDeparture.After(now) {
+			return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: "expected_departure must be in the future", Invalid: true}
+		}
+		departure = *req.ExpectedDeparture
+	case req.DiningMinutes != 0:
+		if !validTurnMinutes(req.DiningMinutes) {
+			return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: "dining_minutes must be between 1 and 1440"}
+		}
+		departure = now.Add(minutes(req.DiningMinutes))
+	}
+
+	occupancy := &models.TableOccupancy{
+		FloorPlanID:       id,
+		TableID:           tableID,
+		GroupID:           req.GroupID,
+		Size:              req.Size,
+		SeatedAt:          now,
+		ExpectedDeparture: departure,
+	}
+	if err := os.occupancyRepo.SeatTable(occupancy, req.Version); err != nil {
+		return occupancyErrorResponse(id, tableID, err)
+	}
+
+	status := newTableStatus(table, occupancy)
+	return TableOccupancyResponse{
+		Success:     true,
+		FloorPlanID: id,
+		Table:       &status,
+		Message:     fmt.Sprintf("Table %s seated", tableID),
+	}
+}
+
+// ReleaseTable marks an occupied table of a floor plan free
+func (os *OptimizationService) ReleaseTable(id, tableID string, req ReleaseTableRequest) TableOccupancyResponse {
+	table, err := os.floorPlanTable(id, tableID)
+	if err != nil {
+		return occupancyErrorResponse(id, tableID, err)
+	}
+	if req.Version < 0 {
+		return TableOccupancyResponse{Success: false, FloorPlanID: id, Message: "version must not be negative"}
+	}
+
+	occupancy, err := os.occupancyRepo.ReleaseTable(id, tableID, req.Version)
+	if err != nil {
+		return occupancyErrorResponse(id, tableID, err)
+	}
+
+	status := newTableStatus(table, occupancy)
+	return TableOccupancyResponse{
+		Success:     true,
+		FloorPlanID: id,
+		Table:       &status,
+		Message:     fmt.Sprintf("Table %s released", tableID),
+	}
+}
+
+// liveAvailability returns the minutes after start until each occupied table of a floor
+// plan version is expected to be free. Seated tables the version does not have are ignored
+func (os *OptimizationService) liveAvailability(plan *models.FloorPlan, start time.Time) (map[string]float64, error) {
+	occupancy, err := os.occupancyByTable(plan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string]float64)
+	for tableID, table := range occupancy {
+		if table.Occupied && plan.TableIndex(tableID) >= 0 {
+			// A group that overstays is expected to leave any moment
+			availability[tableID] = math.Max(0, table.ExpectedDeparture.Sub(start).Minutes())
+		}
+	}
+	return availability, nil
+}
+
+// occupancyByTable loads the stored state of a floor plan's tables by table ID
+func (os *OptimizationService) occupancyByTable(id string) (map[string]*models.TableOccupancy, error) {
+	tables, err := os.occupancyRepo.ListOccupancy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	byTable := make(map[string]*models.TableOccupancy, len(tables))
+	for _, table := range tables {
+		byTable[table.TableID] = table
+	}
+	return byTable, nil
+}
+
+// floorPlanTable finds a table in today's version of a floor plan
+func (os *OptimizationService) floorPlanTable(id, tableID string) (models.FloorPlanTable, error) {
+	plan, err := os.resolveFloorPlan(id, FloorPlanQuery{Day: time.Now().Weekday().String()})
+	if err != nil {
+		return models.FloorPlanTable{}, err
+	}
+
+	i := plan.TableIndex(tableID)
+	if i < 0 {
+		return models.FloorPlanTable{}, errTableNotInPlan
+	}
+	return plan.Tables[i], nil
+}
+
+// newTableStatus combines a floor plan table with its stored state, nil if never seated
+func newTableStatus(table models.FloorPlanTable, occupancy *models.TableOccupancy) TableStatus {
+	status := TableStatus{TableID: table.ID, Capacity: table.Capacity, Zone: table.Zone}
+	if occupancy == nil {
+		return status
+	}
+
+	status.Version = occupancy.Version
+	if occupancy.Occupied {
+		seatedAt, departure := occupancy.SeatedAt, occupancy.ExpectedDeparture
+		status.Occupied = true
+		status.GroupID = occupancy.GroupID
+		status.Size = occupancy.Size
+		status.SeatedAt = &seatedAt
+		status.ExpectedDeparture = &departure
+	}
+	return status
+}
+
+// occupancyErrorResponse maps floor plan and occupancy errors to a failed response
+func occupancyErrorResponse(id, tableID string, err error) TableOccupancyResponse {
+	response := TableOccupancyResponse{Success: false, FloorPlanID: id}
+	switch {
+	case errors.Is(err, repository.ErrFloorPlanNotFound):
+		response.Message = fmt.Sprintf("Floor plan %s not found", id)
+		response.NotFound = true
+	case errors.Is(err, errTableNotInPlan):
+		response.Message = fmt.Sprintf("Table %s is not in floor plan %s", tableID, id)
+		response.NotFound = true
+	case errors.Is(err, repository.ErrTableOccupied):
+		response.Message = fmt.Sprintf("Table %s is already occupied", tableID)
+		response.Conflict = true
+	case errors.Is(err, repository.ErrTableNotOccupied):
+		response.Message = fmt.Sprintf("Table %s is not occupied", tableID)
+		response.Conflict = true
+	case errors.Is(err, repository.ErrOccupancyConflict):
+		response.Message = fmt.Sprintf("Table %s changed since version was read, reload and retry", tableID)
+		response.Conflict = true
+	default:
+		response.Message = fmt.Sprintf("Occupancy operation failed: %v", err)
+	}
+	return response
+}
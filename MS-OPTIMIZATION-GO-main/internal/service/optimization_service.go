@@ -1,26 +1,58 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"ms-optimization-go/internal/algorithms"
+	"ms-optimization-go/internal/jobs"
+	"ms-optimization-go/internal/models"
+	"ms-optimization-go/internal/notify"
+	"ms-optimization-go/internal/repository"
+	"runtime"
+	"strings"
+	"sync"
 )
 
 // OptimizationService provides business logic for optimization algorithms
 type OptimizationService struct {
-	moneyAlgo   *algorithms.MoneyChangeAlgorithm
-	sortingAlgo *algorithms.SortingAlgorithm
-	searchAlgo  *algorithms.SearchAlgorithm
+	mu               sync.RWMutex               // guards denominationSets
+	denominationSets map[string]denominationSet // set name -> denominations and change algorithm
+	sortingAlgo      *algorithms.SortingAlgorithm
+	searchAlgo       *algorithms.SearchAlgorithm
+	tipAlgo          *algorithms.TipSplitAlgorithm
+	drawerRepo       repository.DrawerRepository
+	changeLogRepo    repository.ChangeLogRepository
+	floorPlanRepo    repository.FloorPlanRepository
+	occupancyRepo    repository.OccupancyRepository
+	notifier         notify.Notifier // change shortage alerts
+	planNotifier     notify.Notifier // seating plans recomputed after cancellations and no-shows
+	jobManager       *jobs.Manager   // runs calculations submitted as background jobs
 }
 
 // NewOptimizationService creates a new optimization service
-func NewOptimizationService() *OptimizationService {
-	// Initialize with common coin denominations (in cents)
-	coins := []int{5000, 2000, 1000, 500, 200, 100, 50, 25, 10, 5, 1} // $50, $20, $10, $5, $2, $1, $0.50, $0.25, $0.10, $0.05, $0.01
+func NewOptimizationService(drawerRepo repository.DrawerRepository, changeLogRepo repository.ChangeLogRepository, floorPlanRepo repository.FloorPlanRepository, occupancyRepo repository.OccupancyRepository, notifier, planNotifier notify.Notifier) *OptimizationService {
+	// Initialize a change algorithm per built-in currency denomination set (in minor units)
+	denominationSets := make(map[string]denominationSet, len(supportedCurrencies))
+	for code, currency := range supportedCurrencies {
+		denominationSets[code] = denominationSet{
+			currency:  currency,
+			moneyAlgo: currency.newMoneyAlgorithm(currency.Denominations),
+			builtIn:   true,
+		}
+	}
 
 	return &OptimizationService{
-		moneyAlgo:   algorithms.NewMoneyChangeAlgorithm(coins),
-		sortingAlgo: algorithms.NewSortingAlgorithm(),
-		searchAlgo:  algorithms.NewSearchAlgorithm(),
+		denominationSets: denominationSets,
+		sortingAlgo:      algorithms.NewSortingAlgorithm(),
+		searchAlgo:       algorithms.NewSearchAlgorithm(),
+		tipAlgo:          algorithms.NewTipSplitAlgorithm(),
+		drawerRepo:       drawerRepo,
+		changeLogRepo:    changeLogRepo,
+		floorPlanRepo:    floorPlanRepo,
+		occupancyRepo:    occupancyRepo,
+		notifier:         notifier,
+		planNotifier:     planNotifier,
+		jobManager:       jobs.NewManager(runtime.NumCPU(), maxQueuedJobs),
 	}
 }
 
@@ -28,72 +60,498 @@ func NewOptimizationService() *OptimizationService {
 type CalculateChangeRequest struct {
 	AmountPaid float64 `json:"amount_paid"`
 	TotalCost  float64 `json:"total_cost"`
+	Mode       string  `json:"mode,omitempty"` // auto (default), greedy, dp
+
+	// Optional exact amounts in minor units (e.g. cents); when set they take precedence
+	// over amount_paid and total_cost
+	AmountPaidCents *int64 `json:"amount_paid_cents,omitempty"`
+	TotalCostCents  *int64 `json:"total_cost_cents,omitempty"`
+
+	// Currency code (USD, EUR, COP); amounts and denominations are in its major units
+	Currency string `json:"currency,omitempty"`
+	// Optional locale for formatted amounts (e.g. "es-CO"); defaults to the currency's
+	Locale string `json:"locale,omitempty"`
+	// Optional denominations (e.g. 0.25) overriding the built-in set for the currency
+	Denominations []float64 `json:"denominations,omitempty"`
+	// Optional named denomination set (see /denominations) instead of the currency's default
+	DenominationSet string `json:"denomination_set,omitempty"`
+
+	// Optional drawer contents: denomination (e.g. "0.25") -> quantity on hand
+	Available map[string]int `json:"available,omitempty"`
+
+	// Optional stored drawer to dispense from; its contents are decremented on success
+	DrawerID string `json:"drawer_id,omitempty"`
+	// Optional terminal (POS) making the calculation, recorded in the change history
+	TerminalID string `json:"terminal_id,omitempty"`
+
+	// Optional bias between bills and coins: prefer_bills, prefer_coins, balanced (default)
+	Preference string `json:"preference,omitempty"`
+
+	// Optional cash rounding applied to the total before calculating change
+	Rounding *RoundingPolicy `json:"rounding,omitempty"`
+
+	// Objective: min_coins (default) or balance_drawer, which favors denominations with a
+	// higher weight (denomination, e.g. "0.25" -> weight > 0) to keep the till balanced
+	Strategy string             `json:"strategy,omitempty"`
+	Weights  map[string]float64 `json:"weights,omitempty"`
+
+	// Optional split payment; when present it replaces amount_paid and only the cash
+	// tenders take part in the change calculation
+	Tenders []Tender `json:"tenders,omitempty"`
+
+	// Number of alternative breakdowns to return besides the chosen one (max 20)
+	Alternatives int `json:"alternatives,omitempty"`
+
+	// Also express the breakdown in standard coin rolls and bill bundles, optionally
+	// overriding roll sizes (denomination, e.g. "0.25" -> pieces per roll; 0 keeps it loose)
+	Rolls     bool           `json:"rolls,omitempty"`
+	RollSizes map[string]int `json:"roll_sizes,omitempty"`
+}
+
+// RoundingPolicy describes how a cash total is rounded, e.g. to the nearest 0.05
+type RoundingPolicy struct {
+	Mode      string  `json:"mode"`      // up, down, nearest
+	Increment float64 `json:"increment"` // in major units, e.g. 0.05 or 0.50
 }
 
 // CalculateChangeResponse represents the response for change calculation
 type CalculateChangeResponse struct {
 	Success        bool           `json:"success"`
 	ChangeAmount   float64        `json:"change_amount"`
+	ChangeCents    int64          `json:"change_amount_cents"` // exact change in minor units
 	TotalCoins     int            `json:"total_coins"`
 	Breakdown      map[string]int `json:"breakdown"`
 	Message        string         `json:"message"`
 	AvailableCoins []string       `json:"available_coins"`
+	Algorithm      string         `json:"algorithm_used,omitempty"`
+	Strategy       string         `json:"strategy,omitempty"`
+	Currency       string         `json:"currency,omitempty"`
+
+	// Whether greedy is optimal for the denomination set; auto mode uses dp when it is not, or
+	// when a limited drawer supply is given
+	CanonicalSystem *bool `json:"canonical_system,omitempty"`
+
+	InsufficientChange bool    `json:"insufficient_change"`
+	Shortfall          float64 `json:"shortfall,omitempty"`
+
+	DrawerID        string         `json:"drawer_id,omitempty"`
+	DrawerRemaining map[string]int `json:"drawer_remaining,omitempty"`
+
+	RoundedTotal       *float64 `json:"rounded_total,omitempty"`
+	RoundingAdjustment float64  `json:"rounding_adjustment"`
+
+	Bills     map[string]int `json:"bills,omitempty"`
+	Coins     map[string]int `json:"coins,omitempty"`
+	BillCount int            `json:"bill_count"`
+	CoinCount int            `json:"coin_count"`
+
+	Settlement *SettlementSummary `json:"settlement,omitempty"`
+
+	Alternatives []AlternativeBreakdown `json:"alternatives,omitempty"`
+
+	Rolls map[string]RolledDenomination `json:"rolls,omitempty"`
+
+	Invalid bool `json:"-"`
 }
 
+// changeSolver computes change against a drawer's contents (nil means an unlimited supply)
+type changeSolver func(available map[int]int) algorithms.ChangeResult
+
+// Limits applied to request-supplied denomination sets and drawer contents
+const (
+	defaultCurrency  = "USD"
+	maxDenominations = 50
+	maxDrawerCount   = 1000000
+)
+
 // CalculateOptimalChange calculates the optimal change for a payment
 func (os *OptimizationService) CalculateOptimalChange(req CalculateChangeRequest) CalculateChangeResponse {
-	// Convert to cents to avoid floating point precision issues
-	amountPaidCents := int(req.AmountPaid * 100)
-	totalCostCents := int(req.TotalCost * 100)
+	// The terminal is kept in the change history, so reject it here rather than lose the record
+	if len(req.TerminalID) > maxStoredIDLength {
+		return CalculateChangeResponse{
+			Success: false,
+			Message: fmt.Sprintf("terminal_id must be at most %d characters", maxStoredIDLength),
+			Invalid: true,
+		}
+	}
 
-	changeAmountCents := amountPaidCents - totalCostCents
+	mode := req.Mode
+	if mode == "" {
+		mode = algorithms.ChangeModeAuto
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = algorithms.StrategyMinCoins
+	}
 
-	if changeAmountCents < 0 {
+	// A stored drawer implies its currency when the request doesn't name one
+	currencyCode := req.Currency
+	if currencyCode == "" && req.DrawerID != "" {
+		if drawer, err := os.drawerRepo.GetDrawer(req.DrawerID); err == nil {
+			currencyCode = drawer.Currency
+		}
+	}
+
+	currency, moneyAlgo, err := os.resolveCurrency(currencyCode, req.DenominationSet, req.Denominations)
+	if err == nil {
+		currency, err = currency.withLocale(req.Locale)
+	}
+	if err != nil {
+		return CalculateChangeResponse{
+			Success:  false,
+			Message:  err.Error(),
+			Currency: currency.Code,
+		}
+	}
+
+	canonical := moneyAlgo.IsCanonical()
+
+	// Convert to minor units (e.g. cents) to avoid floating point precision issues
+	amountPaid, err := currency.amountToMinorUnits(req.AmountPaid, req.AmountPaidCents)
+	if err != nil {
+		return CalculateChangeResponse{
+			Success:  false,
+			Message:  fmt.Sprintf("invalid amount paid: %v", err),
+			Currency: currency.Code,
+		}
+	}
+	totalCost, err := currency.amountToMinorUnits(req.TotalCost, req.TotalCostCents)
+	if err != nil {
+		return CalculateChangeResponse{
+			Success:  false,
+			Message:  fmt.Sprintf("invalid total cost: %v", err),
+			Currency: currency.Code,
+		}
+	}
+
+	// With split tenders only the cash portion of the total is settled in cash
+	var split *tenderSplit
+	orderTotal := totalCost
+	if len(req.Tenders) > 0 {
+		tenders, err := currency.splitTenders(req.Tenders, totalCost)
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  err.Error(),
+				Currency: currency.Code,
+			}
+		}
+		split = &tenders
+		amountPaid = tenders.cash
+		totalCost -= tenders.nonCash
+	}
+
+	// Round the cash total first so change is given on what the customer actually owes
+	var roundedTotal *float64
+	roundingAdjustment := 0
+	if req.Rounding != nil {
+		increment, err := currency.denominationToMinorUnits(req.Rounding.Increment)
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  fmt.Sprintf("invalid rounding increment: %v", err),
+				Currency: currency.Code,
+			}
+		}
+
+		rounded := algorithms.RoundToIncrement(totalCost, increment, req.Rounding.Mode)
+		roundingAdjustment = rounded - totalCost
+		totalCost = rounded
+
+		total := currency.fromMinorUnits(totalCost)
+		roundedTotal = &total
+	}
+
+	changeAmount := amountPaid - totalCost
+
+	var settlement *SettlementSummary
+	if split != nil {
+		settlement = currency.settlement(*split, orderTotal, totalCost, changeAmount)
+	}
+
+	var available map[int]int
+	if req.Available != nil {
+		available, err = currency.parseAvailable(req.Available, moneyAlgo.GetAvailableCoins())
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  err.Error(),
+				Currency: currency.Code,
+			}
+		}
+	}
+
+	if changeAmount < 0 {
 		return CalculateChangeResponse{
 			Success:      false,
 			ChangeAmount: 0,
 			Message:      "Insufficient payment amount",
+			Currency:     currency.Code,
+
+			RoundedTotal:       roundedTotal,
+			RoundingAdjustment: currency.fromMinorUnits(roundingAdjustment),
 		}
 	}
 
-	if changeAmountCents == 0 {
+	if changeAmount == 0 {
+		os.recordChange(req, currency, amountPaid, totalCost, 0, nil, moneyAlgo.ResolveMode(mode),
+			algorithms.ChangeResult{Success: true, Message: "Exact payment, no change needed"})
 		return CalculateChangeResponse{
 			Success:        true,
 			ChangeAmount:   0,
 			TotalCoins:     0,
 			Breakdown:      make(map[string]int),
 			Message:        "Exact payment, no change needed",
-			AvailableCoins: os.formatCoins(os.moneyAlgo.GetAvailableCoins()),
+			AvailableCoins: currency.formatCoins(moneyAlgo.GetAvailableCoins()),
+			Algorithm:      moneyAlgo.ResolveMode(mode),
+			Currency:       currency.Code,
+
+			CanonicalSystem: &canonical,
+
+			RoundedTotal:       roundedTotal,
+			RoundingAdjustment: currency.fromMinorUnits(roundingAdjustment),
 		}
 	}
 
-	result := os.moneyAlgo.CalculateChange(changeAmountCents)
+	var weights map[int]float64
+	if strategy == algorithms.StrategyBalanceDrawer {
+		weights, err = currency.parseWeights(req.Weights, moneyAlgo.GetAvailableCoins())
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  err.Error(),
+				Currency: currency.Code,
+			}
+		}
+	}
+
+	var rollSizes map[int]int
+	if req.Rolls {
+		rollSizes, err = currency.rollSizes(moneyAlgo.GetAvailableCoins(), req.RollSizes)
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  err.Error(),
+				Currency: currency.Code,
+			}
+		}
+	}
+
+	// solvedWith keeps the drawer contents the final breakdown was computed against
+	var solvedWith map[int]int
+	solve := func(available map[int]int) algorithms.ChangeResult {
+		solvedWith = available
+		switch {
+		case strategy == algorithms.StrategyBalanceDrawer:
+			return moneyAlgo.CalculateChangeBalanced(changeAmount, available, weights)
+		case req.Preference == algorithms.PreferenceBills || req.Preference == algorithms.PreferenceCoins:
+			return moneyAlgo.CalculateChangePreferred(changeAmount, available, req.Preference)
+		case available != nil:
+			return moneyAlgo.CalculateChangeLimited(changeAmount, available, mode)
+		default:
+			return moneyAlgo.CalculateChangeWithMode(changeAmount, mode)
+		}
+	}
+
+	var result algorithms.ChangeResult
+	var drawer *models.Drawer
+	if req.DrawerID != "" {
+		result, drawer, err = os.dispenseFromDrawer(req.DrawerID, currency, solve)
+		if err != nil {
+			return CalculateChangeResponse{
+				Success:  false,
+				Message:  err.Error(),
+				Currency: currency.Code,
+				DrawerID: req.DrawerID,
+			}
+		}
+	} else {
+		result = solve(available)
+	}
+	mode = moneyAlgo.ResolveMode(mode)
+	if result.Algorithm != "" {
+		mode = result.Algorithm
+	}
+
+	os.recordChange(req, currency, amountPaid, totalCost, changeAmount, solvedWith, mode, result)
+	if result.Insufficient && solvedWith != nil {
+		os.alertChangeShortage(currency, moneyAlgo, req.DrawerID, changeAmount, solvedWith, result)
+	}
 
-	// Convert breakdown from cents to dollar format
-	breakdown := make(map[string]int)
-	for coinValue, quantity := range result.Breakdown {
-		dollarValue := float64(coinValue) / 100
-		breakdown[fmt.Sprintf("$%.2f", dollarValue)] = quantity
+	var drawerRemaining map[string]int
+	if drawer != nil {
+		drawerRemaining = currency.formatDrawerContents(drawer.Counts)
+	}
+
+	var rolls map[string]RolledDenomination
+	if rollSizes != nil && result.Success {
+		rolls = currency.formatRolls(result.Breakdown, rollSizes)
+	}
+
+	var alternatives []AlternativeBreakdown
+	if req.Alternatives > 0 && result.Success {
+		alternatives = currency.formatAlternatives(
+			alternativeBreakdowns(moneyAlgo, changeAmount, solvedWith, result.Breakdown, req.Alternatives))
 	}
 
 	return CalculateChangeResponse{
 		Success:        result.Success,
-		ChangeAmount:   float64(changeAmountCents) / 100,
+		ChangeAmount:   currency.fromMinorUnits(changeAmount),
+		ChangeCents:    int64(changeAmount),
 		TotalCoins:     result.TotalCoins,
-		Breakdown:      breakdown,
+		Breakdown:      currency.formatBreakdown(result.Breakdown),
 		Message:        result.Message,
-		AvailableCoins: os.formatCoins(os.moneyAlgo.GetAvailableCoins()),
+		AvailableCoins: currency.formatCoins(moneyAlgo.GetAvailableCoins()),
+		Algorithm:      mode,
+		Strategy:       strategy,
+		Currency:       currency.Code,
+
+		CanonicalSystem: &canonical,
+
+		InsufficientChange: result.Insufficient,
+		Shortfall:          currency.fromMinorUnits(result.Shortfall),
+
+		DrawerID:        req.DrawerID,
+		DrawerRemaining: drawerRemaining,
+
+		RoundedTotal:       roundedTotal,
+		RoundingAdjustment: currency.fromMinorUnits(roundingAdjustment),
+
+		Bills:     currency.formatBreakdown(result.Bills),
+		Coins:     currency.formatBreakdown(result.Coins),
+		BillCount: countPieces(result.Bills),
+		CoinCount: countPieces(result.Coins),
+
+		Settlement:   settlement,
+		Alternatives: alternatives,
+
+		Rolls: rolls,
+	}
+}
+
+// alternativeBreakdowns returns up to k breakdowns other than the chosen one, ranked by pieces
+func alternativeBreakdowns(moneyAlgo *algorithms.MoneyChangeAlgorithm, amount int, available, chosen map[int]int, k int) []algorithms.Breakdown {
+	if amount > maxAnalysisAmount {
+		return nil
+	}
+	if k > maxAlternatives {
+		k = maxAlternatives
 	}
+
+	// Bounded by maxAnalysisAmount, so it is not worth cancelling
+	breakdowns, _ := moneyAlgo.TopBreakdowns(context.Background(), amount, available, k+1)
+	var alternatives []algorithms.Breakdown
+	for _, breakdown := range breakdowns {
+		if len(alternatives) < k && !sameBreakdown(breakdown.Coins, chosen) {
+			alternatives = append(alternatives, breakdown)
+		}
+	}
+	return alternatives
+}
+
+// sameBreakdown reports whether two breakdowns hold the same pieces
+func sameBreakdown(a, b map[int]int) bool {
+	for coin, quantity := range a {
+		if b[coin] != quantity {
+			return false
+		}
+	}
+	for coin, quantity := range b {
+		if a[coin] != quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// countPieces returns the total number of pieces in a breakdown
+func countPieces(breakdown map[int]int) int {
+	total := 0
+	for _, quantity := range breakdown {
+		total += quantity
+	}
+	return total
+}
+
+// resolveCurrency returns the currency and change algorithm for a request: request
+// denominations win, then the named denomination set, then the currency's default set
+func (os *OptimizationService) resolveCurrency(code, setName string, denominations []float64) (Currency, *algorithms.MoneyChangeAlgorithm, error) {
+	currency, known := os.lookupCurrency(code)
+	var moneyAlgo *algorithms.MoneyChangeAlgorithm
+
+	if setName != "" {
+		set, ok := os.lookupDenominationSet(setName)
+		if !ok {
+			return currency, nil, fmt.Errorf("unknown denomination set %s", setName)
+		}
+		if code != "" && !strings.EqualFold(code, set.currency.Code) {
+			return currency, nil, fmt.Errorf("denomination set %s is in %s, not %s", setName, set.currency.Code, currency.Code)
+		}
+		currency, known, moneyAlgo = set.currency, true, set.moneyAlgo
+	} else if known {
+		set, _ := os.lookupDenominationSet(currency.Code)
+		moneyAlgo = set.moneyAlgo
+	}
+
+	if len(denominations) > 0 {
+		coins, err := currency.parseDenominations(denominations)
+		if err != nil {
+			return currency, nil, err
+		}
+		return currency, currency.newMoneyAlgorithm(coins), nil
+	}
+
+	if !known {
+		return currency, nil, fmt.Errorf("unsupported currency %s, supply denominations or use one of: %s",
+			currency.Code, strings.Join(os.currencyCodes(), ", "))
+	}
+	return currency, moneyAlgo, nil
 }
 
-// formatCoins formats coin values from cents to dollar format
-func (os *OptimizationService) formatCoins(coins []int) []string {
-	formatted := make([]string, len(coins))
-	for i, coin := range coins {
-		dollarValue := float64(coin) / 100
-		formatted[i] = fmt.Sprintf("$%.2f", dollarValue)
+// AvailableCoinsResponse represents the denominations available for a currency
+type AvailableCoinsResponse struct {
+	Success             bool     `json:"success"`
+	Currency            string   `json:"currency"`
+	Decimals            int      `json:"decimals"`
+	Locale              string   `json:"locale"`
+	Coins               []string `json:"coins"`
+	Canonical           bool     `json:"canonical"`
+	SupportedCurrencies []string `json:"supported_currencies"`
+	Message             string   `json:"message"`
+}
+
+// GetAvailableCoins returns the default denominations for a currency, formatted for locale
+// when one is given
+func (os *OptimizationService) GetAvailableCoins(code, locale string) AvailableCoinsResponse {
+	currency, moneyAlgo, err := os.resolveCurrency(code, "", nil)
+	if err != nil {
+		return AvailableCoinsResponse{
+			Success:             false,
+			Currency:            currency.Code,
+			SupportedCurrencies: os.currencyCodes(),
+			Message:             fmt.Sprintf("Unsupported currency %s", currency.Code),
+		}
+	}
+	if currency, err = currency.withLocale(locale); err != nil {
+		return AvailableCoinsResponse{
+			Success:             false,
+			Currency:            currency.Code,
+			SupportedCurrencies: os.currencyCodes(),
+			Message:             err.Error(),
+		}
+	}
+
+	return AvailableCoinsResponse{
+		Success:             true,
+		Currency:            currency.Code,
+		Decimals:            currency.Decimals,
+		Locale:              currency.Locale,
+		Coins:               currency.formatCoins(moneyAlgo.GetAvailableCoins()),
+		Canonical:           moneyAlgo.IsCanonical(),
+		SupportedCurrencies: os.currencyCodes(),
+		Message:             "Available coin denominations for change calculation",
 	}
-	return formatted
 }
 
 // SortProductsRequest represents a request to sort products
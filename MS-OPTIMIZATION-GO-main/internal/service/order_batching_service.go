@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"ms-optimization-go/internal/algorithms"
+	"time"
+)
+
+// Order batching limits and defaults
+const (
+	maxBatchOrders         = 2000
+	maxBatchDrinks         = 200
+	maxBatchRecipeMinutes  = 60
+	defaultMaxBatchDelay   = 5
+	maxBatchDelayMinutes   = 120
+	maxOrderBatchingWindow = 24 * 60
+)
+
+// OrderBatchingRequest represents the bar's pending drink orders
+type OrderBatchingRequest struct {
+	Orders []DrinkOrder  `json:"orders"`
+	Drinks []BatchRecipe `json:"drinks"`
+
+	// Longest an order may wait for others to join its batch, default 5
+	MaxDelayMinutes *float64 `json:"max_delay_minutes,omitempty"`
+}
+
+// DrinkOrder describes a pending order for one drink
+type DrinkOrder struct {
+	ID              string    `json:"id"`
+	Drink           string    `json:"drink"`
+	Station         string    `json:"station"`
+	Quantity        int       `json:"quantity,omitempty"` // default 1
+	PlacedAt        time.Time `json:"placed_at"`
+	MaxDelayMinutes *float64  `json:"max_delay_minutes,omitempty"` // default the request's
+}
+
+// BatchRecipe describes how long a drink takes to make
+type BatchRecipe struct {
+	ID           string  `json:"id"`
+	SetupMinutes float64 `json:"setup_minutes"`       // once per batch
+	UnitMinutes  float64 `json:"unit_minutes"`        // per drink
+	MaxBatch     int     `json:"max_batch,omitempty"` // drinks made at once, 0 for no limit
+}
+
+// OrderBatch represents a run of one drink at one station
+type OrderBatch struct {
+	Station  string    `json:"station"`
+	Drink    string    `json:"drink"`
+	OrderIDs []string  `json:"order_ids"`
+	Quantity int       `json:"quantity"`
+	StartAt  time.Time `json:"start_at"`
+	ReadyAt  time.Time `json:"ready_at"`
+}
+
+// BatchedOrder represents when an order is ready, batched and made on its own, in the
+// order of the request
+type BatchedOrder struct {
+	ID              string    `json:"id"`
+	Batch           int       `json:"batch"` // index into the response's batches
+	ReadyAt         time.Time `json:"ready_at"`
+	BaselineReadyAt time.Time `json:"baseline_ready_at"`
+}
+
+// OrderBatchingResponse represents the batch plan for the bar
+type OrderBatchingResponse struct {
+	Success                bool           `json:"success"`
+	Batches                []OrderBatch   `json:"batches"`
+	Orders                 []BatchedOrder `json:"orders"`
+	SetupMinutesSaved      float64        `json:"setup_minutes_saved"`
+	BaselineAverageMinutes float64        `json:"baseline_average_minutes"` // placing to ready, making each order on its own
+	BatchedAverageMinutes  float64        `json:"batched_average_minutes"`
+	AverageMinutesSaved    float64        `json:"average_minutes_saved"`
+	Message                string         `json:"message"`
+}
+
+// PlanOrderBatches groups pending orders for the same drink at the same station into
+// batches, so the setup is paid once per batch, without any order waiting longer than its
+// maximum delay for the rest of its batch to come in
+func (os *OptimizationService) PlanOrderBatches(req OrderBatchingRequest) OrderBatchingResponse {
+	if len(req.Orders) == 0 || len(req.Orders) > maxBatchOrders {
+		return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d orders are required", maxBatchOrders)}
+	}
+	if len(req.Drinks) == 0 {
+		return OrderBatchingResponse{Success: false, Message: "at least one drink is required"}
+	}
+	maxDelay := float64(defaultMaxBatchDelay)
+	if req.MaxDelayMinutes != nil {
+		maxDelay = *req.MaxDelayMinutes
+	}
+	if !(maxDelay >= 0) || maxDelay > maxBatchDelayMinutes {
+		return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("max_delay_minutes must be between 0 and %d", maxBatchDelayMinutes)}
+	}
+
+	drinkIndex := make(map[string]int, len(req.Drinks))
+	items := make([]algorithms.BatchItem, len(req.Drinks))
+	for i, drink := range req.Drinks {
+		if drink.ID == "" {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("drink %d has no id", i)}
+		}
+		if _, ok := drinkIndex[drink.ID]; ok {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("duplicate drink id %q", drink.ID)}
+		}
+		drinkIndex[drink.ID] = i
+		if !(drink.SetupMinutes >= 0) || drink.SetupMinutes > maxBatchRecipeMinutes || !(drink.UnitMinutes >= 0) || drink.UnitMinutes > maxBatchRecipeMinutes {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("setup_minutes and unit_minutes of drink %s must be between 0 and %d", drink.ID, maxBatchRecipeMinutes)}
+		}
+		if drink.MaxBatch < 0 || drink.MaxBatch > maxBatchDrinks {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("max_batch of drink %s must be between 0 and %d", drink.ID, maxBatchDrinks)}
+		}
+		items[i] = algorithms.BatchItem{SetupMinutes: drink.SetupMinutes, UnitMinutes: drink.UnitMinutes, MaxBatch: drink.MaxBatch}
+	}
+
+	// Times are minutes from the first order placed
+	first := req.Orders[0].PlacedAt
+	for _, order := range req.Orders {
+		if order.PlacedAt.Before(first) {
+			first = order.PlacedAt
+		}
+	}
+
+	var stations []string
+	stationIndex := make(map[string]int)
+	seen := make(map[string]bool, len(req.Orders))
+	orders := make([]algorithms.BatchOrder, len(req.Orders))
+	for i, order := range req.Orders {
+		if order.ID == "" {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("order %d has no id", i)}
+		}
+		if seen[order.ID] {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("duplicate order id %q", order.ID)}
+		}
+		seen[order.ID] = true
+		drink, ok := drinkIndex[order.Drink]
+		if !ok {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("order %s is for unknown drink %q", order.ID, order.Drink)}
+		}
+		if order.Station == "" {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("order %s has no station", order.ID)}
+		}
+		station, ok := stationIndex[order.Station]
+		if !ok {
+			station = len(stations)
+			stationIndex[order.Station] = station
+			stations = append(stations, order.Station)
+		}
+		quantity := order.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 || quantity > maxBatchDrinks {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("quantity of order %s must be between 1 and %d", order.ID, maxBatchDrinks)}
+		}
+		delay := maxDelay
+		if order.MaxDelayMinutes != nil {
+			delay = *order.MaxDelayMinutes
+		}
+		if !(delay >= 0) || delay > maxBatchDelayMinutes {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("max_delay_minutes of order %s must be between 0 and %d", order.ID, maxBatchDelayMinutes)}
+		}
+		placed := order.PlacedAt.Sub(first).Minutes()
+		if placed > maxOrderBatchingWindow {
+			return OrderBatchingResponse{Success: false, Message: fmt.Sprintf("orders must be placed within %d minutes of each other", maxOrderBatchingWindow)}
+		}
+		orders[i] = algorithms.BatchOrder{Item: drink, Station: station, Quantity: quantity, Placed: placed, MaxDelay: delay}
+	}
+
+	plan := algorithms.PlanBatches(orders, items, len(stations))
+	if !plan.Success {
+		return OrderBatchingResponse{Success: false, Message: plan.Message}
+	}
+
+	batches := make([]OrderBatch, len(plan.Batches))
+	batchOf := make([]int, len(req.Orders))
+	for b, batch := range plan.Batches {
+		ids := make([]string, len(batch.Orders))
+		for k, i := range batch.Orders {
+			ids[k] = req.Orders[i].ID
+			batchOf[i] = b
+		}
+		batches[b] = OrderBatch{
+			Station:  stations[batch.Station],
+			Drink:    req.Drinks[batch.Item].ID,
+			OrderIDs: ids,
+			Quantity: batch.Quantity,
+			StartAt:  first.Add(minutes(batch.Start)),
+			ReadyAt:  first.Add(minutes(batch.Finish)),
+		}
+	}
+	batched := make([]BatchedOrder, len(req.Orders))
+	for i, order := range req.Orders {
+		batched[i] = BatchedOrder{
+			ID:              order.ID,
+			Batch:           batchOf[i],
+			ReadyAt:         first.Add(minutes(plan.Ready[i])),
+			BaselineReadyAt: first.Add(minutes(plan.BaselineReady[i])),
+		}
+	}
+
+	return OrderBatchingResponse{
+		Success:                true,
+		Batches:                batches,
+		Orders:                 batched,
+		SetupMinutesSaved:      plan.SetupSaved,
+		BaselineAverageMinutes: plan.BaselineAverage,
+		BatchedAverageMinutes:  plan.BatchedAverage,
+		AverageMinutesSaved:    plan.BaselineAverage - plan.BatchedAverage,
+		Message:                plan.Message,
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SuggestPaymentRequest represents a request to suggest how a customer should pay
+type SuggestPaymentRequest struct {
+	Total           float64        `json:"total"`
+	Currency        string         `json:"currency,omitempty"`
+	Locale          string         `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+	Denominations   []float64      `json:"denominations,omitempty"`
+	DenominationSet string         `json:"denomination_set,omitempty"`
+	Wallet          map[string]int `json:"wallet"` // denomination (e.g. "20") -> quantity the customer carries
+}
+
+// SuggestPaymentResponse represents the suggested payment and the resulting change
+type SuggestPaymentResponse struct {
+	Success       bool           `json:"success"`
+	Currency      string         `json:"currency"`
+	Total         float64        `json:"total"`
+	AmountPaid    float64        `json:"amount_paid"`
+	ChangeAmount  float64        `json:"change_amount"`
+	Payment       map[string]int `json:"payment"`
+	Change        map[string]int `json:"change"`
+	PaymentPieces int            `json:"payment_pieces"`
+	ChangePieces  int            `json:"change_pieces"`
+	TotalPieces   int            `json:"total_pieces"`
+	Message       string         `json:"message"`
+}
+
+// SuggestPayment finds the wallet pieces to hand over that minimize the pieces exchanged
+func (os *OptimizationService) SuggestPayment(ctx context.Context, req SuggestPaymentRequest) SuggestPaymentResponse {
+	if err := validateSuggestPaymentRequest(req); err != nil {
+		return SuggestPaymentResponse{Success: false, Currency: req.Currency, Message: err.Error()}
+	}
+	currency, moneyAlgo, err := os.resolveCurrency(req.Currency, req.DenominationSet, req.Denominations)
+	if err == nil {
+		currency, err = currency.withLocale(req.Locale)
+	}
+	if err != nil {
+		return SuggestPaymentResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  err.Error(),
+		}
+	}
+
+	wallet, err := currency.parseAvailable(req.Wallet, moneyAlgo.GetAvailableCoins())
+	if err != nil {
+		return SuggestPaymentResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  err.Error(),
+		}
+	}
+
+	total, err := currency.toMinorUnits(req.Total)
+	if err != nil {
+		return SuggestPaymentResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Message:  fmt.Sprintf("invalid total: %v", err),
+		}
+	}
+	suggestion := moneyAlgo.SuggestPayment(ctx, total, wallet)
+	if !suggestion.Success {
+		return SuggestPaymentResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Total:    currency.fromMinorUnits(total),
+			Message:  suggestion.Message,
+		}
+	}
+
+	return SuggestPaymentResponse{
+		Success:       true,
+		Currency:      currency.Code,
+		Total:         currency.fromMinorUnits(total),
+		AmountPaid:    currency.fromMinorUnits(suggestion.AmountPaid),
+		ChangeAmount:  currency.fromMinorUnits(suggestion.AmountPaid - total),
+		Payment:       currency.formatBreakdown(suggestion.Payment),
+		Change:        currency.formatBreakdown(suggestion.Change),
+		PaymentPieces: suggestion.PaymentPieces,
+		ChangePieces:  suggestion.ChangePieces,
+		TotalPieces:   suggestion.PaymentPieces + suggestion.ChangePieces,
+		Message: fmt.Sprintf("Pay %s with %d pieces and receive %d pieces in change",
+			currency.formatAmount(suggestion.AmountPaid), suggestion.PaymentPieces, suggestion.ChangePieces),
+	}
+}
+
+// validateSuggestPaymentRequest checks a payment suggestion request before the wallet is searched
+func validateSuggestPaymentRequest(req SuggestPaymentRequest) error {
+	if !(req.Total > 0) || len(req.Wallet) == 0 {
+		return errors.New("total must be positive and wallet must not be empty")
+	}
+	return validateCurrencyCode(req.Currency)
+}
+
+// Payment methods accepted as tenders (same values as the sales service)
+const (
+	TenderCash     = "cash"
+	TenderCard     = "card"
+	TenderTransfer = "transfer"
+	TenderOther    = "other"
+)
+
+// Tender represents one part of a split payment
+type Tender struct {
+	Method      string  `json:"method"` // cash, card, transfer, other
+	Amount      float64 `json:"amount"`
+	AmountCents *int64  `json:"amount_cents,omitempty"` // exact amount in minor units, overrides amount
+}
+
+// SettlementSummary describes how a split payment settles the total
+type SettlementSummary struct {
+	Total        float64            `json:"total"`
+	NonCashTotal float64            `json:"non_cash_total"`
+	CashDue      float64            `json:"cash_due"`
+	CashTendered float64            `json:"cash_tendered"`
+	CashChange   float64            `json:"cash_change"`
+	ByMethod     map[string]float64 `json:"by_method"`
+}
+
+// tenderSplit holds tender totals in minor units
+type tenderSplit struct {
+	byMethod map[string]int
+	cash     int
+	nonCash  int
+}
+
+// splitTenders validates tenders and totals them per method. Only cash can be overpaid,
+// so non-cash tenders must not exceed the total and all tenders together must cover it
+func (c Currency) splitTenders(tenders []Tender, total int) (tenderSplit, error) {
+	split := tenderSplit{byMethod: make(map[string]int)}
+	for i, tender := range tenders {
+		switch tender.Method {
+		case TenderCash, TenderCard, TenderTransfer, TenderOther:
+		default:
+			return split, fmt.Errorf("tender %d has invalid method %q (cash, card, transfer, other)", i, tender.Method)
+		}
+		amount, err := c.amountToMinorUnits(tender.Amount, tender.AmountCents)
+		if err != nil {
+			return split, fmt.Errorf("tender %d: %w", i, err)
+		}
+		if amount <= 0 {
+			return split, fmt.Errorf("tender %d amount must be positive", i)
+		}
+
+		split.byMethod[tender.Method] += amount
+		if tender.Method == TenderCash {
+			split.cash += amount
+		} else {
+			split.nonCash += amount
+		}
+	}
+
+	if split.nonCash > total {
+		return split, fmt.Errorf("non-cash tenders (%s) exceed the total (%s); change can only be given on cash",
+			c.formatAmount(split.nonCash), c.formatAmount(total))
+	}
+	if split.cash+split.nonCash < total {
+		return split, fmt.Errorf("tenders (%s) do not cover the total (%s)",
+			c.formatAmount(split.cash+split.nonCash), c.formatAmount(total))
+	}
+
+	return split, nil
+}
+
+// settlement builds the summary for a split payment once the cash due and change are known
+func (c Currency) settlement(split tenderSplit, total, cashDue, change int) *SettlementSummary {
+	byMethod := make(map[string]float64, len(split.byMethod))
+	for method, amount := range split.byMethod {
+		byMethod[method] = c.fromMinorUnits(amount)
+	}
+
+	return &SettlementSummary{
+		Total:        c.fromMinorUnits(total),
+		NonCashTotal: c.fromMinorUnits(split.nonCash),
+		CashDue:      c.fromMinorUnits(cashDue),
+		CashTendered: c.fromMinorUnits(split.cash),
+		CashChange:   c.fromMinorUnits(change),
+		ByMethod:     byMethod,
+	}
+}
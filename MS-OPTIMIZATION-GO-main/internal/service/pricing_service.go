@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Pricing objectives
+const (
+	PricingObjectiveProfit  = "profit"
+	PricingObjectiveRevenue = "revenue"
+)
+
+// Guardrails reported when they hold a price back
+const (
+	guardrailMaxDailyChange = "max_daily_change"
+	guardrailMinMargin      = "min_margin"
+)
+
+// Pricing limits and defaults
+const (
+	maxPricingProducts    = 500
+	maxPricingElasticity  = 20
+	defaultMaxDailyChange = 0.1
+)
+
+// PricingRequest represents a request for daily price adjustments. The guardrails apply to
+// every product unless a product sets its own
+type PricingRequest struct {
+	Products       []PricingProduct `json:"products"`
+	Objective      string           `json:"objective,omitempty"`        // profit (default) or revenue
+	MinMargin      float64          `json:"min_margin,omitempty"`       // lowest (price - cost) / price allowed
+	MaxDailyChange *float64         `json:"max_daily_change,omitempty"` // largest relative price change, default 0.1
+	PriceStep      float64          `json:"price_step,omitempty"`       // prices are multiples of it, e.g. 0.05
+}
+
+// PricingProduct describes a product's price, cost and recent demand
+type PricingProduct struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name,omitempty"`
+	Price          float64  `json:"price"`
+	UnitCost       float64  `json:"unit_cost"`
+	DailySales     float64  `json:"daily_sales"` // recent units sold per day at the current price
+	Elasticity     float64  `json:"elasticity"`  // relative change in sales per relative change in price, e.g. -1.5
+	MinMargin      *float64 `json:"min_margin,omitempty"`
+	MaxDailyChange *float64 `json:"max_daily_change,omitempty"`
+}
+
+// PriceAdjustment represents the recommended price of a product and its projected effect
+type PriceAdjustment struct {
+	ID                    string   `json:"id"`
+	Name                  string   `json:"name,omitempty"`
+	CurrentPrice          float64  `json:"current_price"`
+	RecommendedPrice      float64  `json:"recommended_price"`
+	ChangePercent         float64  `json:"change_percent"`
+	CurrentDailySales     float64  `json:"current_daily_sales"`
+	ProjectedDailySales   float64  `json:"projected_daily_sales"`
+	CurrentDailyRevenue   float64  `json:"current_daily_revenue"`
+	ProjectedDailyRevenue float64  `json:"projected_daily_revenue"`
+	RevenueImpact         float64  `json:"revenue_impact"`
+	CurrentDailyProfit    float64  `json:"current_daily_profit"`
+	ProjectedDailyProfit  float64  `json:"projected_daily_profit"`
+	ProfitImpact          float64  `json:"profit_impact"`
+	LimitedBy             []string `json:"limited_by,omitempty"` // guardrails holding the price back
+	MarginUnreachable     bool     `json:"margin_unreachable,omitempty"`
+}
+
+// PricingResponse represents the price adjustments for a set of products
+type PricingResponse struct {
+	Success       bool              `json:"success"`
+	Products      []PriceAdjustment `json:"products"`
+	RevenueImpact float64           `json:"revenue_impact"` // per day over all products
+	ProfitImpact  float64           `json:"profit_impact"`
+	Errors        []ItemError       `json:"errors,omitempty"` // one entry per invalid product field
+	Message       string            `json:"message"`
+
+	Invalid bool `json:"-"`
+}
+
+// RecommendPrices proposes each product's price for the day from its price elasticity,
+// maximizing projected profit or revenue within the minimum margin and the largest daily
+// change
+func (os *OptimizationService) RecommendPrices(req PricingRequest) PricingResponse {
+	if len(req.Products) == 0 || len(req.Products) > maxPricingProducts {
+		return PricingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d products are required", maxPricingProducts)}
+	}
+	objective := req.Objective
+	if objective == "" {
+		objective = PricingObjectiveProfit
+	}
+	if objective != PricingObjectiveProfit && objective != PricingObjectiveRevenue {
+		return PricingResponse{Success: false, Message: fmt.Sprintf("invalid objective %q, expected %s or %s", req.Objective, PricingObjectiveProfit, PricingObjectiveRevenue)}
+	}
+	maxChange := defaultMaxDailyChange
+	if req.MaxDailyChange != nil {
+		maxChange = *req.MaxDailyChange
+	}
+	if !validPriceChange(maxChange) {
+		return PricingResponse{Success: false, Message: "max_daily_change must be at least 0 and below 1"}
+	}
+	if !validMinMargin(req.MinMargin) {
+		return PricingResponse{Success: false, Message: "min_margin must be at least 0 and below 1"}
+	}
+	if !(req.PriceStep >= 0) || math.IsInf(req.PriceStep, 0) {
+		return PricingResponse{Success: false, Message: "price_step must be a non-negative amount"}
+	}
+
+	if errs := validatePricingProducts(req.Products); len(errs) > 0 {
+		return PricingResponse{Success: false, Errors: errs, Message: invalidItemsMessage(errs), Invalid: true}
+	}
+
+	results := make([]PriceAdjustment, len(req.Products))
+	revenueImpact, profitImpact := 0.0, 0.0
+	for i, product := range req.Products {
+		input := algorithms.PriceInput{
+			Price:           product.Price,
+			UnitCost:        product.UnitCost,
+			DailyUnits:      product.DailySales,
+			Elasticity:      product.Elasticity,
+			MinMargin:       req.MinMargin,
+			MaxChange:       maxChange,
+			PriceStep:       req.PriceStep,
+			MaximizeRevenue: objective == PricingObjectiveRevenue,
+		}
+		if product.MinMargin != nil {
+			input.MinMargin = *product.MinMargin
+		}
+		if product.MaxDailyChange != nil {
+			input.MaxChange = *product.MaxDailyChange
+		}
+		recommendation := algorithms.RecommendPrice(input)
+		if !recommendation.Success {
+			return PricingResponse{Success: false, Message: fmt.Sprintf("product %s: %s", product.ID, recommendation.Message)}
+		}
+
+		currentRevenue := product.Price * product.DailySales
+		currentProfit := (product.Price - product.UnitCost) * product.DailySales
+		var limitedBy []string
+		if recommendation.AtMaxChange {
+			limitedBy = append(limitedBy, guardrailMaxDailyChange)
+		}
+		if recommendation.AtMinMargin {
+			limitedBy = append(limitedBy, guardrailMinMargin)
+		}
+		results[i] = PriceAdjustment{
+			ID:                    product.ID,
+			Name:                  product.Name,
+			CurrentPrice:          product.Price,
+			RecommendedPrice:      recommendation.Price,
+			ChangePercent:         recommendation.Change * 100,
+			CurrentDailySales:     product.DailySales,
+			ProjectedDailySales:   recommendation.DailyUnits,
+			CurrentDailyRevenue:   currentRevenue,
+			ProjectedDailyRevenue: recommendation.Revenue,
+			RevenueImpact:         recommendation.Revenue - currentRevenue,
+			CurrentDailyProfit:    currentProfit,
+			ProjectedDailyProfit:  recommendation.Profit,
+			ProfitImpact:          recommendation.Profit - currentProfit,
+			LimitedBy:             limitedBy,
+			MarginUnreachable:     recommendation.MarginUnreachable,
+		}
+		revenueImpact += results[i].RevenueImpact
+		profitImpact += results[i].ProfitImpact
+	}
+
+	return PricingResponse{
+		Success:       true,
+		Products:      results,
+		RevenueImpact: revenueImpact,
+		ProfitImpact:  profitImpact,
+		Message:       fmt.Sprintf("Prices recommended for %d products", len(results)),
+	}
+}
+
+// validatePricingProducts returns every invalid field of the products
+func validatePricingProducts(products []PricingProduct) []ItemError {
+	var errs itemErrors
+	seen := make(map[string]bool, len(products))
+	for i, product := range products {
+		errs.checkID(i, product.ID, seen)
+		errs.checkPositive(i, product.ID, "price", product.Price)
+		errs.checkNonNegative(i, product.ID, "unit_cost", product.UnitCost)
+		errs.checkNonNegative(i, product.ID, "daily_sales", product.DailySales)
+		if !(product.Elasticity <= 0) || product.Elasticity < -maxPricingElasticity {
+			errs.add(i, product.ID, "elasticity", fmt.Sprintf("must be between -%d and 0", maxPricingElasticity))
+		}
+		if product.MinMargin != nil && !validMinMargin(*product.MinMargin) {
+			errs.add(i, product.ID, "min_margin", "must be at least 0 and below 1")
+		}
+		if product.MaxDailyChange != nil && !validPriceChange(*product.MaxDailyChange) {
+			errs.add(i, product.ID, "max_daily_change", "must be at least 0 and below 1")
+		}
+	}
+	return errs
+}
+
+// validMinMargin reports whether a margin share is at least 0 and below 1
+func validMinMargin(margin float64) bool {
+	return margin >= 0 && margin < 1
+}
+
+// validPriceChange reports whether a relative price change is at least 0 and below 1
+func validPriceChange(change float64) bool {
+	return change >= 0 && change < 1
+}
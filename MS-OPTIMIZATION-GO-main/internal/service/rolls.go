@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// billBundleSize is the number of bills in a standard bundle (strap)
+const billBundleSize = 100
+
+// RolledDenomination represents the pieces of one denomination packed in rolls or bundles
+type RolledDenomination struct {
+	Rolls    int `json:"rolls"`
+	RollSize int `json:"roll_size,omitempty"`
+	Loose    int `json:"loose"`
+}
+
+// rollSizes returns the roll size of each denomination: the currency's standard coin rolls,
+// bundles of billBundleSize for bills, then the overrides (denomination, e.g. "0.25" -> size;
+// 0 keeps the denomination loose)
+func (c Currency) rollSizes(coins []int, overrides map[string]int) (map[int]int, error) {
+	sizes := make(map[int]int, len(coins))
+	for _, coin := range coins {
+		if size, ok := c.RollSizes[coin]; ok {
+			sizes[coin] = size
+		} else if c.SmallestBill > 0 && coin >= c.SmallestBill {
+			sizes[coin] = billBundleSize
+		}
+	}
+
+	for key, size := range overrides {
+		units, err := c.denominationKeyToMinorUnits(key)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 || size > maxDrawerCount {
+			return nil, fmt.Errorf("roll size for %s must be between 0 and %d", key, maxDrawerCount)
+		}
+		sizes[units] = size
+	}
+
+	return sizes, nil
+}
+
+// formatRolls packs a breakdown into rolls, keyed by formatted denomination (e.g. "$0.25")
+func (c Currency) formatRolls(breakdown, sizes map[int]int) map[string]RolledDenomination {
+	rolled := algorithms.BundleBreakdown(breakdown, sizes)
+	formatted := make(map[string]RolledDenomination, len(rolled))
+	for units, quantity := range rolled {
+		formatted[c.formatAmount(units)] = RolledDenomination{
+			Rolls:    quantity.Rolls,
+			RollSize: quantity.RollSize,
+			Loose:    quantity.Loose,
+		}
+	}
+	return formatted
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"time"
+)
+
+// Distance metrics for stops given by coordinates
+const (
+	RouteMetricEuclidean = "euclidean" // straight-line distance in the coordinates' unit
+	RouteMetricHaversine = "haversine" // great-circle kilometers, x as longitude and y as latitude
+)
+
+// Routing limits
+const (
+	maxRouteStops         = 300
+	maxWindowedRouteStops = 100
+	earthRadiusKm         = 6371.0
+	maxRouteDistance      = 1e9
+)
+
+// RouteRequest represents delivery stops to visit in one trip. Distances come from the
+// stops' coordinates, or from distance_matrix, indexed like stops, when it is given. With
+// depart_at the legs get arrival times, and stops may have time windows to arrive within
+type RouteRequest struct {
+	Stops          []RouteStop `json:"stops"`
+	DistanceMatrix [][]float64 `json:"distance_matrix,omitempty"` // distance_matrix[i][j] from stop i to stop j, need not be symmetric
+	Metric         string      `json:"metric,omitempty"`          // euclidean (default) or haversine
+	Start          string      `json:"start,omitempty"`           // stop the trip begins at, default the first
+	ReturnToStart  bool        `json:"return_to_start,omitempty"` // end the trip back at the start
+	DepartAt       *time.Time  `json:"depart_at,omitempty"`       // leaving the start, required with time windows
+	AverageSpeed   float64     `json:"average_speed,omitempty"`   // distance per hour, required with depart_at
+}
+
+// RouteStop describes a delivery address or the kitchen the trip leaves from
+type RouteStop struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name,omitempty"`
+	X              float64    `json:"x,omitempty"`
+	Y              float64    `json:"y,omitempty"`
+	ServiceMinutes float64    `json:"service_minutes,omitempty"` // time spent handing the order over
+	EarliestAt     *time.Time `json:"earliest_at,omitempty"`     // a driver arriving sooner waits until then
+	LatestAt       *time.Time `json:"latest_at,omitempty"`       // the driver must arrive by then
+}
+
+// RouteLeg represents one stretch of the route
+type RouteLeg struct {
+	From               string     `json:"from"`
+	To                 string     `json:"to"`
+	Distance           float64    `json:"distance"`
+	CumulativeDistance float64    `json:"cumulative_distance"`
+	ETA                *time.Time `json:"eta,omitempty"`          // arrival at to, with depart_at
+	WaitMinutes        float64    `json:"wait_minutes,omitempty"` // from arrival until to's earliest_at
+}
+
+// RouteWindowIssue explains why a stop's time window cannot be met
+type RouteWindowIssue struct {
+	StopID      string    `json:"stop_id"`
+	LatestAt    time.Time `json:"latest_at"`
+	ArriveAt    time.Time `json:"arrive_at"` // soonest arrival possible, or in the best route found
+	LateMinutes float64   `json:"late_minutes"`
+	Reason      string    `json:"reason"`
+}
+
+// RouteResponse represents an optimized visiting order
+type RouteResponse struct {
+	Success         bool               `json:"success"`
+	Order           []string           `json:"order"`
+	Legs            []RouteLeg         `json:"legs"`
+	TotalDistance   float64            `json:"total_distance"`
+	InitialDistance float64            `json:"initial_distance,omitempty"` // of the nearest-neighbor route before 2-opt, without time windows
+	FinishAt        *time.Time         `json:"finish_at,omitempty"`        // leaving the last stop, or back at the start
+	Diagnostics     []RouteWindowIssue `json:"diagnostics,omitempty"`      // why the time windows cannot all be met
+	Message         string             `json:"message"`
+}
+
+// OptimizeRoute orders delivery stops with nearest neighbor and 2-opt or, when stops have
+// time windows, searching for a route that meets them all and then is shortest
+func (os *OptimizationService) OptimizeRoute(req RouteRequest) RouteResponse {
+	dist, err := routeDistances(req.Stops, req.DistanceMatrix, req.Metric)
+	if err != nil {
+		return RouteResponse{Success: false, Message: err.Error()}
+	}
+	start := 0
+	if req.Start != "" {
+		start = stopIndex(req.Stops, req.Start)
+		if start < 0 {
+			return RouteResponse{Success: false, Message: fmt.Sprintf("start %q is not one of the stops", req.Start)}
+		}
+	}
+
+	windows, windowed, err := routeWindows(req, dist, start)
+	if err != nil {
+		return RouteResponse{Success: false, Message: err.Error()}
+	}
+
+	var response RouteResponse
+	var order []int
+	if windowed {
+		if len(req.Stops) > maxWindowedRouteStops {
+			return RouteResponse{Success: false, Message: fmt.Sprintf("at most %d stops are allowed with time windows", maxWindowedRouteStops)}
+		}
+		if issues := unreachableStops(req, windows, start); len(issues) > 0 {
+			return RouteResponse{Success: false, Diagnostics: issues, Message: fmt.Sprintf("time windows cannot all be met: %d stops cannot be reached in time", len(issues))}
+		}
+		tour := algorithms.SolveTSPWindows(dist, windows, start, req.ReturnToStart)
+		if !tour.Success {
+			return RouteResponse{Success: false, Message: tour.Message}
+		}
+		if tour.Lateness > 0 {
+			var issues []RouteWindowIssue
+			for p, s := range tour.Order {
+				if tour.Times[p].Late > 0 {
+					issues = append(issues, RouteWindowIssue{
+						StopID:      req.Stops[s].ID,
+						LatestAt:    *req.Stops[s].LatestAt,
+						ArriveAt:    req.DepartAt.Add(minutes(tour.Times[p].Arrive)),
+						LateMinutes: tour.Times[p].Late,
+						Reason:      "late in the best route found",
+					})
+				}
+			}
+			return RouteResponse{Success: false, Diagnostics: issues, Message: fmt.Sprintf("time windows cannot all be met: %d stops would be late", len(issues))}
+		}
+		order = tour.Order
+		response = RouteResponse{TotalDistance: tour.Distance, Message: tour.Message}
+	} else {
+		tour := algorithms.SolveTSP(dist, start, req.ReturnToStart)
+		if !tour.Success {
+			return RouteResponse{Success: false, Message: tour.Message}
+		}
+		order = tour.Order
+		response = RouteResponse{TotalDistance: tour.Distance, InitialDistance: tour.InitialDistance, Message: tour.Message}
+	}
+
+	stops := order
+	if req.ReturnToStart && len(order) > 1 {
+		stops = append(stops, start)
+	}
+	response.Success = true
+	response.Order = stopIDs(req.Stops, stops)
+	response.Legs = routeLegs(req.Stops, dist, stops)
+	if req.DepartAt != nil {
+		times, finish, _ := algorithms.ScheduleTour(windows, order, req.ReturnToStart)
+		for i := range response.Legs {
+			eta := req.DepartAt.Add(minutes(finish))
+			if i+1 < len(times) {
+				eta = req.DepartAt.Add(minutes(times[i+1].Arrive))
+				response.Legs[i].WaitMinutes = times[i+1].Wait
+			}
+			response.Legs[i].ETA = &eta
+		}
+		finishAt := req.DepartAt.Add(minutes(finish))
+		response.FinishAt = &finishAt
+	}
+	return response
+}
+
+// routeWindows validates the timing of the route and returns it in minutes from depart_at,
+// and whether any stop has a time window
+func routeWindows(req RouteRequest, dist [][]float64, start int) (algorithms.TSPWindows, bool, error) {
+	windowed := false
+	for i, stop := range req.Stops {
+		if !(stop.ServiceMinutes >= 0) || stop.ServiceMinutes > maxServiceMinutes {
+			return algorithms.TSPWindows{}, false, fmt.Errorf("service_minutes of stop %s must be between 0 and %d", stop.ID, maxServiceMinutes)
+		}
+		if stop.EarliestAt == nil && stop.LatestAt == nil {
+			continue
+		}
+		if i == start {
+			return algorithms.TSPWindows{}, false, fmt.Errorf("start stop %s cannot have a time window, set depart_at instead", stop.ID)
+		}
+		if stop.EarliestAt != nil && stop.LatestAt != nil && stop.EarliestAt.After(*stop.LatestAt) {
+			return algorithms.TSPWindows{}, false, fmt.Errorf("earliest_at of stop %s is after its latest_at", stop.ID)
+		}
+		windowed = true
+	}
+	if req.DepartAt == nil {
+		if windowed {
+			return algorithms.TSPWindows{}, false, errors.New("depart_at is required with time windows")
+		}
+		return algorithms.TSPWindows{}, false, nil
+	}
+	if !(req.AverageSpeed > 0) || req.AverageSpeed > maxAverageSpeed {
+		return algorithms.TSPWindows{}, false, fmt.Errorf("average_speed must be above 0 and at most %d with depart_at", maxAverageSpeed)
+	}
+
+	n := len(req.Stops)
+	windows := algorithms.TSPWindows{
+		Travel:   make([][]float64, n),
+		Service:  make([]float64, n),
+		Earliest: make([]float64, n),
+		Latest:   make([]float64, n),
+	}
+	for i, stop := range req.Stops {
+		windows.Travel[i] = make([]float64, n)
+		for j := range req.Stops {
+			windows.Travel[i][j] = dist[i][j] / req.AverageSpeed * 60
+		}
+		windows.Service[i] = stop.ServiceMinutes
+		windows.Earliest[i] = math.Inf(-1)
+		if stop.EarliestAt != nil {
+			windows.Earliest[i] = stop.EarliestAt.Sub(*req.DepartAt).Minutes()
+		}
+		windows.Latest[i] = math.Inf(1)
+		if stop.LatestAt != nil {
+			windows.Latest[i] = stop.LatestAt.Sub(*req.DepartAt).Minutes()
+		}
+	}
+	return windows, windowed, nil
+}
+
+// unreachableStops lists the stops that would be late even driven to straight from the start
+func unreachableStops(req RouteRequest, windows algorithms.TSPWindows, start int) []RouteWindowIssue {
+	var issues []RouteWindowIssue
+	for s, stop := range req.Stops {
+		if s == start || stop.LatestAt == nil {
+			continue
+		}
+		if arrive := windows.Travel[start][s]; arrive > windows.Latest[s] {
+			issues = append(issues, RouteWindowIssue{
+				StopID:      stop.ID,
+				LatestAt:    *stop.LatestAt,
+				ArriveAt:    req.DepartAt.Add(minutes(arrive)),
+				LateMinutes: arrive - windows.Latest[s],
+				Reason:      "cannot be reached by latest_at even as the first stop",
+			})
+		}
+	}
+	return issues
+}
+
+// routeDistances validates the stops and returns the distance between each pair
+func routeDistances(stops []RouteStop, matrix [][]float64, metric string) ([][]float64, error) {
+	if len(stops) == 0 || len(stops) > maxRouteStops {
+		return nil, fmt.Errorf("between 1 and %d stops are required", maxRouteStops)
+	}
+	seen := make(map[string]bool, len(stops))
+	for i, stop := range stops {
+		if stop.ID == "" {
+			return nil, fmt.Errorf("stop %d has no id", i)
+		}
+		if seen[stop.ID] {
+			return nil, fmt.Errorf("duplicate stop id %q", stop.ID)
+		}
+		seen[stop.ID] = true
+	}
+
+	if matrix != nil {
+		if metric != "" {
+			return nil, errors.New("give distance_matrix or metric, not both")
+		}
+		if len(matrix) != len(stops) {
+			return nil, fmt.Errorf("distance_matrix needs a row per stop, got %d for %d stops", len(matrix), len(stops))
+		}
+		for i, row := range matrix {
+			if len(row) != len(stops) {
+				return nil, fmt.Errorf("row %d of distance_matrix needs an entry per stop", i)
+			}
+			for j, d := range row {
+				if !(d >= 0) || d > maxRouteDistance {
+					return nil, fmt.Errorf("distance_matrix[%d][%d] must be between 0 and %g", i, j, maxRouteDistance)
+				}
+			}
+		}
+		return matrix, nil
+	}
+
+	var distance func(a, b RouteStop) float64
+	switch metric {
+	case "", RouteMetricEuclidean:
+		distance = func(a, b RouteStop) float64 { return math.Hypot(a.X-b.X, a.Y-b.Y) }
+	case RouteMetricHaversine:
+		for _, stop := range stops {
+			if math.Abs(stop.Y) > 90 || math.Abs(stop.X) > 180 {
+				return nil, fmt.Errorf("stop %s needs a latitude (y) within ±90 and a longitude (x) within ±180", stop.ID)
+			}
+		}
+		distance = haversineKm
+	default:
+		return nil, fmt.Errorf("invalid metric %q, expected %s or %s", metric, RouteMetricEuclidean, RouteMetricHaversine)
+	}
+	dist := make([][]float64, len(stops))
+	for i := range stops {
+		dist[i] = make([]float64, len(stops))
+		for j := range stops {
+			dist[i][j] = distance(stops[i], stops[j])
+		}
+	}
+	return dist, nil
+}
+
+// haversineKm returns the great-circle distance between two stops in kilometers
+func haversineKm(a, b RouteStop) float64 {
+	lat1, lat2 := a.Y*math.Pi/180, b.Y*math.Pi/180
+	dLat, dLng := lat2-lat1, (b.X-a.X)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// stopIndex returns the position of the stop with the given ID, or -1
+func stopIndex(stops []RouteStop, id string) int {
+	for i, stop := range stops {
+		if stop.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// stopIDs returns the IDs of the stops in order
+func stopIDs(stops []RouteStop, order []int) []string {
+	ids := make([]string, len(order))
+	for i, s := range order {
+		ids[i] = stops[s].ID
+	}
+	return ids
+}
+
+// routeLegs lists the legs between consecutive stops of order
+func routeLegs(stops []RouteStop, dist [][]float64, order []int) []RouteLeg {
+	legs := make([]RouteLeg, 0, len(order))
+	total := 0.0
+	for i := 1; i < len(order); i++ {
+		d := dist[order[i-1]][order[i]]
+		total += d
+		legs = append(legs, RouteLeg{From: stops[order[i-1]].ID, To: stops[order[i]].ID, Distance: d, CumulativeDistance: total})
+	}
+	return legs
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+	"ms-optimization-go/internal/notify"
+	"time"
+)
+
+// Seating event types reported by the reservations service
+const (
+	SeatingEventCancellation = "cancellation"
+	SeatingEventNoShow       = "no_show"
+)
+
+// SeatingEventRequest represents a cancellation or no-show on a floor plan. Waitlist is the
+// current waitlist, in the /waitlist format without tables or floor_plan_id; it is planned
+// again without the group, under the same constraints, from the floor's live occupancy
+// unless availability is given
+type SeatingEventRequest struct {
+	Type     string          `json:"type"` // cancellation or no_show
+	GroupID  string          `json:"group_id"`
+	TableID  string          `json:"table_id,omitempty"` // table seated or held for the group, released by the event
+	Waitlist WaitlistRequest `json:"waitlist"`
+}
+
+// SeatingEventResponse represents the seating plan recomputed after an event. It is also
+// the payload pushed to the seating plan webhook
+type SeatingEventResponse struct {
+	Success       bool              `json:"success"`
+	FloorPlanID   string            `json:"floor_plan_id"`
+	Event         string            `json:"event"`
+	GroupID       string            `json:"group_id"`
+	ReleasedTable string            `json:"released_table,omitempty"`
+	Plan          *WaitlistResponse `json:"plan,omitempty"`
+	Message       string            `json:"message"`
+	NotFound      bool              `json:"-"`
+	Conflict      bool              `json:"-"`
+}
+
+// HandleSeatingEvent takes a cancelled or no-show group off the waitlist, frees its table
+// and plans the waitlist again, pushing the new plan to the seating plan webhook
+func (os *OptimizationService) HandleSeatingEvent(id string, req SeatingEventRequest) SeatingEventResponse {
+	response := SeatingEventResponse{Success: false, FloorPlanID: id, Event: req.Type, GroupID: req.GroupID}
+	if req.Type != SeatingEventCancellation && req.Type != SeatingEventNoShow {
+		response.Message = fmt.Sprintf("invalid event type %q, expected %s or %s", req.Type, SeatingEventCancellation, SeatingEventNoShow)
+		return response
+	}
+	if req.GroupID == "" {
+		response.Message = "group_id is required"
+		return response
+	}
+
+	if _, err := os.resolveFloorPlan(id, FloorPlanQuery{}); err != nil {
+		failed := floorPlanErrorResponse(id, err)
+		response.Message, response.NotFound = failed.Message, failed.NotFound
+		return response
+	}
+
+	waitlist := req.Waitlist
+	if waitlist.FloorPlanID != "" && waitlist.FloorPlanID != id {
+		response.Message = "waitlist.floor_plan_id must match the floor plan"
+		return response
+	}
+	waitlist.FloorPlanID = id
+	if waitlist.Availability == nil {
+		waitlist.LiveOccupancy = true
+	}
+	groups := make([]WaitlistGroup, 0, len(waitlist.Groups))
+	for _, group := range waitlist.Groups {
+		if group.ID != req.GroupID {
+			groups = append(groups, group)
+		}
+	}
+	waitlist.Groups = groups
+	// Constraints keep applying to the other groups; those that named only this one are dropped
+	constraints := make([]AssignmentConstraint, 0, len(waitlist.Constraints))
+	for _, constraint := range waitlist.Constraints {
+		if len(constraint.Groups) > 0 {
+			others := make([]string, 0, len(constraint.Groups))
+			for _, groupID := range constraint.Groups {
+				if groupID != req.GroupID {
+					others = append(others, groupID)
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			constraint.Groups = others
+		}
+		constraints = append(constraints, constraint)
+	}
+	waitlist.Constraints = constraints
+
+	if req.TableID != "" {
+		occupancy, err := os.occupancyByTable(id)
+		if err != nil {
+			response.Message = fmt.Sprintf("Occupancy operation failed: %v", err)
+			return response
+		}
+		if table := occupancy[req.TableID]; table != nil && table.Occupied {
+			if table.GroupID != "" && table.GroupID != req.GroupID {
+				response.Message = fmt.Sprintf("Table %s is seated by group %s", req.TableID, table.GroupID)
+				response.Conflict = true
+				return response
+			}
+			released := os.ReleaseTable(id, req.TableID, ReleaseTableRequest{Version: table.Version})
+			if !released.Success {
+				response.Message = released.Message
+				response.NotFound, response.Conflict = released.NotFound, released.Conflict
+				return response
+			}
+			response.ReleasedTable = req.TableID
+		}
+	}
+
+	plan := WaitlistResponse{Success: true, Queue: []WaitlistEntry{}, Message: "No groups waiting"}
+	if len(waitlist.Groups) > 0 {
+		plan = os.PlanWaitlist(waitlist)
+		if !plan.Success {
+			response.Message = plan.Message
+			response.NotFound = plan.NotFound
+			return response
+		}
+	}
+
+	response.Success = true
+	response.Plan = &plan
+	response.Message = fmt.Sprintf("Waitlist replanned after %s of group %s", req.Type, req.GroupID)
+	os.planNotifier.Notify(notify.Event{
+		Type:       notify.EventSeatingPlanUpdated,
+		OccurredAt: time.Now().UTC(),
+		Data:       response,
+	})
+	return response
+}
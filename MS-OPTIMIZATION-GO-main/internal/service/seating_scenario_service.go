@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxSeatingScenarios limits the scenarios simulated in one request
+const maxSeatingScenarios = 20
+
+// SeatingScenariosRequest represents hypothetical arrival mixes to simulate against one
+// floor, given as tables or a stored floor plan as in a waitlist request
+type SeatingScenariosRequest struct {
+	Tables                []WaitlistTable        `json:"tables,omitempty"`
+	FloorPlanID           string                 `json:"floor_plan_id,omitempty"`
+	FloorPlanVersion      int                    `json:"floor_plan_version,omitempty"`
+	Day                   string                 `json:"day,omitempty"`
+	Availability          map[string]float64     `json:"availability,omitempty"`
+	Holds                 map[string]float64     `json:"holds,omitempty"`
+	HoldPriority          int                    `json:"hold_priority,omitempty"`
+	FairnessMinutes       *float64               `json:"fairness_minutes,omitempty"`
+	Objective             string                 `json:"objective,omitempty"`
+	OverbookingRisk       float64                `json:"overbooking_risk,omitempty"`
+	SectionBalanceMinutes float64                `json:"section_balance_minutes,omitempty"`
+	Constraints           []AssignmentConstraint `json:"constraints,omitempty"`
+	TurnMinutes           float64                `json:"turn_minutes,omitempty"`
+	TurnMinutesBySize     map[int]float64        `json:"turn_minutes_by_size,omitempty"`
+	HorizonMinutes        float64                `json:"horizon_minutes,omitempty"` // length of service the KPIs cover, default until the last group leaves
+	Scenarios             []SeatingScenario      `json:"scenarios"`
+}
+
+// SeatingScenario is one hypothetical mix of groups; arrival_minutes sets when each walks in
+type SeatingScenario struct {
+	Name   string          `json:"name"`
+	Groups []WaitlistGroup `json:"groups"`
+}
+
+// SeatingScenarioResult represents the KPIs of one simulated scenario
+type SeatingScenarioResult struct {
+	Name               string  `json:"name"`
+	Groups             int     `json:"groups"`
+	SeatedGroups       int     `json:"seated_groups"` // seated within the horizon
+	SeatedPercent      float64 `json:"seated_percent"`
+	Covers             int     `json:"covers"`
+	SeatedCovers       int     `json:"seated_covers"`
+	AverageWaitMinutes float64 `json:"average_wait_minutes"` // of the groups seated within the horizon
+	MaxWaitMinutes     float64 `json:"max_wait_minutes"`
+	CoversPerHour      float64 `json:"covers_per_hour"`
+	SeatUtilization    float64 `json:"seat_utilization"`  // expected occupied seat time over available seat time
+	ProjectedRevenue   float64 `json:"projected_revenue"` // expected spend of the groups seated within the horizon, weighted by their chance of showing up
+	RevenuePerSeatHour float64 `json:"revenue_per_seat_hour"`
+}
+
+// SeatingScenariosResponse represents the comparative KPIs of a set of scenarios
+type SeatingScenariosResponse struct {
+	Success          bool                    `json:"success"`
+	Scenarios        []SeatingScenarioResult `json:"scenarios"`
+	Seats            int                     `json:"seats"`
+	FloorPlanVersion int                     `json:"floor_plan_version,omitempty"`
+	Message          string                  `json:"message"`
+	NotFound         bool                    `json:"-"`
+}
+
+// SimulateSeatingScenarios runs the waitlist planner on each scenario and reports how
+// well the floor copes with it
+func (os *OptimizationService) SimulateSeatingScenarios(req SeatingScenariosRequest) SeatingScenariosResponse {
+	if len(req.Scenarios) == 0 || len(req.Scenarios) > maxSeatingScenarios {
+		return SeatingScenariosResponse{Success: false, Message: fmt.Sprintf("between 1 and %d scenarios are required", maxSeatingScenarios)}
+	}
+	if !(req.HorizonMinutes >= 0) || req.HorizonMinutes > 24*60 {
+		return SeatingScenariosResponse{Success: false, Message: "horizon_minutes must be between 0 and 1440"}
+	}
+
+	// Resolve the floor plan once so every scenario runs against the same version
+	start := time.Now()
+	tables := req.Tables
+	floorPlanVersion := 0
+	if req.FloorPlanID != "" {
+		plan, err := os.waitlistFloorPlan(WaitlistRequest{Tables: req.Tables, FloorPlanID: req.FloorPlanID, FloorPlanVersion: req.FloorPlanVersion, Day: req.Day}, start)
+		if err != nil {
+			response := floorPlanErrorResponse(req.FloorPlanID, err)
+			return SeatingScenariosResponse{Success: false, Message: response.Message, NotFound: response.NotFound}
+		}
+		if tables, err = floorPlanWaitlistTables(plan, req.Availability, req.Holds); err != nil {
+			return SeatingScenariosResponse{Success: false, Message: err.Error()}
+		}
+		floorPlanVersion = plan.Version
+	} else if req.FloorPlanVersion != 0 || req.Day != "" || req.Availability != nil || req.Holds != nil {
+		return SeatingScenariosResponse{Success: false, Message: "floor_plan_version, day, availability and holds require floor_plan_id"}
+	}
+	if len(tables) == 0 || len(tables) > maxWaitlistTables {
+		return SeatingScenariosResponse{Success: false, Message: fmt.Sprintf("between 1 and %d tables are required", maxWaitlistTables)}
+	}
+
+	seats := 0
+	for _, table := range tables {
+		seats += table.Capacity
+	}
+
+	results := make([]SeatingScenarioResult, len(req.Scenarios))
+	for i, scenario := range req.Scenarios {
+		name := scenario.Name
+		if name == "" {
+			name = fmt.Sprintf("scenario %d", i+1)
+		}
+
+		plan := os.PlanWaitlist(WaitlistRequest{
+			Tables:                tables,
+			Groups:                scenario.Groups,
+			TurnMinutes:           req.TurnMinutes,
+			StartTime:             &start,
+			TurnMinutesBySize:     req.TurnMinutesBySize,
+			HoldPriority:          req.HoldPriority,
+			FairnessMinutes:       req.FairnessMinutes,
+			Objective:             req.Objective,
+			OverbookingRisk:       req.OverbookingRisk,
+			SectionBalanceMinutes: req.SectionBalanceMinutes,
+			Constraints:           req.Constraints,
+		})
+		if !plan.Success {
+			return SeatingScenariosResponse{Success: false, Message: fmt.Sprintf("%s: %s", name, plan.Message)}
+		}
+		results[i] = scenarioKPIs(name, scenario.Groups, plan.Queue, start, req.HorizonMinutes, seats)
+	}
+
+	return SeatingScenariosResponse{
+		Success:          true,
+		Scenarios:        results,
+		Seats:            seats,
+		FloorPlanVersion: floorPlanVersion,
+		Message:          fmt.Sprintf("%d scenarios simulated", len(results)),
+	}
+}
+
+// scenarioKPIs summarizes a simulated seating plan. Groups seated after the horizon count
+// as not seated, and only the seat time within the horizon counts as used
+func scenarioKPIs(name string, groups []WaitlistGroup, queue []WaitlistEntry, start time.Time, horizon float64, seats int) SeatingScenarioResult {
+	if horizon == 0 {
+		for _, entry := range queue {
+			horizon = math.Max(horizon, entry.TableFreeAt.Sub(start).Minutes())
+		}
+	}
+
+	result := SeatingScenarioResult{Name: name, Groups: len(groups)}
+	for _, group := range groups {
+		result.Covers += group.Size
+	}
+
+	totalWait, seatMinutes := 0.0, 0.0
+	for _, entry := range queue {
+		seatAt := entry.SeatAt.Sub(start).Minutes()
+		if seatAt >= horizon {
+			continue
+		}
+		result.SeatedGroups++
+		result.SeatedCovers += entry.Size
+		shows := 1 - entry.NoShowProbability
+		result.ProjectedRevenue += entry.ExpectedSpend * shows
+		totalWait += entry.EstimatedWaitMinutes
+		result.MaxWaitMinutes = math.Max(result.MaxWaitMinutes, entry.EstimatedWaitMinutes)
+		seatMinutes += float64(entry.Size) * shows * (math.Min(horizon, seatAt+entry.DiningMinutes) - seatAt)
+	}
+
+	if result.Groups > 0 {
+		result.SeatedPercent = float64(result.SeatedGroups) / float64(result.Groups) * 100
+	}
+	if result.SeatedGroups > 0 {
+		result.AverageWaitMinutes = totalWait / float64(result.SeatedGroups)
+	}
+	if horizon > 0 {
+		result.CoversPerHour = float64(result.SeatedCovers) / (horizon / 60)
+		result.SeatUtilization = seatMinutes / (float64(seats) * horizon)
+		result.RevenuePerSeatHour = result.ProjectedRevenue / (float64(seats) * horizon / 60)
+	}
+	return result
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"sort"
+	"time"
+)
+
+// House rules tables rank groups by, applied in order
+const (
+	HouseRulePriority = "priority" // higher priority first
+	HouseRuleWait     = "wait"     // longest wait first
+	HouseRuleFit      = "fit"      // fewest empty seats first
+	HouseRuleSize     = "size"     // larger groups first
+	HouseRuleSpend    = "spend"    // higher expected spend first
+)
+
+// defaultHouseRules rank groups when a request gives none
+var defaultHouseRules = []string{HouseRulePriority, HouseRuleWait, HouseRuleFit}
+
+// StableMatchRequest represents waiting groups and the tables free for them now, given
+// as tables or as the free tables of a stored floor plan's live occupancy
+type StableMatchRequest struct {
+	Tables      []StableMatchTable `json:"tables,omitempty"`
+	FloorPlanID string             `json:"floor_plan_id,omitempty"`
+	Groups      []StableMatchGroup `json:"groups"`
+	HouseRules  []string           `json:"house_rules,omitempty"` // default priority, wait, fit
+
+	// Hard and soft constraints on the tables groups may take. Hard ones make the pairs
+	// that break them unacceptable; every table is free now, so no wait is traded for soft
+	// ones and they move those pairs down both sides' rankings, the higher the total penalty
+	// the further down, ahead of fit and the house rules
+	Constraints []AssignmentConstraint `json:"constraints,omitempty"`
+}
+
+// StableMatchTable describes a free table. Preferences lists group IDs the table ranks
+// first, in order; the other groups follow by the house rules
+type StableMatchTable struct {
+	ID         string   `json:"id"`
+	Capacity   int      `json:"capacity"`
+	Zone       string   `json:"zone,omitempty"`       // for constraints, taken from the floor plan when floor_plan_id is given
+	Attributes []string `json:"attributes,omitempty"` // for constraints, as zone
+
+	WheelchairAccessible bool     `json:"wheelchair_accessible,omitempty"` // as zone
+	X                    float64  `json:"x,omitempty"`
+	Y                    float64  `json:"y,omitempty"`
+	Preferences          []string `json:"preferences,omitempty"`
+}
+
+// StableMatchGroup describes a waiting group. Preferences lists the table IDs it accepts,
+// best first; without it the group accepts every table it fits, fewest empty seats first
+type StableMatchGroup struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	Size          int      `json:"size"`
+	Priority      int      `json:"priority,omitempty"`
+	WaitedMinutes float64  `json:"waited_minutes,omitempty"`
+	ExpectedSpend float64  `json:"expected_spend,omitempty"`
+	Preferences   []string `json:"preferences,omitempty"`
+
+	// Needs a wheelchair-accessible table and never accepts another
+	WheelchairAccess bool `json:"wheelchair_access,omitempty"`
+}
+
+// StableMatch represents one group seated at one table
+type StableMatch struct {
+	GroupID    string                `json:"group_id"`
+	Name       string                `json:"name,omitempty"`
+	TableID    string                `json:"table_id"`
+	GroupRank  int                   `json:"group_rank"`           // the table's place in the group's preferences, from 1
+	TableRank  int                   `json:"table_rank"`           // the group's place in the table's ranking, from 1
+	Violations []ConstraintViolation `json:"violations,omitempty"` // soft constraints the match breaks
+}
+
+// StableMatchResponse represents a stable matching of groups to tables
+type StableMatchResponse struct {
+	Success         bool          `json:"success"`
+	Matches         []StableMatch `json:"matches"`
+	UnmatchedGroups []string      `json:"unmatched_groups,omitempty"`
+	UnmatchedTables []string      `json:"unmatched_tables,omitempty"`
+	HouseRules      []string      `json:"house_rules"`
+	Penalty         float64       `json:"penalty,omitempty"` // total penalty of the soft constraints broken
+	Message         string        `json:"message"`
+	NotFound        bool          `json:"-"`
+}
+
+// MatchTablesStable seats waiting groups at free tables so that no group and table would
+// both rather be matched to each other than to their partners. Groups propose, so each
+// gets the best table any stable matching can give it. Rankings still tied after the
+// house rules go by ID, so the order of the request does not change the matching
+func (os *OptimizationService) MatchTablesStable(req StableMatchRequest) StableMatchResponse {
+	tables := req.Tables
+	if req.FloorPlanID != "" {
+		if len(tables) > 0 {
+			return StableMatchResponse{Success: false, Message: "give tables or floor_plan_id, not both"}
+		}
+		free, response := os.freeFloorPlanTables(req.FloorPlanID)
+		if !response.Success {
+			return StableMatchResponse{Success: false, Message: response.Message, NotFound: response.NotFound}
+		}
+		tables = free
+	}
+	if len(tables) == 0 || len(tables) > maxWaitlistTables {
+		return StableMatchResponse{Success: false, Message: fmt.Sprintf("between 1 and %d free tables are required", maxWaitlistTables)}
+	}
+	if len(req.Groups) == 0 || len(req.Groups) > maxWaitlistGroups {
+		return StableMatchResponse{Success: false, Message: fmt.Sprintf("between 1 and %d groups are required", maxWaitlistGroups)}
+	}
+
+	rules := req.HouseRules
+	if len(rules) == 0 {
+		rules = defaultHouseRules
+	}
+	for _, rule := range rules {
+		switch rule {
+		case HouseRulePriority, HouseRuleWait, HouseRuleFit, HouseRuleSize, HouseRuleSpend:
+		default:
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("invalid house rule %q", rule)}
+		}
+	}
+
+	tableIndex := make(map[string]int, len(tables))
+	for i, table := range tables {
+		if table.ID == "" {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("table %d has no id", i)}
+		}
+		if _, ok := tableIndex[table.ID]; ok {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("duplicate table id %q", table.ID)}
+		}
+		if table.Capacity < 1 || table.Capacity > maxTableCapacity {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("capacity of table %s must be between 1 and %d", table.ID, maxTableCapacity)}
+		}
+		tableIndex[table.ID] = i
+	}
+
+	groupIDs, sizes, access := make([]string, len(req.Groups)), make([]int, len(req.Groups)), make([]bool, len(req.Groups))
+	for i, group := range req.Groups {
+		groupIDs[i], sizes[i], access[i] = group.ID, group.Size, group.WheelchairAccess
+	}
+	constraintTables := make([]constraintTable, len(tables))
+	for i, table := range tables {
+		constraintTables[i] = constraintTable{Capacity: table.Capacity, Zone: table.Zone, Attributes: table.Attributes, X: table.X, Y: table.Y, Accessible: table.WheelchairAccessible}
+	}
+	constraints, err := compileConstraints(req.Constraints, groupIDs, access, constraintTables)
+	if err != nil {
+		return StableMatchResponse{Success: false, Message: err.Error()}
+	}
+	penalty := constraints.penalties(sizes)
+	penaltyOf := func(g, t int) float64 {
+		if penalty == nil {
+			return 0
+		}
+		return penalty[g][t]
+	}
+	// acceptable reports whether group g fits table t and no hard constraint rules it out
+	acceptable := func(g, t int) bool {
+		return req.Groups[g].Size <= tables[t].Capacity && !math.IsInf(penaltyOf(g, t), 1)
+	}
+
+	groupIndex := make(map[string]int, len(req.Groups))
+	preferences := make([][]int, len(req.Groups))
+	for i, group := range req.Groups {
+		if group.ID == "" {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("group %d has no id", i)}
+		}
+		if _, ok := groupIndex[group.ID]; ok {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("duplicate group id %q", group.ID)}
+		}
+		groupIndex[group.ID] = i
+		if group.Size < 1 || group.Size > maxTableCapacity {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("size of group %s must be between 1 and %d", group.ID, maxTableCapacity)}
+		}
+		if !(group.WaitedMinutes >= 0) || math.IsInf(group.WaitedMinutes, 0) {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("waited_minutes of group %s must be a non-negative number", group.ID)}
+		}
+		if !(group.ExpectedSpend >= 0) || math.IsInf(group.ExpectedSpend, 0) {
+			return StableMatchResponse{Success: false, Message: fmt.Sprintf("expected_spend of group %s must be a non-negative number", group.ID)}
+		}
+
+		if len(group.Preferences) == 0 {
+			for t := range tables {
+				if acceptable(i, t) {
+					preferences[i] = append(preferences[i], t)
+				}
+			}
+			sort.SliceStable(preferences[i], func(a, b int) bool {
+				ta, tb := preferences[i][a], preferences[i][b]
+				if penaltyOf(i, ta) != penaltyOf(i, tb) {
+					return penaltyOf(i, ta) < penaltyOf(i, tb)
+				}
+				if tables[ta].Capacity != tables[tb].Capacity {
+					return tables[ta].Capacity < tables[tb].Capacity
+				}
+				return tables[ta].ID < tables[tb].ID
+			})
+			continue
+		}
+		listed := make(map[int]bool, len(group.Preferences))
+		for _, id := range group.Preferences {
+			t, ok := tableIndex[id]
+			if !ok {
+				return StableMatchResponse{Success: false, Message: fmt.Sprintf("group %s prefers unknown table %q", group.ID, id)}
+			}
+			if listed[t] {
+				return StableMatchResponse{Success: false, Message: fmt.Sprintf("group %s lists table %s twice", group.ID, id)}
+			}
+			listed[t] = true
+			// Tables the group does not fit or may not take are never acceptable
+			if acceptable(i, t) {
+				preferences[i] = append(preferences[i], t)
+			}
+		}
+	}
+
+	ranks := make([][]int, len(tables))
+	for t, table := range tables {
+		order := make([]int, 0, len(req.Groups))
+		listed := make(map[int]bool, len(table.Preferences))
+		for _, id := range table.Preferences {
+			g, ok := groupIndex[id]
+			if !ok {
+				return StableMatchResponse{Success: false, Message: fmt.Sprintf("table %s prefers unknown group %q", table.ID, id)}
+			}
+			if listed[g] {
+				return StableMatchResponse{Success: false, Message: fmt.Sprintf("table %s lists group %s twice", table.ID, id)}
+			}
+			listed[g] = true
+			order = append(order, g)
+		}
+		rest := make([]int, 0, len(req.Groups)-len(order))
+		for g := range req.Groups {
+			if !listed[g] {
+				rest = append(rest, g)
+			}
+		}
+		sort.SliceStable(rest, func(a, b int) bool {
+			if penaltyOf(rest[a], t) != penaltyOf(rest[b], t) {
+				return penaltyOf(rest[a], t) < penaltyOf(rest[b], t)
+			}
+			ga, gb := req.Groups[rest[a]], req.Groups[rest[b]]
+			if houseRuleLess(rules, table, ga, gb) || houseRuleLess(rules, table, gb, ga) {
+				return houseRuleLess(rules, table, ga, gb)
+			}
+			return ga.ID < gb.ID
+		})
+
+		ranks[t] = make([]int, len(req.Groups))
+		for g := range ranks[t] {
+			ranks[t][g] = -1
+		}
+		rank := 0
+		for _, g := range append(order, rest...) {
+			if acceptable(g, t) {
+				ranks[t][g] = rank
+				rank++
+			}
+		}
+	}
+
+	match := algorithms.StableMatching(preferences, ranks)
+
+	matched := make([]bool, len(tables))
+	matches := make([]StableMatch, 0, len(tables))
+	var unmatchedGroups []string
+	totalPenalty := 0.0
+	for g, t := range match {
+		group := req.Groups[g]
+		if t < 0 {
+			unmatchedGroups = append(unmatchedGroups, group.ID)
+			continue
+		}
+		matched[t] = true
+		groupRank := 0
+		for position, preferred := range preferences[g] {
+			if preferred == t {
+				groupRank = position + 1
+			}
+		}
+		matches = append(matches, StableMatch{
+			GroupID:    group.ID,
+			Name:       group.Name,
+			TableID:    tables[t].ID,
+			GroupRank:  groupRank,
+			TableRank:  ranks[t][g] + 1,
+			Violations: constraints.violations(g, group.Size, t),
+		})
+		totalPenalty += penaltyOf(g, t)
+	}
+	var unmatchedTables []string
+	for t, table := range tables {
+		if !matched[t] {
+			unmatchedTables = append(unmatchedTables, table.ID)
+		}
+	}
+
+	return StableMatchResponse{
+		Success:         true,
+		Matches:         matches,
+		UnmatchedGroups: unmatchedGroups,
+		UnmatchedTables: unmatchedTables,
+		HouseRules:      rules,
+		Penalty:         totalPenalty,
+		Message:         fmt.Sprintf("%d of %d groups matched to %d free tables", len(matches), len(req.Groups), len(tables)),
+	}
+}
+
+// houseRuleLess reports whether table ranks group a above group b by the house rules
+func houseRuleLess(rules []string, table StableMatchTable, a, b StableMatchGroup) bool {
+	for _, rule := range rules {
+		switch rule {
+		case HouseRulePriority:
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+		case HouseRuleWait:
+			if a.WaitedMinutes != b.WaitedMinutes {
+				return a.WaitedMinutes > b.WaitedMinutes
+			}
+		case HouseRuleFit:
+			// Groups too large for the table rank last and are never matched to it
+			emptyA, emptyB := table.Capacity-a.Size, table.Capacity-b.Size
+			if (emptyA < 0) != (emptyB < 0) {
+				return emptyB < 0
+			}
+			if emptyA != emptyB {
+				return emptyA < emptyB
+			}
+		case HouseRuleSize:
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+		case HouseRuleSpend:
+			if a.ExpectedSpend != b.ExpectedSpend {
+				return a.ExpectedSpend > b.ExpectedSpend
+			}
+		}
+	}
+	return false
+}
+
+// freeFloorPlanTables lists the tables of today's version of a floor plan nobody is seated at
+func (os *OptimizationService) freeFloorPlanTables(id string) ([]StableMatchTable, FloorPlanResponse) {
+	plan, err := os.resolveFloorPlan(id, FloorPlanQuery{Day: time.Now().Weekday().String()})
+	if err != nil {
+		return nil, floorPlanErrorResponse(id, err)
+	}
+	occupancy, err := os.occupancyByTable(id)
+	if err != nil {
+		return nil, FloorPlanResponse{Success: false, Message: fmt.Sprintf("failed to read occupancy: %v", err)}
+	}
+
+	var tables []StableMatchTable
+	for _, table := range plan.Tables {
+		if state := occupancy[table.ID]; state == nil || !state.Occupied {
+			tables = append(tables, StableMatchTable{
+				ID:                   table.ID,
+				Capacity:             table.Capacity,
+				Zone:                 table.Zone,
+				Attributes:           table.Attributes,
+				X:                    table.X,
+				Y:                    table.Y,
+				WheelchairAccessible: table.WheelchairAccessible,
+			})
+		}
+	}
+	return tables, FloorPlanResponse{Success: true}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Staff assignment limits
+const (
+	maxStaffMembers     = 200
+	maxStations         = 100
+	maxStationPositions = 20
+	maxStaffAssignSlots = 500
+	maxSeniorityYears   = 60
+)
+
+// StaffAssignmentRequest represents the staff on shift and the stations to cover
+type StaffAssignmentRequest struct {
+	Staff    []StaffMember `json:"staff"`
+	Stations []Station     `json:"stations"`
+}
+
+// StaffMember describes a bartender or waiter on shift
+type StaffMember struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	Skills    []string `json:"skills,omitempty"`    // e.g. cocktails, wine, cash
+	Seniority float64  `json:"seniority,omitempty"` // years of experience
+	MaxLoad   float64  `json:"max_load,omitempty"`  // busiest station load they can take, 0 for no limit
+}
+
+// Station describes a bar station or floor section
+type Station struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name,omitempty"`
+	RequiredSkills  []string `json:"required_skills,omitempty"`  // staff without all of them cannot take the station
+	PreferredSkills []string `json:"preferred_skills,omitempty"` // each one held improves the match
+	ExpectedLoad    float64  `json:"expected_load"`              // e.g. drinks or covers per hour
+	Positions       int      `json:"positions,omitempty"`        // staff needed, default 1
+}
+
+// StaffAssignment represents one staff member placed at a station
+type StaffAssignment struct {
+	StaffID   string  `json:"staff_id"`
+	Name      string  `json:"name,omitempty"`
+	StationID string  `json:"station_id"`
+	Position  int     `json:"position"` // from 1, for stations with several positions
+	Score     float64 `json:"score"`
+}
+
+// StaffAssignmentResponse represents the staffing of the stations
+type StaffAssignmentResponse struct {
+	Success          bool              `json:"success"`
+	Assignments      []StaffAssignment `json:"assignments"`
+	UnfilledStations []string          `json:"unfilled_stations,omitempty"` // a station once per position nobody could take
+	UnassignedStaff  []string          `json:"unassigned_staff,omitempty"`
+	TotalScore       float64           `json:"total_score"`
+	Message          string            `json:"message"`
+}
+
+// AssignStaff places staff at stations with the Hungarian method, filling as many positions
+// as the skills and load limits allow and, among those assignments, maximizing the total
+// score. A staff member's score at a station is its expected load times one plus their
+// seniority relative to the most senior on shift plus the share of its preferred skills
+// they hold, so the busiest stations get the most experienced and best-suited staff
+func (os *OptimizationService) AssignStaff(req StaffAssignmentRequest) StaffAssignmentResponse {
+	if len(req.Staff) == 0 || len(req.Staff) > maxStaffMembers {
+		return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("between 1 and %d staff members are required", maxStaffMembers)}
+	}
+	if len(req.Stations) == 0 || len(req.Stations) > maxStations {
+		return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("between 1 and %d stations are required", maxStations)}
+	}
+
+	seen := make(map[string]bool, len(req.Staff))
+	mostSenior := 0.0
+	for i, member := range req.Staff {
+		if member.ID == "" {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("staff member %d has no id", i)}
+		}
+		if seen[member.ID] {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("duplicate staff id %q", member.ID)}
+		}
+		seen[member.ID] = true
+		if !(member.Seniority >= 0) || member.Seniority > maxSeniorityYears {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("seniority of %s must be between 0 and %d", member.ID, maxSeniorityYears)}
+		}
+		if !(member.MaxLoad >= 0) || math.IsInf(member.MaxLoad, 0) {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("max_load of %s must be a non-negative number", member.ID)}
+		}
+		mostSenior = math.Max(mostSenior, member.Seniority)
+	}
+
+	// Each position of a station is a column of the assignment, holding the station
+	var positions []int
+	seen = make(map[string]bool, len(req.Stations))
+	for i, station := range req.Stations {
+		if station.ID == "" {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("station %d has no id", i)}
+		}
+		if seen[station.ID] {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("duplicate station id %q", station.ID)}
+		}
+		seen[station.ID] = true
+		if !(station.ExpectedLoad >= 0) || math.IsInf(station.ExpectedLoad, 0) {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("expected_load of station %s must be a non-negative number", station.ID)}
+		}
+		count := station.Positions
+		if count == 0 {
+			count = 1
+		}
+		if count < 0 || count > maxStationPositions {
+			return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("positions of station %s must be between 1 and %d", station.ID, maxStationPositions)}
+		}
+		for n := 0; n < count; n++ {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) > maxStaffAssignSlots {
+		return StaffAssignmentResponse{Success: false, Message: fmt.Sprintf("at most %d positions are allowed", maxStaffAssignSlots)}
+	}
+
+	// score[s][i] is staff member s's score at station i, NaN where they cannot take it
+	score := make([][]float64, len(req.Staff))
+	for s, member := range req.Staff {
+		skills := make(map[string]bool, len(member.Skills))
+		for _, skill := range member.Skills {
+			skills[skill] = true
+		}
+		experience := 0.0
+		if mostSenior > 0 {
+			experience = member.Seniority / mostSenior
+		}
+
+		score[s] = make([]float64, len(req.Stations))
+		for i, station := range req.Stations {
+			allowed := member.MaxLoad == 0 || station.ExpectedLoad <= member.MaxLoad
+			for _, skill := range station.RequiredSkills {
+				allowed = allowed && skills[skill]
+			}
+			if !allowed {
+				score[s][i] = math.NaN()
+				continue
+			}
+			fit := 1.0
+			if len(station.PreferredSkills) > 0 {
+				held := 0
+				for _, skill := range station.PreferredSkills {
+					if skills[skill] {
+						held++
+					}
+				}
+				fit = float64(held) / float64(len(station.PreferredSkills))
+			}
+			score[s][i] = station.ExpectedLoad * (1 + experience + fit)
+		}
+	}
+
+	cost := make([][]float64, len(req.Staff))
+	for s := range cost {
+		cost[s] = make([]float64, len(positions))
+		for p, station := range positions {
+			if math.IsNaN(score[s][station]) {
+				cost[s][p] = math.Inf(1)
+			} else {
+				cost[s][p] = -score[s][station]
+			}
+		}
+	}
+	assignment := algorithms.Hungarian(cost)
+
+	filled := make([]bool, len(positions))
+	staffed := make([]int, len(req.Stations))
+	assignments := make([]StaffAssignment, 0, len(positions))
+	var unassigned []string
+	total := 0.0
+	for s, p := range assignment {
+		member := req.Staff[s]
+		if p < 0 {
+			unassigned = append(unassigned, member.ID)
+			continue
+		}
+		filled[p] = true
+		station := positions[p]
+		staffed[station]++
+		assignments = append(assignments, StaffAssignment{
+			StaffID:   member.ID,
+			Name:      member.Name,
+			StationID: req.Stations[station].ID,
+			Position:  staffed[station],
+			Score:     score[s][station],
+		})
+		total += score[s][station]
+	}
+	var unfilled []string
+	for p, station := range positions {
+		if !filled[p] {
+			unfilled = append(unfilled, req.Stations[station].ID)
+		}
+	}
+
+	return StaffAssignmentResponse{
+		Success:          true,
+		Assignments:      assignments,
+		UnfilledStations: unfilled,
+		UnassignedStaff:  unassigned,
+		TotalScore:       total,
+		Message:          fmt.Sprintf("%d of %d positions filled by %d staff", len(assignments), len(positions), len(req.Staff)),
+	}
+}
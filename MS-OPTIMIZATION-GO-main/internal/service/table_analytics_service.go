@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/models"
+	"time"
+)
+
+// Table analytics periods
+const (
+	defaultAnalyticsDays = 7
+	maxAnalyticsDays     = 366
+)
+
+// TableAnalyticsRequest selects the floor plan and the period to analyze
+type TableAnalyticsRequest struct {
+	FloorPlanID string `form:"floor_plan_id"`
+	From        string `form:"from"` // RFC 3339, inclusive, default 7 days before to
+	To          string `form:"to"`   // RFC 3339, exclusive, default now
+}
+
+// TableUtilization represents how one table was used over the period
+type TableUtilization struct {
+	TableID            string  `json:"table_id"`
+	Capacity           int     `json:"capacity"`
+	Zone               string  `json:"zone,omitempty"`
+	X                  float64 `json:"x"`
+	Y                  float64 `json:"y"`
+	Sessions           int     `json:"sessions"`
+	Covers             int     `json:"covers"`
+	OccupiedMinutes    float64 `json:"occupied_minutes"`
+	Utilization        float64 `json:"utilization"`      // share of the period the table was occupied
+	SeatUtilization    float64 `json:"seat_utilization"` // occupied seat time over available seat time
+	AverageFill        float64 `json:"average_fill"`     // average group size over capacity, how well groups fit the table
+	AverageStayMinutes float64 `json:"average_stay_minutes"`
+}
+
+// HeatmapPoint is one table's value at its floor coordinates
+type HeatmapPoint struct {
+	TableID string  `json:"table_id"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Value   float64 `json:"value"`
+}
+
+// TableHeatmap represents a metric over the floor, with the bounds of the coordinates
+type TableHeatmap struct {
+	Metric string         `json:"metric"`
+	MinX   float64        `json:"min_x"`
+	MinY   float64        `json:"min_y"`
+	MaxX   float64        `json:"max_x"`
+	MaxY   float64        `json:"max_y"`
+	Points []HeatmapPoint `json:"points"`
+}
+
+// TableAnalyticsResponse represents the utilization of a floor plan's tables over a period
+type TableAnalyticsResponse struct {
+	Success          bool               `json:"success"`
+	FloorPlanID      string             `json:"floor_plan_id"`
+	FloorPlanVersion int                `json:"floor_plan_version,omitempty"`
+	From             time.Time          `json:"from"`
+	To               time.Time          `json:"to"`
+	Tables           []TableUtilization `json:"tables"`
+	Utilization      float64            `json:"utilization"`      // across all tables
+	SeatUtilization  float64            `json:"seat_utilization"` // across all seats
+	Heatmap          *TableHeatmap      `json:"heatmap,omitempty"`
+	Message          string             `json:"message"`
+	NotFound         bool               `json:"-"`
+}
+
+// GetTableAnalytics aggregates the sessions recorded by the occupancy endpoints into
+// per-table utilization for the latest version of a floor plan, counting groups still
+// seated up to now. Tables the latest version no longer has are left out
+func (os *OptimizationService) GetTableAnalytics(req TableAnalyticsRequest) TableAnalyticsResponse {
+	if req.FloorPlanID == "" {
+		return TableAnalyticsResponse{Success: false, Message: "floor_plan_id is required"}
+	}
+
+	now := time.Now()
+	to := now
+	var err error
+	if req.To != "" {
+		if to, err = time.Parse(time.RFC3339, req.To); err != nil {
+			return TableAnalyticsResponse{Success: false, Message: "to must be an RFC 3339 timestamp"}
+		}
+	}
+	from := to.AddDate(0, 0, -defaultAnalyticsDays)
+	if req.From != "" {
+		if from, err = time.Parse(time.RFC3339, req.From); err != nil {
+			return TableAnalyticsResponse{Success: false, Message: "from must be an RFC 3339 timestamp"}
+		}
+	}
+	if !from.Before(to) || to.Sub(from) > maxAnalyticsDays*24*time.Hour {
+		return TableAnalyticsResponse{Success: false, Message: fmt.Sprintf("from must be before to and at most %d days earlier", maxAnalyticsDays)}
+	}
+
+	plan, err := os.resolveFloorPlan(req.FloorPlanID, FloorPlanQuery{})
+	if err != nil {
+		response := floorPlanErrorResponse(req.FloorPlanID, err)
+		return TableAnalyticsResponse{Success: false, FloorPlanID: req.FloorPlanID, Message: response.Message, NotFound: response.NotFound}
+	}
+	sessions, err := os.occupancyRepo.ListTableSessions(req.FloorPlanID, from, to)
+	if err != nil {
+		return TableAnalyticsResponse{Success: false, FloorPlanID: req.FloorPlanID, Message: fmt.Sprintf("failed to read table sessions: %v", err)}
+	}
+	occupancy, err := os.occupancyByTable(req.FloorPlanID)
+	if err != nil {
+		return TableAnalyticsResponse{Success: false, FloorPlanID: req.FloorPlanID, Message: fmt.Sprintf("failed to read occupancy: %v", err)}
+	}
+	for _, table := range occupancy {
+		if table.Occupied && table.SeatedAt.Before(to) && now.After(from) {
+			sessions = append(sessions, &models.TableSession{
+				FloorPlanID: table.FloorPlanID,
+				TableID:     table.TableID,
+				GroupID:     table.GroupID,
+				Size:        table.Size,
+				SeatedAt:    table.SeatedAt,
+				ReleasedAt:  now,
+			})
+		}
+	}
+
+	period := to.Sub(from).Minutes()
+	tables := make([]TableUtilization, len(plan.Tables))
+	fill := make([]float64, len(plan.Tables))
+	counted := 0
+	for i, table := range plan.Tables {
+		tables[i] = TableUtilization{TableID: table.ID, Capacity: table.Capacity, Zone: table.Zone, X: table.X, Y: table.Y}
+	}
+	for _, session := range sessions {
+		i := plan.TableIndex(session.TableID)
+		if i < 0 {
+			continue
+		}
+		start, end := session.SeatedAt, session.ReleasedAt
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		stay := end.Sub(start).Minutes()
+		if stay <= 0 {
+			continue
+		}
+
+		counted++
+		tables[i].Sessions++
+		tables[i].Covers += session.Size
+		tables[i].OccupiedMinutes += stay
+		tables[i].SeatUtilization += float64(session.Size) * stay
+		fill[i] += math.Min(1, float64(session.Size)/float64(tables[i].Capacity))
+	}
+
+	occupiedMinutes, seatMinutes, seats := 0.0, 0.0, 0
+	for i := range tables {
+		table := &tables[i]
+		occupiedMinutes += table.OccupiedMinutes
+		seatMinutes += table.SeatUtilization
+		seats += table.Capacity
+
+		table.Utilization = table.OccupiedMinutes / period
+		table.SeatUtilization /= float64(table.Capacity) * period
+		if table.Sessions > 0 {
+			table.AverageFill = fill[i] / float64(table.Sessions)
+			table.AverageStayMinutes = table.OccupiedMinutes / float64(table.Sessions)
+		}
+	}
+
+	return TableAnalyticsResponse{
+		Success:          true,
+		FloorPlanID:      plan.ID,
+		FloorPlanVersion: plan.Version,
+		From:             from,
+		To:               to,
+		Tables:           tables,
+		Utilization:      occupiedMinutes / (float64(len(tables)) * period),
+		SeatUtilization:  seatMinutes / (float64(seats) * period),
+		Heatmap:          utilizationHeatmap(tables),
+		Message:          fmt.Sprintf("%d sessions across %d tables", counted, len(tables)),
+	}
+}
+
+// utilizationHeatmap places each table's utilization at its coordinates
+func utilizationHeatmap(tables []TableUtilization) *TableHeatmap {
+	heatmap := &TableHeatmap{Metric: "utilization", Points: make([]HeatmapPoint, len(tables))}
+	for i, table := range tables {
+		heatmap.Points[i] = HeatmapPoint{TableID: table.TableID, X: table.X, Y: table.Y, Value: table.Utilization}
+		if i == 0 || table.X < heatmap.MinX {
+			heatmap.MinX = table.X
+		}
+		if i == 0 || table.Y < heatmap.MinY {
+			heatmap.MinY = table.Y
+		}
+		if i == 0 || table.X > heatmap.MaxX {
+			heatmap.MaxX = table.X
+		}
+		if i == 0 || table.Y > heatmap.MaxY {
+			heatmap.MaxY = table.Y
+		}
+	}
+	return heatmap
+}
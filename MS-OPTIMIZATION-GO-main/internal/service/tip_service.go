@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// maxTipStaff limits the number of staff members in one tip pool
+const maxTipStaff = 200
+
+// SplitTipsRequest represents a request to distribute a tip pool among staff
+type SplitTipsRequest struct {
+	Pool      float64 `json:"pool"`
+	PoolCents *int64  `json:"pool_cents,omitempty"` // exact pool in minor units, overrides pool
+	Currency  string  `json:"currency,omitempty"`
+	Locale    string  `json:"locale,omitempty"` // e.g. "es-CO"; defaults to the currency's
+
+	Staff []TipStaff `json:"staff"`
+
+	// Optional role -> weight multiplier on hours (e.g. "bartender": 1.2); unlisted roles weigh 1
+	RoleWeights map[string]float64 `json:"role_weights,omitempty"`
+
+	// Part of the pool (0..1) split by sales instead of weighted hours, default 0
+	SalesFactor float64 `json:"sales_factor,omitempty"`
+}
+
+// TipStaff describes one staff member taking part in a tip pool
+type TipStaff struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name,omitempty"`
+	Role  string  `json:"role,omitempty"`
+	Hours float64 `json:"hours"`
+	Sales float64 `json:"sales,omitempty"` // in major units
+}
+
+// TipAllocationResponse represents the part of the pool assigned to one staff member
+type TipAllocationResponse struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name,omitempty"`
+	Role        string  `json:"role,omitempty"`
+	Hours       float64 `json:"hours"`
+	Sales       float64 `json:"sales"`
+	Share       float64 `json:"share"`
+	Amount      float64 `json:"amount"`
+	AmountCents int     `json:"amount_cents"`
+}
+
+// SplitTipsResponse represents the distribution of a tip pool
+type SplitTipsResponse struct {
+	Success     bool                    `json:"success"`
+	Currency    string                  `json:"currency"`
+	Pool        float64                 `json:"pool"`
+	Allocations []TipAllocationResponse `json:"allocations"`
+	Message     string                  `json:"message"`
+
+	Invalid bool `json:"-"`
+}
+
+// SplitTips distributes a tip pool by weighted hours and sales so the allocations sum exactly to the pool
+func (os *OptimizationService) SplitTips(req SplitTipsRequest) SplitTipsResponse {
+	currency, ok := os.lookupCurrency(req.Currency)
+	if !ok {
+		return SplitTipsResponse{Success: false, Currency: currency.Code, Message: os.unsupportedCurrency(currency.Code), Invalid: true}
+	}
+	currency, err := currency.withLocale(req.Locale)
+	if err != nil {
+		return SplitTipsResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+
+	pool, err := currency.amountToMinorUnits(req.Pool, req.PoolCents)
+	if err != nil {
+		return SplitTipsResponse{Success: false, Currency: currency.Code, Message: fmt.Sprintf("invalid pool: %v", err)}
+	}
+	if pool <= 0 {
+		return SplitTipsResponse{Success: false, Currency: currency.Code, Message: "pool must be positive"}
+	}
+
+	shares, err := currency.tipShares(req)
+	if err != nil {
+		return SplitTipsResponse{Success: false, Currency: currency.Code, Message: err.Error()}
+	}
+
+	result := os.tipAlgo.SplitTips(pool, shares, req.SalesFactor)
+	if !result.Success {
+		return SplitTipsResponse{
+			Success:  false,
+			Currency: currency.Code,
+			Pool:     currency.fromMinorUnits(pool),
+			Message:  result.Message,
+		}
+	}
+
+	allocations := make([]TipAllocationResponse, len(result.Allocations))
+	for i, allocation := range result.Allocations {
+		member := req.Staff[i]
+		allocations[i] = TipAllocationResponse{
+			ID:          member.ID,
+			Name:        member.Name,
+			Role:        member.Role,
+			Hours:       member.Hours,
+			Sales:       currency.fromMinorUnits(shares[i].Sales),
+			Share:       allocation.Share,
+			Amount:      currency.fromMinorUnits(allocation.Amount),
+			AmountCents: allocation.Amount,
+		}
+	}
+
+	return SplitTipsResponse{
+		Success:     true,
+		Currency:    currency.Code,
+		Pool:        currency.fromMinorUnits(pool),
+		Allocations: allocations,
+		Message: fmt.Sprintf("Distributed %s among %d staff members",
+			currency.formatAmount(result.Distributed), len(allocations)),
+	}
+}
+
+// tipShares validates the staff of a tip pool and converts them to algorithm input
+func (c Currency) tipShares(req SplitTipsRequest) ([]algorithms.TipShare, error) {
+	if len(req.Staff) == 0 || len(req.Staff) > maxTipStaff {
+		return nil, fmt.Errorf("between 1 and %d staff members are required", maxTipStaff)
+	}
+	if req.SalesFactor < 0 || req.SalesFactor > 1 || math.IsNaN(req.SalesFactor) {
+		return nil, fmt.Errorf("sales_factor must be between 0 and 1")
+	}
+	for role, weight := range req.RoleWeights {
+		if weight <= 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weight for role %q must be a positive number", role)
+		}
+	}
+
+	seen := make(map[string]bool, len(req.Staff))
+	shares := make([]algorithms.TipShare, len(req.Staff))
+	for i, member := range req.Staff {
+		if member.ID == "" {
+			return nil, fmt.Errorf("staff member %d has no id", i)
+		}
+		if seen[member.ID] {
+			return nil, fmt.Errorf("duplicate staff id %q", member.ID)
+		}
+		seen[member.ID] = true
+
+		if member.Hours < 0 || math.IsNaN(member.Hours) || math.IsInf(member.Hours, 0) {
+			return nil, fmt.Errorf("hours for %s must be a non-negative number", member.ID)
+		}
+		sales, err := c.toMinorUnits(member.Sales)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sales for %s: %w", member.ID, err)
+		}
+		if sales < 0 {
+			return nil, fmt.Errorf("sales for %s must be non-negative", member.ID)
+		}
+
+		roleWeight := 1.0
+		if weight, ok := req.RoleWeights[member.Role]; ok {
+			roleWeight = weight
+		}
+
+		shares[i] = algorithms.TipShare{
+			ID:         member.ID,
+			Hours:      member.Hours,
+			RoleWeight: roleWeight,
+			Sales:      sales,
+		}
+	}
+
+	return shares, nil
+}
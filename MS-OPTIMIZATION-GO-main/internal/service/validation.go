@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxStoredIDLength bounds ids kept in the database, whose columns are VARCHAR(100)
+const maxStoredIDLength = 100
+
+// ItemError describes one invalid field of one item in a request list
+type ItemError struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// itemErrors collects the validation errors of a list of items
+type itemErrors []ItemError
+
+// add records an invalid field of the item at index
+func (e *itemErrors) add(index int, id, field, reason string) {
+	*e = append(*e, ItemError{Index: index, ID: id, Field: field, Reason: reason})
+}
+
+// checkID requires a non-empty id not used by an earlier item
+func (e *itemErrors) checkID(index int, id string, seen map[string]bool) {
+	switch {
+	case id == "":
+		e.add(index, id, "id", "is required")
+	case seen[id]:
+		e.add(index, id, "id", "duplicates an earlier item")
+	}
+	seen[id] = true
+}
+
+// checkPositive requires a finite number greater than zero
+func (e *itemErrors) checkPositive(index int, id, field string, value float64) {
+	if !(value > 0) || math.IsInf(value, 0) {
+		e.add(index, id, field, "must be a positive number")
+	}
+}
+
+// checkNonNegative requires a finite number of at least zero
+func (e *itemErrors) checkNonNegative(index int, id, field string, value float64) {
+	if !(value >= 0) || math.IsInf(value, 0) {
+		e.add(index, id, field, "must be a non-negative number")
+	}
+}
+
+// invalidItemsMessage summarizes a list of validation errors
+func invalidItemsMessage(errs []ItemError) string {
+	if len(errs) == 1 {
+		return fmt.Sprintf("item %d: %s %s", errs[0].Index, errs[0].Field, errs[0].Reason)
+	}
+	return fmt.Sprintf("%d invalid fields, see errors", len(errs))
+}
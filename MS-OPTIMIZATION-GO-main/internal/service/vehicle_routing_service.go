@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+)
+
+// Vehicle routing limits
+const (
+	maxRouteVehicles  = 50
+	maxShiftMinutes   = 24 * 60
+	maxServiceMinutes = 240
+	maxDeliveryDemand = 1e6
+	maxAverageSpeed   = 1000
+)
+
+// VehicleRoutingRequest represents orders to deliver from the restaurant with several drivers.
+// Distances come from the coordinates, or from distance_matrix, indexed with the depot first
+// and then the stops in order, when it is given
+type VehicleRoutingRequest struct {
+	Depot          RouteStop      `json:"depot"`
+	Stops          []DeliveryStop `json:"stops"`
+	Vehicles       []Vehicle      `json:"vehicles"`
+	DistanceMatrix [][]float64    `json:"distance_matrix,omitempty"`
+	Metric         string         `json:"metric,omitempty"`        // euclidean (default) or haversine
+	AverageSpeed   float64        `json:"average_speed,omitempty"` // distance per hour, required with shift limits
+}
+
+// DeliveryStop describes an order to deliver
+type DeliveryStop struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name,omitempty"`
+	X              float64 `json:"x,omitempty"`
+	Y              float64 `json:"y,omitempty"`
+	Demand         float64 `json:"demand"`                    // e.g. bags or boxes, in the unit of the vehicles' capacity
+	ServiceMinutes float64 `json:"service_minutes,omitempty"` // time spent handing the order over
+}
+
+// Vehicle describes a driver and what they can carry
+type Vehicle struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name,omitempty"`
+	Capacity     float64 `json:"capacity"`
+	ShiftMinutes float64 `json:"shift_minutes,omitempty"` // longest round trip they can make, 0 for no limit
+}
+
+// VehicleRoute represents one vehicle's itinerary, leaving from and returning to the depot
+type VehicleRoute struct {
+	VehicleID    string     `json:"vehicle_id"`
+	Name         string     `json:"name,omitempty"`
+	Stops        []string   `json:"stops"`
+	Legs         []RouteLeg `json:"legs"`
+	Load         float64    `json:"load"`
+	Capacity     float64    `json:"capacity"`
+	LoadFactor   float64    `json:"load_factor"` // load over capacity
+	Distance     float64    `json:"distance"`
+	Minutes      float64    `json:"minutes,omitempty"` // driving and service time, when an average speed is given
+	ShiftMinutes float64    `json:"shift_minutes,omitempty"`
+}
+
+// VehicleRoutingResponse represents the split of the orders into routes
+type VehicleRoutingResponse struct {
+	Success       bool           `json:"success"`
+	Routes        []VehicleRoute `json:"routes"`
+	IdleVehicles  []string       `json:"idle_vehicles,omitempty"`
+	UnservedStops []string       `json:"unserved_stops,omitempty"`
+	TotalDistance float64        `json:"total_distance"`
+	LoadFactor    float64        `json:"load_factor"` // load over the capacity of the vehicles used
+	Message       string         `json:"message"`
+}
+
+// OptimizeVehicleRoutes splits delivery orders among vehicles with the Clarke–Wright savings
+// heuristic and 2-opt, within each vehicle's capacity and shift
+func (os *OptimizationService) OptimizeVehicleRoutes(ctx context.Context, req VehicleRoutingRequest) VehicleRoutingResponse {
+	if len(req.Stops) == 0 || len(req.Stops) >= maxRouteStops {
+		return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d stops are required", maxRouteStops-1)}
+	}
+	if len(req.Vehicles) == 0 || len(req.Vehicles) > maxRouteVehicles {
+		return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("between 1 and %d vehicles are required", maxRouteVehicles)}
+	}
+	if !(req.AverageSpeed >= 0) || req.AverageSpeed > maxAverageSpeed {
+		return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("average_speed must be between 0 and %d", maxAverageSpeed)}
+	}
+	if req.Depot.ID == "" {
+		return VehicleRoutingResponse{Success: false, Message: "depot has no id"}
+	}
+	if req.Depot.EarliestAt != nil || req.Depot.LatestAt != nil {
+		return VehicleRoutingResponse{Success: false, Message: "depot cannot have a time window"}
+	}
+
+	// The depot goes first, so a stop's index in the distances is one past its own
+	points := make([]RouteStop, 0, len(req.Stops)+1)
+	points = append(points, req.Depot)
+	demand := make([]float64, 1, len(req.Stops)+1)
+	service := make([]float64, 1, len(req.Stops)+1)
+	for _, stop := range req.Stops {
+		if stop.ID == req.Depot.ID {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("stop %s has the id of the depot", stop.ID)}
+		}
+		if !(stop.Demand >= 0) || stop.Demand > maxDeliveryDemand {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("demand of stop %s must be between 0 and %g", stop.ID, maxDeliveryDemand)}
+		}
+		if !(stop.ServiceMinutes >= 0) || stop.ServiceMinutes > maxServiceMinutes {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("service_minutes of stop %s must be between 0 and %d", stop.ID, maxServiceMinutes)}
+		}
+		points = append(points, RouteStop{ID: stop.ID, Name: stop.Name, X: stop.X, Y: stop.Y})
+		demand = append(demand, stop.Demand)
+		service = append(service, stop.ServiceMinutes)
+	}
+	dist, err := routeDistances(points, req.DistanceMatrix, req.Metric)
+	if err != nil {
+		return VehicleRoutingResponse{Success: false, Message: err.Error()}
+	}
+
+	seen := make(map[string]bool, len(req.Vehicles))
+	vehicles := make([]algorithms.VRPVehicle, len(req.Vehicles))
+	for i, vehicle := range req.Vehicles {
+		if vehicle.ID == "" {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("vehicle %d has no id", i)}
+		}
+		if seen[vehicle.ID] {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("duplicate vehicle id %q", vehicle.ID)}
+		}
+		seen[vehicle.ID] = true
+		if !(vehicle.Capacity > 0) || math.IsInf(vehicle.Capacity, 0) {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("capacity of vehicle %s must be a positive number", vehicle.ID)}
+		}
+		if !(vehicle.ShiftMinutes >= 0) || vehicle.ShiftMinutes > maxShiftMinutes {
+			return VehicleRoutingResponse{Success: false, Message: fmt.Sprintf("shift_minutes of vehicle %s must be between 0 and %d", vehicle.ID, maxShiftMinutes)}
+		}
+		if vehicle.ShiftMinutes > 0 && req.AverageSpeed == 0 {
+			return VehicleRoutingResponse{Success: false, Message: "average_speed is required when vehicles have shift_minutes"}
+		}
+		vehicles[i] = algorithms.VRPVehicle{Capacity: vehicle.Capacity, MaxMinutes: vehicle.ShiftMinutes}
+	}
+
+	solution := algorithms.SolveVRP(ctx, algorithms.VRPInput{
+		Dist:     dist,
+		Demand:   demand,
+		Service:  service,
+		Speed:    req.AverageSpeed / 60,
+		Vehicles: vehicles,
+	})
+	if !solution.Success {
+		return VehicleRoutingResponse{Success: false, Message: solution.Message}
+	}
+
+	routes := make([]VehicleRoute, 0, len(solution.Routes))
+	busy := make([]bool, len(req.Vehicles))
+	load, capacity := 0.0, 0.0
+	for _, r := range solution.Routes {
+		vehicle := req.Vehicles[r.Vehicle]
+		busy[r.Vehicle] = true
+		order := append(append([]int{0}, r.Stops...), 0)
+		route := VehicleRoute{
+			VehicleID:    vehicle.ID,
+			Name:         vehicle.Name,
+			Stops:        stopIDs(points, r.Stops),
+			Legs:         routeLegs(points, dist, order),
+			Load:         r.Load,
+			Capacity:     vehicle.Capacity,
+			LoadFactor:   r.Load / vehicle.Capacity,
+			Distance:     r.Distance,
+			ShiftMinutes: vehicle.ShiftMinutes,
+		}
+		if req.AverageSpeed > 0 {
+			route.Minutes = r.Minutes
+		}
+		routes = append(routes, route)
+		load += r.Load
+		capacity += vehicle.Capacity
+	}
+	var idle []string
+	for v, vehicle := range req.Vehicles {
+		if !busy[v] {
+			idle = append(idle, vehicle.ID)
+		}
+	}
+	loadFactor := 0.0
+	if capacity > 0 {
+		loadFactor = load / capacity
+	}
+
+	return VehicleRoutingResponse{
+		Success:       true,
+		Routes:        routes,
+		IdleVehicles:  idle,
+		UnservedStops: stopIDs(points, solution.Unserved),
+		TotalDistance: solution.Distance,
+		LoadFactor:    loadFactor,
+		Message:       solution.Message,
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import "math"
+
+// WaitlistWeights describes how much each measure counts when picking among the alternative
+// plans. Each measure is scaled between the worst and best alternative before weighting
+type WaitlistWeights struct {
+	Revenue     float64 `json:"revenue,omitempty"`
+	Wait        float64 `json:"wait,omitempty"`
+	Utilization float64 `json:"utilization,omitempty"`
+}
+
+// WaitlistAlternative represents one plan on the Pareto front of a waitlist
+type WaitlistAlternative struct {
+	Objective          string          `json:"objective"`
+	FairnessMinutes    *float64        `json:"fairness_minutes,omitempty"` // none when the fairness rule is off
+	ProjectedRevenue   float64         `json:"projected_revenue"`
+	AverageWaitMinutes float64         `json:"average_wait_minutes"`
+	SeatUtilization    float64         `json:"seat_utilization"`
+	Score              *float64        `json:"score,omitempty"`  // with weights, between 0 and the sum of the weights
+	Chosen             bool            `json:"chosen,omitempty"` // returned as the plan
+	Queue              []WaitlistEntry `json:"queue"`
+}
+
+// planWaitlistAlternatives plans a waitlist under each objective, with the requested fairness
+// and in arrival order, and keeps the plans not dominated on revenue, wait and utilization
+func (os *OptimizationService) planWaitlistAlternatives(req WaitlistRequest) WaitlistResponse {
+	if req.Method == WaitlistMethodAnneal {
+		return WaitlistResponse{Success: false, Message: "alternatives cannot be combined with the anneal method"}
+	}
+	if weights := req.Weights; weights != nil {
+		if !(weights.Revenue >= 0) || !(weights.Wait >= 0) || !(weights.Utilization >= 0) ||
+			math.IsInf(weights.Revenue+weights.Wait+weights.Utilization, 0) {
+			return WaitlistResponse{Success: false, Message: "weights must be non-negative numbers"}
+		}
+		if weights.Revenue+weights.Wait+weights.Utilization == 0 {
+			return WaitlistResponse{Success: false, Message: "at least one weight must be positive"}
+		}
+	}
+
+	inOrder := 0.0
+	fairness := []*float64{req.FairnessMinutes}
+	if req.FairnessMinutes == nil || *req.FairnessMinutes != 0 {
+		fairness = append(fairness, &inOrder)
+	}
+	var alternatives []WaitlistAlternative
+	var plans []WaitlistResponse
+	for _, objective := range []string{WaitlistObjectiveFit, WaitlistObjectiveRevenue} {
+		for _, fairnessMinutes := range fairness {
+			variant := req
+			variant.Alternatives, variant.Weights = false, nil
+			variant.Objective, variant.FairnessMinutes = objective, fairnessMinutes
+			if fairnessMinutes != nil && variant.Method == WaitlistMethodFlow {
+				variant.Method = WaitlistMethodGreedy
+			}
+			plan := os.PlanWaitlist(variant)
+			if !plan.Success {
+				return plan
+			}
+			plans = append(plans, plan)
+			alternatives = append(alternatives, WaitlistAlternative{
+				Objective:          objective,
+				FairnessMinutes:    fairnessMinutes,
+				ProjectedRevenue:   plan.ProjectedRevenue,
+				AverageWaitMinutes: plan.AverageWaitMinutes,
+				SeatUtilization:    plan.SeatUtilization,
+				Queue:              plan.Queue,
+			})
+		}
+	}
+
+	// The front keeps each alternative no other matches or beats on every measure while
+	// beating it on one, and the first of alternatives that tie on all of them
+	var front []int
+	for i, a := range alternatives {
+		dominated := false
+		for j, b := range alternatives {
+			atLeast := b.ProjectedRevenue >= a.ProjectedRevenue && b.AverageWaitMinutes <= a.AverageWaitMinutes && b.SeatUtilization >= a.SeatUtilization
+			better := b.ProjectedRevenue > a.ProjectedRevenue || b.AverageWaitMinutes < a.AverageWaitMinutes || b.SeatUtilization > a.SeatUtilization
+			if j != i && atLeast && (better || j < i) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, i)
+		}
+	}
+
+	// Without weights the plan is the one the request asked for, the first alternative
+	chosen := 0
+	if req.Weights != nil {
+		chosen = front[0]
+		scale := func(value, worst, best float64) float64 {
+			if best == worst {
+				return 1
+			}
+			return (value - worst) / (best - worst)
+		}
+		lowRevenue, highRevenue := math.Inf(1), math.Inf(-1)
+		lowWait, highWait := math.Inf(1), math.Inf(-1)
+		lowUse, highUse := math.Inf(1), math.Inf(-1)
+		for _, i := range front {
+			a := alternatives[i]
+			lowRevenue, highRevenue = math.Min(lowRevenue, a.ProjectedRevenue), math.Max(highRevenue, a.ProjectedRevenue)
+			lowWait, highWait = math.Min(lowWait, a.AverageWaitMinutes), math.Max(highWait, a.AverageWaitMinutes)
+			lowUse, highUse = math.Min(lowUse, a.SeatUtilization), math.Max(highUse, a.SeatUtilization)
+		}
+		best := -1.0
+		for _, i := range front {
+			a := &alternatives[i]
+			score := req.Weights.Revenue*scale(a.ProjectedRevenue, lowRevenue, highRevenue) +
+				req.Weights.Wait*scale(a.AverageWaitMinutes, highWait, lowWait) +
+				req.Weights.Utilization*scale(a.SeatUtilization, lowUse, highUse)
+			a.Score = &score
+			if score > best {
+				chosen, best = i, score
+			}
+		}
+	}
+
+	response := plans[chosen]
+	response.Alternatives = make([]WaitlistAlternative, 0, len(front))
+	for _, i := range front {
+		alternatives[i].Chosen = i == chosen
+		response.Alternatives = append(response.Alternatives, alternatives[i])
+	}
+	return response
+}
@@ -0,0 +1,1138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"ms-optimization-go/internal/algorithms"
+	"ms-optimization-go/internal/models"
+	"sort"
+	"time"
+)
+
+// Waitlist objectives
+const (
+	WaitlistObjectiveFit     = "fit"
+	WaitlistObjectiveRevenue = "revenue"
+)
+
+// Waitlist planning methods
+const (
+	WaitlistMethodGreedy = "greedy"
+	WaitlistMethodAnneal = "anneal"
+	WaitlistMethodFlow   = "flow"
+)
+
+// Group types, each with its own seating policy: reservations and events have their table
+// set aside from their booked time, reservations held through a grace window if late and
+// events able to use held tables; walk-ins fill the gaps between bookings
+const (
+	GroupTypeWalkIn      = "walk_in"
+	GroupTypeReservation = "reservation"
+	GroupTypeEvent       = "event"
+)
+
+// Reasons a group gets no table, in unseatable_reasons and overflow entries: no table is
+// large enough, every table that is is ruled out by a hard constraint or the group's need
+// for wheelchair access, or a table could take the group but not within
+// overflow_after_minutes
+const (
+	NoTableLargeEnough = "no_table_large_enough"
+	NoTableAllowed     = "no_table_allowed"
+	NoTableSoonEnough  = "no_table_soon_enough"
+)
+
+// Waitlist limits and defaults
+const (
+	maxWaitlistTables   = 500
+	maxWaitlistGroups   = 200
+	maxTableCapacity    = 100
+	maxOverflowPools    = 20
+	maxPoolCapacity     = 500
+	maxTablePairs       = 200
+	defaultAnnealMillis = 1000
+	maxAnnealMillis     = 10000
+	maxAnnealSteps      = 20000
+	defaultTurnMinutes  = 90
+	defaultGraceMinutes = 15
+)
+
+// WaitlistRequest represents the current state of the floor and the waitlist. It is
+// meant to be sent again whenever a table frees up or a group joins or leaves
+type WaitlistRequest struct {
+	Tables      []WaitlistTable `json:"tables,omitempty"`
+	Groups      []WaitlistGroup `json:"groups"`                 // in arrival order
+	TurnMinutes float64         `json:"turn_minutes,omitempty"` // how long a seated group keeps its table, default 90
+	StartTime   *time.Time      `json:"start_time,omitempty"`   // the time waits are counted from, default now
+
+	// Turn times by group size: each entry applies from its size up to the next entry's,
+	// e.g. {"1": 60, "5": 120}. Groups smaller than every entry use turn_minutes
+	TurnMinutesBySize map[int]float64 `json:"turn_minutes_by_size,omitempty"`
+
+	// Minimum group priority allowed at a table before its hold ends, default 1
+	HoldPriority int `json:"hold_priority,omitempty"`
+
+	// When set, no group is seated before a group of the same or higher priority that
+	// arrived more than this many minutes earlier; 0 seats each priority level in arrival order
+	FairnessMinutes *float64 `json:"fairness_minutes,omitempty"`
+
+	// Overflow areas without tables, tried in order, for groups that would wait longer than
+	// overflow_after_minutes for a table and for groups larger than every table
+	Pools []WaitlistPool `json:"pools,omitempty"`
+
+	// How long a group waits for a table before taking a pool spot, default 0: as soon as
+	// no table is free on arrival
+	OverflowAfterMinutes float64 `json:"overflow_after_minutes,omitempty"`
+
+	// How long a reservation's table stays held past its time if the group is late, default 15
+	ReservationGraceMinutes *float64 `json:"reservation_grace_minutes,omitempty"`
+
+	// fit (default) gives each free table to the best-fitting group; revenue gives it to the
+	// group with the highest expected_spend per seat hour
+	Objective string `json:"objective,omitempty"`
+
+	// Highest accepted chance, below 1, that two reservations or events booked on the same
+	// table at overlapping times both show up, judged from their no_show_probability.
+	// 0 (default) never overbooks a table
+	OverbookingRisk float64 `json:"overbooking_risk,omitempty"`
+
+	// Hard and soft constraints on the tables groups may take. A soft constraint's penalty
+	// is the minutes of extra wait worth spending to keep it
+	Constraints []AssignmentConstraint `json:"constraints,omitempty"`
+
+	// Minutes of extra wait worth spending to seat a group in a server section that the plan
+	// has given one cover fewer, so new tables spread over the servers instead of piling up
+	// in one section. 0 (default) ignores sections
+	SectionBalanceMinutes float64 `json:"section_balance_minutes,omitempty"`
+
+	// Minutes of extra wait worth spending to keep a group at the table_id an earlier plan
+	// gave it, so the waitlist can be planned again through service without reshuffling the
+	// groups already told where they will sit. Without it, those groups keep their table
+	ReassignmentMinutes *float64 `json:"reassignment_minutes,omitempty"`
+
+	// greedy (default) plans in one pass; anneal starts from the greedy plan and searches for
+	// one seating more groups at tables, then with less total wait and penalty. flow first
+	// seats the walk-ins here now at the tables free now by min-cost max-flow, as many as
+	// possible and then the best fitting, before planning the rest greedily; it cannot be
+	// combined with fairness_minutes
+	Method string `json:"method,omitempty"`
+
+	// Anneal only: how long the search may run, default 1000, at most 10000. Without steps
+	// it stops after this or 20000 steps, whichever comes first, so the plan depends on the
+	// machine's speed
+	TimeBudgetMs int `json:"time_budget_ms,omitempty"`
+
+	// Anneal only: run exactly this many steps, at most 20000, so the same seed and steps
+	// give the same plan. annealing.steps falls short only if the 10000 ms limit stops the run
+	Steps int `json:"steps,omitempty"`
+
+	// Anneal only: seed of the search's random moves
+	Seed int64 `json:"seed,omitempty"`
+
+	// Plans the waitlist for revenue and for fit, each with the requested fairness and seating
+	// in arrival order, and lists the plans no other beats on projected revenue, average wait
+	// and seat utilization all at once. Not allowed with method anneal
+	Alternatives bool `json:"alternatives,omitempty"`
+
+	// Requires alternatives: the alternative scoring best is returned as the plan instead of
+	// the one the request asks for
+	Weights *WaitlistWeights `json:"weights,omitempty"`
+
+	// Let a group larger than every table it may take sit at two tables pushed together.
+	// Merges are tried before splits, and both are passed over when a single table can take
+	// the group
+	Merge *WaitlistMerge `json:"merge,omitempty"`
+
+	// Let a group larger than every table it may take split over two tables close enough
+	// together
+	Split *WaitlistSplit `json:"split,omitempty"`
+
+	// A stored floor plan to use instead of tables
+	FloorPlanID string `json:"floor_plan_id,omitempty"`
+
+	// Requires floor_plan_id: the version to use, default the latest one used on day
+	FloorPlanVersion int `json:"floor_plan_version,omitempty"`
+
+	// Requires floor_plan_id: the day whose latest version is used, default that of start_time
+	Day string `json:"day,omitempty"`
+
+	// Requires floor_plan_id: minutes until each occupied table is free. Tables not listed
+	// are free now
+	Availability map[string]float64 `json:"availability,omitempty"`
+
+	// Requires floor_plan_id: takes availability from the tables seated through the
+	// occupancy endpoints instead
+	LiveOccupancy bool `json:"live_occupancy,omitempty"`
+
+	// Requires floor_plan_id: the hold_minutes of held tables
+	Holds map[string]float64 `json:"holds,omitempty"`
+}
+
+// WaitlistTable describes a table and when it is expected to be free
+type WaitlistTable struct {
+	ID                 string     `json:"id"`
+	Capacity           int        `json:"capacity"`
+	AvailableInMinutes float64    `json:"available_in_minutes,omitempty"` // 0 if free now
+	AvailableAt        *time.Time `json:"available_at,omitempty"`         // expected departure of the seated group, instead of available_in_minutes
+	HoldMinutes        float64    `json:"hold_minutes,omitempty"`         // kept for priority groups (VIPs, walk-in buffer) this long after the start time
+	Zone               string     `json:"zone,omitempty"`                 // for constraints, taken from the floor plan when floor_plan_id is given
+	Attributes         []string   `json:"attributes,omitempty"`           // for constraints, as zone
+	Section            string     `json:"section,omitempty"`              // server section, as zone
+
+	WheelchairAccessible bool     `json:"wheelchair_accessible,omitempty"` // as zone
+	Adjacent             []string `json:"adjacent,omitempty"`              // tables that can be pushed together with this one, as zone
+	X                    float64  `json:"x,omitempty"`
+	Y                    float64  `json:"y,omitempty"`
+}
+
+// WaitlistMerge describes which pairs of tables may be pushed together into one: those
+// listing each other as adjacent, and with max_distance those whose centres are at most
+// that far apart. Each join loses seats_lost_per_join seats, e.g. at the ends where the
+// tables meet. penalty_minutes is the extra wait worth spending to avoid moving tables
+type WaitlistMerge struct {
+	MaxDistance      float64 `json:"max_distance,omitempty"`
+	SeatsLostPerJoin int     `json:"seats_lost_per_join,omitempty"`
+	PenaltyMinutes   float64 `json:"penalty_minutes,omitempty"`
+}
+
+// WaitlistSplit describes which pairs of tables a group may be split over: those whose
+// centres are at most max_distance apart, in the units of the table coordinates.
+// penalty_minutes is the extra wait worth spending to keep a group together
+type WaitlistSplit struct {
+	MaxDistance    float64 `json:"max_distance"`
+	PenaltyMinutes float64 `json:"penalty_minutes,omitempty"`
+}
+
+// WaitlistPool describes an overflow area such as bar seats or a standing area
+type WaitlistPool struct {
+	ID          string  `json:"id"`
+	Capacity    int     `json:"capacity"`               // people it holds at once
+	TurnMinutes float64 `json:"turn_minutes,omitempty"` // how long a group stays, default the group's table turn
+}
+
+// WaitlistGroup describes a waiting party
+type WaitlistGroup struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name,omitempty"`
+	Size           int        `json:"size"`
+	WaitedMinutes  float64    `json:"waited_minutes,omitempty"`  // time already spent waiting
+	ArrivalMinutes float64    `json:"arrival_minutes,omitempty"` // for groups not here yet, minutes after the start time they are expected
+	ArrivalTime    *time.Time `json:"arrival_time,omitempty"`    // when the group arrived or is expected, instead of waited_minutes and arrival_minutes
+	DiningMinutes  float64    `json:"dining_minutes,omitempty"`  // expected time at the table, overrides the turn times
+	Priority       int        `json:"priority,omitempty"`        // groups at or above hold_priority may use held tables
+	ExpectedSpend  float64    `json:"expected_spend,omitempty"`  // projected revenue from the group
+	Type           string     `json:"type,omitempty"`            // walk_in (default), reservation or event; booked groups arrive at their booked time
+	TableID        string     `json:"table_id,omitempty"`        // table an earlier plan gave the group
+
+	// Needs a wheelchair-accessible table and is never seated at another
+	WheelchairAccess bool `json:"wheelchair_access,omitempty"`
+
+	// Chance between 0 and 1 that the group never comes, from the reservations service.
+	// Expected spend and seats are weighted by the chance it shows up
+	NoShowProbability float64 `json:"no_show_probability,omitempty"`
+}
+
+// WaitlistEntry represents the planned seating of one group
+type WaitlistEntry struct {
+	Position             int                   `json:"position"` // 1 for the next group to seat
+	ID                   string                `json:"id"`
+	Name                 string                `json:"name,omitempty"`
+	Size                 int                   `json:"size"`
+	TableID              string                `json:"table_id"`
+	TableIDs             []string              `json:"table_ids,omitempty"`    // every table of a split or merged group, table_id first
+	EstimatedWaitMinutes float64               `json:"estimated_wait_minutes"` // from the start time, or from arrival for groups not here yet
+	TotalWaitMinutes     float64               `json:"total_wait_minutes"`     // including the time already waited
+	SeatAt               time.Time             `json:"seat_at"`
+	DiningMinutes        float64               `json:"dining_minutes"`
+	TableFreeAt          time.Time             `json:"table_free_at"`        // when the group is expected to leave
+	HeldTable            bool                  `json:"held_table,omitempty"` // seated at a table before its hold ends
+	ExpectedSpend        float64               `json:"expected_spend,omitempty"`
+	Type                 string                `json:"type"`
+	HoldUntil            *time.Time            `json:"hold_until,omitempty"` // when a late reservation loses its table
+	NoShowProbability    float64               `json:"no_show_probability,omitempty"`
+	Overbooked           bool                  `json:"overbooked,omitempty"` // the table is also booked for another group at an overlapping time
+	Violations           []ConstraintViolation `json:"violations,omitempty"` // soft constraints the seating breaks
+	Reassigned           bool                  `json:"reassigned,omitempty"` // moved off the table_id an earlier plan gave the group
+	Split                bool                  `json:"split,omitempty"`      // seated over the tables in table_ids
+	Merged               bool                  `json:"merged,omitempty"`     // seated at the tables in table_ids pushed together
+	Explanation          WaitlistExplanation   `json:"explanation"`
+}
+
+// WaitlistExplanation breaks down what the planner weighed when it gave a group its seating:
+// among the earliest seatings, the one whose penalty and section balance minutes add up the
+// lowest, then the highest spend per seat hour with the revenue objective, then the fewest
+// empty seats, then the group that has waited longest
+type WaitlistExplanation struct {
+	EmptySeats            int     `json:"empty_seats"`                   // seats of its table or tables the group leaves empty
+	PenaltyMinutes        float64 `json:"penalty_minutes"`               // of soft constraints, moving off table_id and splitting the group
+	SectionBalanceMinutes float64 `json:"section_balance_minutes"`       // for the covers already planned in the table's section
+	SpendPerSeatHour      float64 `json:"spend_per_seat_hour,omitempty"` // expected spend per seat hour of the table, with the revenue objective
+	Priority              bool    `json:"priority,omitempty"`            // at or above hold_priority, or an event, so held tables were open to it
+}
+
+// OverflowEntry represents a group placed in an overflow area instead of at a table
+type OverflowEntry struct {
+	ID                   string    `json:"id"`
+	Name                 string    `json:"name,omitempty"`
+	Size                 int       `json:"size"`
+	PoolID               string    `json:"pool_id"`
+	Reason               string    `json:"reason"` // no_table_soon_enough, no_table_large_enough or no_table_allowed
+	EstimatedWaitMinutes float64   `json:"estimated_wait_minutes"`
+	TotalWaitMinutes     float64   `json:"total_wait_minutes"`
+	PlacedAt             time.Time `json:"placed_at"`
+	LeaveAt              time.Time `json:"leave_at"`
+}
+
+// WaitlistResponse represents the planned seating order of the waitlist
+type WaitlistResponse struct {
+	Success            bool                     `json:"success"`
+	Queue              []WaitlistEntry          `json:"queue"`
+	Overflow           []OverflowEntry          `json:"overflow,omitempty"`           // groups placed in pools, not counted in the wait statistics
+	Unseatable         []string                 `json:"unseatable,omitempty"`         // groups no table can take, with no room in a pool
+	UnseatableReasons  map[string]string        `json:"unseatable_reasons,omitempty"` // by group: no_table_large_enough or no_table_allowed
+	AverageWaitMinutes float64                  `json:"average_wait_minutes"`
+	MaxWaitMinutes     float64                  `json:"max_wait_minutes"`
+	ProjectedRevenue   float64                  `json:"projected_revenue"`        // expected spend of the groups seated at tables, weighted by their chance of showing up
+	RevenuePerSeatHour float64                  `json:"revenue_per_seat_hour"`    // projected revenue over the table seat hours until the last group leaves
+	SeatUtilization    float64                  `json:"seat_utilization"`         // expected occupied seat time over the table seat time until the last group leaves
+	Penalty            float64                  `json:"penalty,omitempty"`        // total penalty of the soft constraints broken
+	Reassignments      int                      `json:"reassignments,omitempty"`  // groups moved off the table an earlier plan gave them
+	SectionCovers      map[string]int           `json:"section_covers,omitempty"` // covers seated in each server section
+	Annealing          *WaitlistAnnealingReport `json:"annealing,omitempty"`      // with the anneal method
+	Alternatives       []WaitlistAlternative    `json:"alternatives,omitempty"`
+	FloorPlanVersion   int                      `json:"floor_plan_version,omitempty"` // version used when floor_plan_id was given
+	Message            string                   `json:"message"`
+	NotFound           bool                     `json:"-"`
+}
+
+// WaitlistAnnealingReport compares the annealed plan with the greedy plan it started from.
+// Costs are the total minutes the groups seated at tables wait plus their penalties
+type WaitlistAnnealingReport struct {
+	GreedySeated       int     `json:"greedy_seated"`
+	GreedyCostMinutes  float64 `json:"greedy_cost_minutes"`
+	Seated             int     `json:"seated"`
+	CostMinutes        float64 `json:"cost_minutes"`
+	ImprovementMinutes float64 `json:"improvement_minutes"` // greedy cost less the annealed cost when both seat as many groups
+	Steps              int     `json:"steps"`
+}
+
+// PlanWaitlist orders the waitlist and estimates each group's wait from the tables'
+// expected departure times
+func (os *OptimizationService) PlanWaitlist(req WaitlistRequest) WaitlistResponse {
+	start := time.Now()
+	if req.StartTime != nil {
+		start = *req.StartTime
+	}
+	if req.Alternatives {
+		req.StartTime = &start
+		return os.planWaitlistAlternatives(req)
+	}
+	if req.Weights != nil {
+		return WaitlistResponse{Success: false, Message: "weights require alternatives"}
+	}
+
+	floorPlanVersion := 0
+	if req.FloorPlanID != "" {
+		plan, err := os.waitlistFloorPlan(req, start)
+		if err != nil {
+			response := floorPlanErrorResponse(req.FloorPlanID, err)
+			return WaitlistResponse{Success: false, Message: response.Message, NotFound: response.NotFound}
+		}
+		if req.LiveOccupancy {
+			if req.Availability != nil {
+				return WaitlistResponse{Success: false, Message: "give availability or live_occupancy, not both"}
+			}
+			if req.Availability, err = os.liveAvailability(plan, start); err != nil {
+				return WaitlistResponse{Success: false, Message: fmt.Sprintf("failed to read occupancy: %v", err)}
+			}
+		}
+		if req.Tables, err = floorPlanWaitlistTables(plan, req.Availability, req.Holds); err != nil {
+			return WaitlistResponse{Success: false, Message: err.Error()}
+		}
+		floorPlanVersion = plan.Version
+	} else if req.FloorPlanVersion != 0 || req.Day != "" || req.Availability != nil || req.LiveOccupancy || req.Holds != nil {
+		return WaitlistResponse{Success: false, Message: "floor_plan_version, day, availability, live_occupancy and holds require floor_plan_id"}
+	}
+	// The planner gives tied tables to the first one, so order them by ID for the same plan
+	// whatever order the tables come in
+	req.Tables = append([]WaitlistTable(nil), req.Tables...)
+	sort.SliceStable(req.Tables, func(a, b int) bool { return req.Tables[a].ID < req.Tables[b].ID })
+
+	settings, err := waitlistRequestSettings(req)
+	if err != nil {
+		return WaitlistResponse{Success: false, Message: err.Error()}
+	}
+	turnMinutes, fairnessMinutes, objective, method := settings.turnMinutes, settings.fairnessMinutes, settings.objective, settings.method
+	budget, steps := settings.budget, settings.steps
+	if !(req.OverbookingRisk >= 0) || req.OverbookingRisk >= 1 {
+		return WaitlistResponse{Success: false, Message: "overbooking_risk must be at least 0 and below 1"}
+	}
+	if !(req.SectionBalanceMinutes >= 0) || req.SectionBalanceMinutes > 24*60 {
+		return WaitlistResponse{Success: false, Message: "section_balance_minutes must be between 0 and 1440"}
+	}
+
+	if len(req.Pools) > maxOverflowPools {
+		return WaitlistResponse{Success: false, Message: fmt.Sprintf("at most %d pools are allowed", maxOverflowPools)}
+	}
+	if !(req.OverflowAfterMinutes >= 0) || req.OverflowAfterMinutes > 24*60 {
+		return WaitlistResponse{Success: false, Message: "overflow_after_minutes must be between 0 and 1440"}
+	}
+	pools := make([]algorithms.WaitPool, len(req.Pools))
+	seen := make(map[string]bool, len(req.Pools))
+	for i, pool := range req.Pools {
+		if pool.ID == "" {
+			return WaitlistResponse{Success: false, Message: fmt.Sprintf("pool %d has no id", i)}
+		}
+		if seen[pool.ID] {
+			return WaitlistResponse{Success: false, Message: fmt.Sprintf("duplicate pool id %q", pool.ID)}
+		}
+		seen[pool.ID] = true
+		if pool.Capacity < 1 || pool.Capacity > maxPoolCapacity {
+			return WaitlistResponse{Success: false, Message: fmt.Sprintf("capacity of pool %s must be between 1 and %d", pool.ID, maxPoolCapacity)}
+		}
+		if pool.TurnMinutes != 0 && !validTurnMinutes(pool.TurnMinutes) {
+			return WaitlistResponse{Success: false, Message: fmt.Sprintf("turn_minutes of pool %s must be between 1 and 1440", pool.ID)}
+		}
+		pools[i] = algorithms.WaitPool{Capacity: pool.Capacity, Turn: pool.TurnMinutes}
+	}
+
+	tables, groups, err := waitlistInput(req, start)
+	if err != nil {
+		return WaitlistResponse{Success: false, Message: err.Error()}
+	}
+	groupIDs, sizes, access := make([]string, len(req.Groups)), make([]int, len(req.Groups)), make([]bool, len(req.Groups))
+	for i, group := range req.Groups {
+		groupIDs[i], sizes[i], access[i] = group.ID, group.Size, group.WheelchairAccess
+	}
+	constraintTables := make([]constraintTable, len(req.Tables))
+	for i, table := range req.Tables {
+		constraintTables[i] = constraintTable{Capacity: table.Capacity, Zone: table.Zone, Attributes: table.Attributes, X: table.X, Y: table.Y, Accessible: table.WheelchairAccessible}
+	}
+	constraints, err := compileConstraints(req.Constraints, groupIDs, access, constraintTables)
+	if err != nil {
+		return WaitlistResponse{Success: false, Message: err.Error()}
+	}
+	penalties, err := keepPlannedTables(req, constraints.penalties(sizes))
+	if err != nil {
+		return WaitlistResponse{Success: false, Message: err.Error()}
+	}
+	combinations, merged, err := tableCombinations(req)
+	if err != nil {
+		return WaitlistResponse{Success: false, Message: err.Error()}
+	}
+
+	input := algorithms.WaitlistInput{
+		Tables:          tables,
+		Groups:          groups,
+		Pools:           pools,
+		TurnMinutes:     turnMinutes,
+		FairnessMinutes: fairnessMinutes,
+		OverflowAfter:   req.OverflowAfterMinutes,
+		MaximizeRevenue: objective == WaitlistObjectiveRevenue,
+		OverbookRisk:    req.OverbookingRisk,
+		SectionBalance:  req.SectionBalanceMinutes,
+		Penalty:         penalties,
+		Combinations:    combinations,
+		MatchNow:        method == WaitlistMethodFlow,
+	}
+	var annealing *WaitlistAnnealingReport
+	var plan algorithms.WaitlistPlan
+	if method == WaitlistMethodAnneal {
+		annealed := algorithms.AnnealWaitlist(input, time.Duration(budget)*time.Millisecond, steps, req.Seed)
+		plan = annealed.Plan
+		annealing = &WaitlistAnnealingReport{
+			GreedySeated:      annealed.GreedySeated,
+			GreedyCostMinutes: annealed.GreedyCost,
+			Seated:            annealed.Seated,
+			CostMinutes:       annealed.Cost,
+			Steps:             annealed.Steps,
+		}
+		if annealed.Seated == annealed.GreedySeated {
+			annealing.ImprovementMinutes = annealed.GreedyCost - annealed.Cost
+		}
+	} else {
+		plan = algorithms.PlanWaitlist(input)
+	}
+	if !plan.Success {
+		return WaitlistResponse{Success: false, Message: plan.Message}
+	}
+
+	queue := make([]WaitlistEntry, len(plan.Seatings))
+	total, longest, revenue, lastFree, penalty, seatMinutes := 0.0, 0.0, 0.0, 0.0, 0.0, 0.0
+	reassignments := 0
+	var sectionCovers map[string]int
+	for i, seating := range plan.Seatings {
+		group, waiting := req.Groups[seating.Group], groups[seating.Group]
+		members := []int{seating.Table}
+		if seating.Combination >= 0 {
+			members = combinations[seating.Combination].Tables
+		}
+		queue[i] = WaitlistEntry{
+			Position:             i + 1,
+			ID:                   group.ID,
+			Name:                 group.Name,
+			Size:                 group.Size,
+			TableID:              req.Tables[seating.Table].ID,
+			EstimatedWaitMinutes: seating.SeatAt - waiting.ArriveAt,
+			TotalWaitMinutes:     waiting.Waited + seating.SeatAt - waiting.ArriveAt,
+			SeatAt:               start.Add(minutes(seating.SeatAt)),
+			DiningMinutes:        seating.Turn,
+			TableFreeAt:          start.Add(minutes(seating.SeatAt + seating.Turn)),
+			HeldTable:            heldAt(tables, members, seating.SeatAt),
+			ExpectedSpend:        group.ExpectedSpend,
+			Type:                 groupType(group),
+			NoShowProbability:    group.NoShowProbability,
+			Overbooked:           seating.Overbooked,
+			Violations:           constraints.violations(seating.Group, group.Size, seating.Table),
+			Reassigned:           group.TableID != "" && group.TableID != req.Tables[seating.Table].ID,
+			Split:                seating.Combination >= 0 && !merged[seating.Combination],
+			Merged:               seating.Combination >= 0 && merged[seating.Combination],
+			Explanation: WaitlistExplanation{
+				EmptySeats:            -group.Size,
+				PenaltyMinutes:        seating.Penalty,
+				SectionBalanceMinutes: seating.Balance,
+				Priority:              waiting.Priority,
+			},
+		}
+		if seating.Combination >= 0 {
+			queue[i].Explanation.EmptySeats += combinations[seating.Combination].Capacity
+		} else {
+			queue[i].Explanation.EmptySeats += tables[seating.Table].Capacity
+		}
+		if objective == WaitlistObjectiveRevenue {
+			seatHours := float64(group.Size+queue[i].Explanation.EmptySeats) * seating.Turn / 60
+			queue[i].Explanation.SpendPerSeatHour = group.ExpectedSpend * (1 - group.NoShowProbability) / seatHours
+		}
+		if len(members) > 1 {
+			seen := make(map[int]bool)
+			queue[i].Violations = nil
+			for _, t := range members {
+				queue[i].TableIDs = append(queue[i].TableIDs, req.Tables[t].ID)
+				for _, violation := range constraints.violations(seating.Group, group.Size, t) {
+					if !seen[violation.Constraint] {
+						seen[violation.Constraint] = true
+						queue[i].Violations = append(queue[i].Violations, violation)
+					}
+				}
+			}
+		}
+		if queue[i].Reassigned {
+			reassignments++
+		}
+		if section := req.Tables[seating.Table].Section; section != "" {
+			if sectionCovers == nil {
+				sectionCovers = make(map[string]int)
+			}
+			sectionCovers[section] += group.Size
+		}
+		for _, violation := range queue[i].Violations {
+			penalty += violation.Penalty
+		}
+		if waiting.Grace > 0 {
+			holdUntil := start.Add(minutes(seating.SeatAt + waiting.Grace))
+			queue[i].HoldUntil = &holdUntil
+		}
+		total += queue[i].EstimatedWaitMinutes
+		longest = math.Max(longest, queue[i].EstimatedWaitMinutes)
+		revenue += group.ExpectedSpend * (1 - group.NoShowProbability)
+		lastFree = math.Max(lastFree, seating.SeatAt+seating.Turn)
+		seatMinutes += float64(group.Size) * (1 - group.NoShowProbability) * seating.Turn
+	}
+
+	seats := 0
+	for _, table := range tables {
+		seats += table.Capacity
+	}
+	perSeatHour, utilization := 0.0, 0.0
+	if lastFree > 0 {
+		perSeatHour = revenue / (float64(seats) * lastFree / 60)
+		utilization = seatMinutes / (float64(seats) * lastFree)
+	}
+
+	var overflow []OverflowEntry
+	for _, seating := range plan.Overflow {
+		group, waiting := req.Groups[seating.Group], groups[seating.Group]
+		overflow = append(overflow, OverflowEntry{
+			ID:                   group.ID,
+			Name:                 group.Name,
+			Size:                 group.Size,
+			PoolID:               req.Pools[seating.Table].ID,
+			Reason:               noTableReason(req, penalties, combinations, seating.Group),
+			EstimatedWaitMinutes: seating.SeatAt - waiting.ArriveAt,
+			TotalWaitMinutes:     waiting.Waited + seating.SeatAt - waiting.ArriveAt,
+			PlacedAt:             start.Add(minutes(seating.SeatAt)),
+			LeaveAt:              start.Add(minutes(seating.SeatAt + seating.Turn)),
+		})
+	}
+
+	unseatable := make([]string, len(plan.Unseatable))
+	var unseatableReasons map[string]string
+	for i, g := range plan.Unseatable {
+		unseatable[i] = req.Groups[g].ID
+		if unseatableReasons == nil {
+			unseatableReasons = make(map[string]string, len(plan.Unseatable))
+		}
+		unseatableReasons[unseatable[i]] = noTableReason(req, penalties, combinations, g)
+	}
+
+	average := 0.0
+	if len(queue) > 0 {
+		average = total / float64(len(queue))
+	}
+	return WaitlistResponse{
+		Success:            true,
+		Queue:              queue,
+		Overflow:           overflow,
+		Unseatable:         unseatable,
+		UnseatableReasons:  unseatableReasons,
+		AverageWaitMinutes: average,
+		MaxWaitMinutes:     longest,
+		ProjectedRevenue:   revenue,
+		RevenuePerSeatHour: perSeatHour,
+		SeatUtilization:    utilization,
+		Penalty:            penalty,
+		Reassignments:      reassignments,
+		SectionCovers:      sectionCovers,
+		Annealing:          annealing,
+		FloorPlanVersion:   floorPlanVersion,
+		Message:            plan.Message,
+	}
+}
+
+// waitlistSettings are the planner settings of a waitlist request, defaults filled in
+type waitlistSettings struct {
+	turnMinutes, fairnessMinutes float64
+	objective, method            string
+	budget, steps                int // anneal time budget in milliseconds and step limit
+}
+
+// validateWaitlistRequest checks what it can of a waitlist request without its floor plan,
+// so a background job is rejected when submitted
+func validateWaitlistRequest(req WaitlistRequest) error {
+	if len(req.Groups) == 0 {
+		return errors.New("at least one waiting group is required")
+	}
+	if req.Weights != nil && !req.Alternatives {
+		return errors.New("weights require alternatives")
+	}
+	if req.Alternatives && req.Method == WaitlistMethodAnneal {
+		return errors.New("alternatives cannot be combined with the anneal method")
+	}
+	_, err := waitlistRequestSettings(req)
+	return err
+}
+
+// waitlistRequestSettings checks the planner settings of a waitlist request and fills in
+// their defaults
+func waitlistRequestSettings(req WaitlistRequest) (waitlistSettings, error) {
+	turnMinutes := req.TurnMinutes
+	if turnMinutes == 0 {
+		turnMinutes = defaultTurnMinutes
+	}
+	if !validTurnMinutes(turnMinutes) {
+		return waitlistSettings{}, errors.New("turn_minutes must be between 1 and 1440")
+	}
+	fairnessMinutes := -1.0
+	if req.FairnessMinutes != nil {
+		fairnessMinutes = *req.FairnessMinutes
+		if !(fairnessMinutes >= 0) || fairnessMinutes > 24*60 {
+			return waitlistSettings{}, errors.New("fairness_minutes must be between 0 and 1440")
+		}
+	}
+
+	objective := req.Objective
+	if objective == "" {
+		objective = WaitlistObjectiveFit
+	}
+	if objective != WaitlistObjectiveFit && objective != WaitlistObjectiveRevenue {
+		return waitlistSettings{}, fmt.Errorf("invalid objective %q, expected %s or %s", req.Objective, WaitlistObjectiveFit, WaitlistObjectiveRevenue)
+	}
+	method := req.Method
+	if method == "" {
+		method = WaitlistMethodGreedy
+	}
+	if method != WaitlistMethodGreedy && method != WaitlistMethodAnneal && method != WaitlistMethodFlow {
+		return waitlistSettings{}, fmt.Errorf("invalid method %q, expected %s, %s or %s", req.Method, WaitlistMethodGreedy, WaitlistMethodAnneal, WaitlistMethodFlow)
+	}
+	if method == WaitlistMethodFlow && req.FairnessMinutes != nil {
+		return waitlistSettings{}, errors.New("the flow method cannot be combined with fairness_minutes")
+	}
+	budget, steps := req.TimeBudgetMs, req.Steps
+	if budget == 0 {
+		budget = defaultAnnealMillis
+		if steps != 0 {
+			budget = maxAnnealMillis
+		}
+	} else if steps != 0 {
+		return waitlistSettings{}, errors.New("time_budget_ms and steps cannot be combined")
+	}
+	if budget < 1 || budget > maxAnnealMillis {
+		return waitlistSettings{}, fmt.Errorf("time_budget_ms must be between 1 and %d", maxAnnealMillis)
+	}
+	if steps == 0 {
+		steps = maxAnnealSteps
+	}
+	if steps < 1 || steps > maxAnnealSteps {
+		return waitlistSettings{}, fmt.Errorf("steps must be between 1 and %d", maxAnnealSteps)
+	}
+	if method != WaitlistMethodAnneal && (req.TimeBudgetMs != 0 || req.Steps != 0 || req.Seed != 0) {
+		return waitlistSettings{}, errors.New("time_budget_ms, steps and seed only apply to the anneal method")
+	}
+
+	return waitlistSettings{
+		turnMinutes:     turnMinutes,
+		fairnessMinutes: fairnessMinutes,
+		objective:       objective,
+		method:          method,
+		budget:          budget,
+		steps:           steps,
+	}, nil
+}
+
+// keepPlannedTables adds to the penalties the cost of moving each group off the table_id an
+// earlier plan gave it: reassignment_minutes at every other table, which are ruled out
+// without it
+func keepPlannedTables(req WaitlistRequest, penalty [][]float64) ([][]float64, error) {
+	cost := math.Inf(1)
+	if req.ReassignmentMinutes != nil {
+		cost = *req.ReassignmentMinutes
+		if !(cost >= 0) || cost > 24*60 {
+			return nil, errors.New("reassignment_minutes must be between 0 and 1440")
+		}
+	}
+
+	tableIndex := make(map[string]int, len(req.Tables))
+	for t, table := range req.Tables {
+		tableIndex[table.ID] = t
+	}
+	for g, group := range req.Groups {
+		if group.TableID == "" {
+			continue
+		}
+		kept, ok := tableIndex[group.TableID]
+		if !ok {
+			return nil, fmt.Errorf("group %s has unknown table_id %q", group.ID, group.TableID)
+		}
+		if penalty == nil {
+			penalty = make([][]float64, len(req.Groups))
+			for h := range penalty {
+				penalty[h] = make([]float64, len(req.Tables))
+			}
+		}
+		for t := range penalty[g] {
+			if t != kept {
+				penalty[g][t] += cost
+			}
+		}
+	}
+	return penalty, nil
+}
+
+// tableCombinations lists the pairs of tables the request's merge and split options let a
+// group take together when some group is larger than every table, with whether each is a
+// merge. Only pairs whose seats take the smallest such group are listed, closest first and
+// at most maxTablePairs of each kind: merges of tables listed as adjacent or within the merge
+// max_distance, seating seats_lost_per_join fewer than the two tables apart and more than
+// the larger one, then splits over tables within the split max_distance. Tables listed as
+// adjacent must lie within the merge max_distance when one is given
+func tableCombinations(req WaitlistRequest) ([]algorithms.WaitCombination, []bool, error) {
+	if req.Merge == nil && req.Split == nil {
+		return nil, nil, nil
+	}
+	if merge := req.Merge; merge != nil {
+		if !(merge.MaxDistance >= 0) || math.IsInf(merge.MaxDistance, 0) {
+			return nil, nil, errors.New("merge.max_distance must be a non-negative number")
+		}
+		if merge.SeatsLostPerJoin < 0 || merge.SeatsLostPerJoin > maxTableCapacity {
+			return nil, nil, fmt.Errorf("merge.seats_lost_per_join must be between 0 and %d", maxTableCapacity)
+		}
+		if !(merge.PenaltyMinutes >= 0) || merge.PenaltyMinutes > 24*60 {
+			return nil, nil, errors.New("merge.penalty_minutes must be between 0 and 1440")
+		}
+	}
+	if split := req.Split; split != nil {
+		if !(split.MaxDistance > 0) || math.IsInf(split.MaxDistance, 0) {
+			return nil, nil, errors.New("split.max_distance must be a positive number")
+		}
+		if !(split.PenaltyMinutes >= 0) || split.PenaltyMinutes > 24*60 {
+			return nil, nil, errors.New("split.penalty_minutes must be between 0 and 1440")
+		}
+	}
+
+	points := make([]algorithms.Point, len(req.Tables))
+	tableIndex := make(map[string]int, len(req.Tables))
+	for t, table := range req.Tables {
+		points[t] = algorithms.Point{X: table.X, Y: table.Y}
+		tableIndex[table.ID] = t
+	}
+	distance := func(a, b int) float64 {
+		return math.Hypot(points[a].X-points[b].X, points[a].Y-points[b].Y)
+	}
+	adjacent := make(map[[2]int]bool)
+	for a, table := range req.Tables {
+		for _, id := range table.Adjacent {
+			b, ok := tableIndex[id]
+			if !ok || b == a {
+				return nil, nil, fmt.Errorf("table %s lists unknown adjacent table %q", table.ID, id)
+			}
+			if req.Merge != nil && req.Merge.MaxDistance > 0 && distance(a, b) > req.Merge.MaxDistance {
+				return nil, nil, fmt.Errorf("tables %s and %s are listed as adjacent but are %.2f apart, more than merge.max_distance", table.ID, id, distance(a, b))
+			}
+			if b < a {
+				a, b = b, a
+			}
+			adjacent[[2]int{a, b}] = true
+		}
+	}
+
+	largest := 0
+	for _, table := range req.Tables {
+		if table.Capacity > largest {
+			largest = table.Capacity
+		}
+	}
+	smallest := 0
+	for _, group := range req.Groups {
+		if group.Size > largest && (smallest == 0 || group.Size < smallest) {
+			smallest = group.Size
+		}
+	}
+	if smallest == 0 {
+		return nil, nil, nil
+	}
+
+	index := algorithms.NewKDTree(points)
+	// pairs lists the pairs within maxDistance, and those listed as adjacent with
+	// withAdjacent, that seat the smallest group, closest first
+	pairs := func(maxDistance float64, withAdjacent bool, capacity func(a, b int) int, penalty float64) []algorithms.WaitCombination {
+		found := make(map[[2]int]bool)
+		if withAdjacent {
+			for pair := range adjacent {
+				found[pair] = true
+			}
+		}
+		if maxDistance > 0 {
+			for a := range req.Tables {
+				for _, b := range index.Within(points[a], maxDistance) {
+					if b > a {
+						found[[2]int{a, b}] = true
+					}
+				}
+			}
+		}
+		var kept [][2]int
+		for pair := range found {
+			if capacity(pair[0], pair[1]) >= smallest {
+				kept = append(kept, pair)
+			}
+		}
+		sort.Slice(kept, func(i, j int) bool {
+			if di, dj := distance(kept[i][0], kept[i][1]), distance(kept[j][0], kept[j][1]); di != dj {
+				return di < dj
+			}
+			return kept[i][0] < kept[j][0] || (kept[i][0] == kept[j][0] && kept[i][1] < kept[j][1])
+		})
+		if len(kept) > maxTablePairs {
+			kept = kept[:maxTablePairs]
+		}
+		combinations := make([]algorithms.WaitCombination, len(kept))
+		for i, pair := range kept {
+			combinations[i] = algorithms.WaitCombination{Tables: []int{pair[0], pair[1]}, Capacity: capacity(pair[0], pair[1]), Penalty: penalty}
+		}
+		return combinations
+	}
+
+	var combinations []algorithms.WaitCombination
+	var merged []bool
+	if merge := req.Merge; merge != nil {
+		joined := func(a, b int) int {
+			capacity := req.Tables[a].Capacity + req.Tables[b].Capacity - merge.SeatsLostPerJoin
+			if capacity <= req.Tables[a].Capacity || capacity <= req.Tables[b].Capacity {
+				return 0
+			}
+			return capacity
+		}
+		for _, combination := range pairs(merge.MaxDistance, true, joined, merge.PenaltyMinutes) {
+			combinations, merged = append(combinations, combination), append(merged, true)
+		}
+	}
+	if split := req.Split; split != nil {
+		apart := func(a, b int) int {
+			return req.Tables[a].Capacity + req.Tables[b].Capacity
+		}
+		for _, combination := range pairs(split.MaxDistance, false, apart, split.PenaltyMinutes) {
+			combinations, merged = append(combinations, combination), append(merged, false)
+		}
+	}
+	return combinations, merged, nil
+}
+
+// noTableReason returns why group g has no table: none of the tables, or the combinations the
+// request allows, is large enough, or every one that is is ruled out. It returns
+// no_table_soon_enough when some table could take the group
+func noTableReason(req WaitlistRequest, penalty [][]float64, combinations []algorithms.WaitCombination, g int) string {
+	size := req.Groups[g].Size
+	largeEnough := false
+	for t, table := range req.Tables {
+		if table.Capacity >= size {
+			largeEnough = true
+			if penalty == nil || !math.IsInf(penalty[g][t], 1) {
+				return NoTableSoonEnough
+			}
+		}
+	}
+	for _, combination := range combinations {
+		if combination.Capacity < size {
+			continue
+		}
+		largeEnough = true
+		allowed := true
+		for _, t := range combination.Tables {
+			allowed = allowed && (penalty == nil || !math.IsInf(penalty[g][t], 1))
+		}
+		if allowed {
+			return NoTableSoonEnough
+		}
+	}
+	if !largeEnough {
+		return NoTableLargeEnough
+	}
+	return NoTableAllowed
+}
+
+// heldAt reports whether any of the tables is still held at the given minute
+func heldAt(tables []algorithms.WaitTable, members []int, at float64) bool {
+	for _, t := range members {
+		if at < tables[t].HeldUntil {
+			return true
+		}
+	}
+	return false
+}
+
+// waitlistFloorPlan loads the floor plan version a waitlist request refers to, by default
+// the one used on the weekday of start
+func (os *OptimizationService) waitlistFloorPlan(req WaitlistRequest, start time.Time) (*models.FloorPlan, error) {
+	if len(req.Tables) > 0 {
+		return nil, errors.New("give tables or floor_plan_id, not both")
+	}
+
+	query := FloorPlanQuery{Version: req.FloorPlanVersion, Day: req.Day}
+	if query.Version == 0 && query.Day == "" {
+		query.Day = start.Weekday().String()
+	}
+	return os.resolveFloorPlan(req.FloorPlanID, query)
+}
+
+// floorPlanWaitlistTables lists the tables of a floor plan with their availability and holds
+func floorPlanWaitlistTables(plan *models.FloorPlan, availability, holds map[string]float64) ([]WaitlistTable, error) {
+	tables := make([]WaitlistTable, len(plan.Tables))
+	for i, table := range plan.Tables {
+		tables[i] = WaitlistTable{
+			ID:                 table.ID,
+			Capacity:           table.Capacity,
+			AvailableInMinutes: availability[table.ID],
+			HoldMinutes:        holds[table.ID],
+			Zone:               table.Zone,
+			Attributes:         table.Attributes,
+			Section:            table.Section,
+
+			WheelchairAccessible: table.WheelchairAccessible,
+			Adjacent:             table.Adjacent,
+			X:                    table.X,
+			Y:                    table.Y,
+		}
+	}
+
+	for _, byTable := range []struct {
+		field  string
+		values map[string]float64
+	}{{"availability", availability}, {"holds", holds}} {
+		ids := make([]string, 0, len(byTable.values))
+		for id := range byTable.values {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if plan.TableIndex(id) < 0 {
+				return nil, fmt.Errorf("%s lists table %q, which is not in floor plan %s version %d", byTable.field, id, plan.ID, plan.Version)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// waitlistInput validates the tables and groups of a waitlist request, with table
+// availability in minutes after start
+func waitlistInput(req WaitlistRequest, start time.Time) ([]algorithms.WaitTable, []algorithms.WaitGroup, error) {
+	if len(req.Tables) == 0 || len(req.Tables) > maxWaitlistTables {
+		return nil, nil, fmt.Errorf("between 1 and %d tables are required", maxWaitlistTables)
+	}
+	if len(req.Groups) == 0 || len(req.Groups) > maxWaitlistGroups {
+		return nil, nil, fmt.Errorf("between 1 and %d groups are required", maxWaitlistGroups)
+	}
+
+	tables := make([]algorithms.WaitTable, len(req.Tables))
+	seen := make(map[string]bool, len(req.Tables))
+	sections := make(map[string]int)
+	for i, table := range req.Tables {
+		if table.ID == "" {
+			return nil, nil, fmt.Errorf("table %d has no id", i)
+		}
+		if seen[table.ID] {
+			return nil, nil, fmt.Errorf("duplicate table id %q", table.ID)
+		}
+		seen[table.ID] = true
+		if table.Capacity < 1 || table.Capacity > maxTableCapacity {
+			return nil, nil, fmt.Errorf("capacity of table %s must be between 1 and %d", table.ID, maxTableCapacity)
+		}
+		freeAt := table.AvailableInMinutes
+		if table.AvailableAt != nil {
+			if freeAt != 0 {
+				return nil, nil, fmt.Errorf("give available_in_minutes or available_at for table %s, not both", table.ID)
+			}
+			// A table whose group is late to leave is expected to free up any moment
+			freeAt = math.Max(0, table.AvailableAt.Sub(start).Minutes())
+		}
+		if !(freeAt >= 0) || freeAt > 24*60 {
+			return nil, nil, fmt.Errorf("table %s must be available within 1440 minutes", table.ID)
+		}
+		if !(table.HoldMinutes >= 0) || table.HoldMinutes > 24*60 {
+			return nil, nil, fmt.Errorf("hold_minutes of table %s must be between 0 and 1440", table.ID)
+		}
+		if table.Section != "" && sections[table.Section] == 0 {
+			sections[table.Section] = len(sections) + 1
+		}
+		tables[i] = algorithms.WaitTable{Capacity: table.Capacity, FreeAt: freeAt, HeldUntil: table.HoldMinutes, Section: sections[table.Section]}
+	}
+
+	sizes := make([]int, 0, len(req.TurnMinutesBySize))
+	for size, turn := range req.TurnMinutesBySize {
+		if size < 1 || size > maxTableCapacity || !validTurnMinutes(turn) {
+			return nil, nil, fmt.Errorf("turn_minutes_by_size needs sizes between 1 and %d and minutes between 1 and 1440", maxTableCapacity)
+		}
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	grace := float64(defaultGraceMinutes)
+	if req.ReservationGraceMinutes != nil {
+		grace = *req.ReservationGraceMinutes
+		if !(grace >= 0) || grace > 24*60 {
+			return nil, nil, errors.New("reservation_grace_minutes must be between 0 and 1440")
+		}
+	}
+
+	holdPriority := req.HoldPriority
+	if holdPriority == 0 {
+		holdPriority = 1
+	}
+	if holdPriority < 0 {
+		return nil, nil, errors.New("hold_priority must be positive")
+	}
+
+	groups := make([]algorithms.WaitGroup, len(req.Groups))
+	seen = make(map[string]bool, len(req.Groups))
+	for i, group := range req.Groups {
+		if group.ID == "" {
+			return nil, nil, fmt.Errorf("group %d has no id", i)
+		}
+		if seen[group.ID] {
+			return nil, nil, fmt.Errorf("duplicate group id %q", group.ID)
+		}
+		seen[group.ID] = true
+		if group.Size < 1 || group.Size > maxTableCapacity {
+			return nil, nil, fmt.Errorf("size of group %s must be between 1 and %d", group.ID, maxTableCapacity)
+		}
+		if !(group.WaitedMinutes >= 0) || math.IsInf(group.WaitedMinutes, 0) {
+			return nil, nil, fmt.Errorf("waited_minutes of group %s must be a non-negative number", group.ID)
+		}
+		if !(group.ArrivalMinutes >= 0) || group.ArrivalMinutes > 24*60 {
+			return nil, nil, fmt.Errorf("arrival_minutes of group %s must be between 0 and 1440", group.ID)
+		}
+		if group.Priority < 0 {
+			return nil, nil, fmt.Errorf("priority of group %s must not be negative", group.ID)
+		}
+		if group.ArrivalMinutes > 0 && group.WaitedMinutes > 0 {
+			return nil, nil, fmt.Errorf("group %s cannot have waited if it has not arrived yet", group.ID)
+		}
+		waited, arriveAt := group.WaitedMinutes, group.ArrivalMinutes
+		if group.ArrivalTime != nil {
+			if waited != 0 || arriveAt != 0 {
+				return nil, nil, fmt.Errorf("give arrival_time or waited_minutes and arrival_minutes for group %s, not both", group.ID)
+			}
+			if offset := group.ArrivalTime.Sub(start).Minutes(); offset > 0 {
+				arriveAt = offset
+			} else {
+				waited = -offset
+			}
+			if arriveAt > 24*60 || waited > 24*60 {
+				return nil, nil, fmt.Errorf("arrival_time of group %s must be within a day of the start time", group.ID)
+			}
+		}
+		if group.DiningMinutes != 0 && !validTurnMinutes(group.DiningMinutes) {
+			return nil, nil, fmt.Errorf("dining_minutes of group %s must be between 1 and 1440", group.ID)
+		}
+
+		turn := group.DiningMinutes
+		if turn == 0 {
+			// The entry of the largest configured size not above the group's
+			for j := len(sizes) - 1; j >= 0; j-- {
+				if sizes[j] <= group.Size {
+					turn = req.TurnMinutesBySize[sizes[j]]
+					break
+				}
+			}
+		}
+		if !(group.ExpectedSpend >= 0) || math.IsInf(group.ExpectedSpend, 0) {
+			return nil, nil, fmt.Errorf("expected_spend of group %s must be a non-negative number", group.ID)
+		}
+		if !(group.NoShowProbability >= 0) || group.NoShowProbability > 1 {
+			return nil, nil, fmt.Errorf("no_show_probability of group %s must be between 0 and 1", group.ID)
+		}
+
+		var booked bool
+		var groupGrace float64
+		switch groupType(group) {
+		case GroupTypeWalkIn:
+		case GroupTypeReservation:
+			booked, groupGrace = true, grace
+		case GroupTypeEvent:
+			booked = true
+		default:
+			return nil, nil, fmt.Errorf("type of group %s must be %s, %s or %s", group.ID, GroupTypeWalkIn, GroupTypeReservation, GroupTypeEvent)
+		}
+
+		groups[i] = algorithms.WaitGroup{
+			Size:     group.Size,
+			Waited:   waited,
+			ArriveAt: arriveAt,
+			Turn:     turn,
+			Priority: group.Priority >= holdPriority || group.Type == GroupTypeEvent,
+			Rank:     group.Priority,
+			Spend:    group.ExpectedSpend,
+			Booked:   booked,
+			Grace:    groupGrace,
+			NoShow:   group.NoShowProbability,
+		}
+	}
+	return tables, groups, nil
+}
+
+// groupType returns the type of a waitlist group, walk_in when none is given
+func groupType(group WaitlistGroup) string {
+	if group.Type == "" {
+		return GroupTypeWalkIn
+	}
+	return group.Type
+}
+
+// validTurnMinutes reports whether a turn time is between a minute and a day
+func validTurnMinutes(turn float64) bool {
+	return turn >= 1 && turn <= 24*60
+}
+
+// minutes converts fractional minutes to a duration
+func minutes(m float64) time.Duration {
+	return time.Duration(m * float64(time.Minute))
+}
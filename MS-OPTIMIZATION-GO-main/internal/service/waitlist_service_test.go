@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// tiedFloor has several tables of each size, some free now and some later, so the
+// planner keeps choosing between equally good tables
+func tiedFloor() []WaitlistTable {
+	return []WaitlistTable{
+		{ID: "a", Capacity: 2},
+		{ID: "b", Capacity: 2},
+		{ID: "c", Capacity: 4, AvailableInMinutes: 20},
+		{ID: "d", Capacity: 4},
+		{ID: "e", Capacity: 4, AvailableInMinutes: 20},
+		{ID: "f", Capacity: 6, AvailableInMinutes: 45},
+	}
+}
+
+func waitingGroups() []WaitlistGroup {
+	return []WaitlistGroup{
+		{ID: "g1", Size: 2, WaitedMinutes: 15},
+		{ID: "g2", Size: 4, WaitedMinutes: 10},
+		{ID: "g3", Size: 2, WaitedMinutes: 5},
+		{ID: "g4", Size: 3, WaitedMinutes: 5},
+		{ID: "g5", Size: 4},
+		{ID: "g6", Size: 2},
+		{ID: "g7", Size: 5, ArrivalMinutes: 10},
+		{ID: "g8", Size: 2, ArrivalMinutes: 15},
+	}
+}
+
+func TestPlanWaitlistIgnoresTableOrder(t *testing.T) {
+	start := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+	tables := tiedFloor()
+	reversed := make([]WaitlistTable, len(tables))
+	for i, table := range tables {
+		reversed[len(tables)-1-i] = table
+	}
+	shuffled := []WaitlistTable{tables[3], tables[0], tables[5], tables[2], tables[1], tables[4]}
+
+	requests := map[string]WaitlistRequest{
+		"greedy":  {Groups: waitingGroups(), StartTime: &start},
+		"revenue": {Groups: waitingGroups(), StartTime: &start, Objective: WaitlistObjectiveRevenue},
+		"anneal":  {Groups: waitingGroups(), StartTime: &start, Method: WaitlistMethodAnneal, Steps: 2000, Seed: 7},
+	}
+	os := newTestService()
+	for name, req := range requests {
+		t.Run(name, func(t *testing.T) {
+			req.Tables = tables
+			want := os.PlanWaitlist(req)
+			if !want.Success {
+				t.Fatalf("PlanWaitlist: %s", want.Message)
+			}
+			for _, order := range [][]WaitlistTable{reversed, shuffled} {
+				req.Tables = order
+				got := os.PlanWaitlist(req)
+				if !reflect.DeepEqual(got.Queue, want.Queue) || !reflect.DeepEqual(got.Annealing, want.Annealing) {
+					t.Errorf("tables %v planned\n%+v\nwant\n%+v", tableIDs(order), got.Queue, want.Queue)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanWaitlistAnnealIsReproducible(t *testing.T) {
+	start := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+	req := WaitlistRequest{Tables: tiedFloor(), Groups: waitingGroups(), StartTime: &start, Method: WaitlistMethodAnneal, Steps: 2000, Seed: 42}
+
+	os := newTestService()
+	first, second := os.PlanWaitlist(req), os.PlanWaitlist(req)
+	if !first.Success {
+		t.Fatalf("PlanWaitlist: %s", first.Message)
+	}
+	if first.Annealing == nil || first.Annealing.Steps != req.Steps {
+		t.Fatalf("annealing report %+v, want %d steps", first.Annealing, req.Steps)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed and steps planned\n%+v\nthen\n%+v", first, second)
+	}
+}
+
+func tableIDs(tables []WaitlistTable) []string {
+	ids := make([]string, len(tables))
+	for i, table := range tables {
+		ids[i] = table.ID
+	}
+	return ids
+}